@@ -18,6 +18,7 @@ import (
 func main() {
 	serverAddr := flag.String("addr", "localhost:9000", "server address")
 	playerName := flag.String("name", "TestPlayer", "player name")
+	resumeToken := flag.String("resume", "", "resume token from a previous session")
 	flag.Parse()
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -47,6 +48,9 @@ func main() {
 		*playerName,
 		"0.1.0",
 	)
+	if *resumeToken != "" {
+		hello = protocol.NewResumeHello(playerID, *playerName, "0.1.0", *resumeToken)
+	}
 
 	data, err := protocol.Encode(hello)
 	if err != nil {
@@ -87,8 +91,9 @@ func main() {
 
 				switch p := msg.Payload.(type) {
 				case *gamepb.Message_ServerWelcome:
-					log.Printf("✅ ServerWelcome: player_id=%s, tick_rate=%d",
-						p.ServerWelcome.PlayerId, p.ServerWelcome.TickRate)
+					log.Printf("✅ ServerWelcome: player_id=%s, tick_rate=%d, resumed=%v",
+						p.ServerWelcome.PlayerId, p.ServerWelcome.TickRate, p.ServerWelcome.Resumed)
+					log.Printf("🔑 Resume token: %s", p.ServerWelcome.ResumeToken)
 				case *gamepb.Message_StateSnapshot:
 					log.Printf("📊 StateSnapshot: tick=%d, players=%d",
 						p.StateSnapshot.Tick, len(p.StateSnapshot.Players))
@@ -99,6 +104,22 @@ func main() {
 		}
 	}()
 
+	// Keep the server from taking us for gone between inputs
+	go func() {
+		ticker := time.NewTicker(protocol.HeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if data, err := protocol.Encode(protocol.NewHeartbeat(uint64(time.Now().UnixMilli()))); err == nil {
+					conn.Write(data)
+				}
+			}
+		}
+	}()
+
 	// Read input and send
 	fmt.Println("\n🎮 Use arrow keys (or WASD) to move. Press Enter to send. Type 'quit' to exit.")
 	fmt.Println("   Commands: up, down, left, right, jump, quit")
@@ -0,0 +1,210 @@
+// Command replay inspects recorded game sessions and re-streams them to
+// spectators.
+//
+// Usage:
+//
+//	replay info FILE                 summary of a replay
+//	replay seek -tick N FILE         player state at tick N
+//	replay stream [-udp 9100] FILE   play back to UDP spectators
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/LemmyAI/gameserver/internal/game"
+	"github.com/LemmyAI/gameserver/internal/protocol"
+	"github.com/LemmyAI/gameserver/internal/transport"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	switch os.Args[1] {
+	case "info":
+		runInfo(os.Args[2:])
+	case "seek":
+		runSeek(os.Args[2:])
+	case "stream":
+		runStream(os.Args[2:])
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: replay info FILE")
+	fmt.Fprintln(os.Stderr, "       replay seek -tick N FILE")
+	fmt.Fprintln(os.Stderr, "       replay stream [-udp PORT] [-speed X] [-from TICK] [-wait D] FILE")
+	os.Exit(2)
+}
+
+// loadReplay reads the replay named by the flag set's only argument.
+func loadReplay(fs *flag.FlagSet) *game.Replay {
+	if fs.NArg() != 1 {
+		usage()
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		log.Fatalf("Open replay: %v", err)
+	}
+	defer f.Close()
+
+	replay, err := game.ReadReplay(f)
+	if err != nil {
+		log.Fatalf("Read replay: %v", err)
+	}
+	return replay
+}
+
+// runInfo prints a summary of a replay.
+func runInfo(args []string) {
+	fs := flag.NewFlagSet("info", flag.ExitOnError)
+	fs.Parse(args)
+	replay := loadReplay(fs)
+
+	h := replay.Header
+	counts := make(map[game.ReplayEventKind]int)
+	names := make(map[string]string)
+	for _, ev := range replay.Events {
+		counts[ev.Kind]++
+		if ev.Kind == game.EventJoin {
+			names[ev.PlayerID] = ev.Name
+		}
+		for _, f := range ev.Players {
+			names[f.ID] = f.Name
+		}
+	}
+
+	fmt.Printf("Recorded:  %s\n", h.RecordedAt.Format(time.RFC3339))
+	fmt.Printf("Ticks:     %d-%d (%v at %d Hz)\n", replay.StartTick(), replay.EndTick(), replay.Duration(), h.Config.TickRate)
+	fmt.Printf("World:     %.0fx%.0f, speed %.0f\n", h.Config.WorldWidth, h.Config.WorldHeight, h.Config.PlayerSpeed)
+	fmt.Printf("Keyframes: %d (every %d ticks)\n", counts[game.EventKeyframe], h.KeyframeInterval)
+	fmt.Printf("Events:    %d inputs, %d joins, %d leaves, %d freezes, %d thaws\n",
+		counts[game.EventInput], counts[game.EventJoin], counts[game.EventLeave],
+		counts[game.EventFreeze], counts[game.EventThaw])
+
+	ids := make([]string, 0, len(names))
+	for id := range names {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	fmt.Printf("Players:   %d\n", len(ids))
+	for _, id := range ids {
+		fmt.Printf("  %s  %s\n", id, names[id])
+	}
+}
+
+// runSeek prints every player's state at a tick.
+func runSeek(args []string) {
+	fs := flag.NewFlagSet("seek", flag.ExitOnError)
+	tick := fs.Uint64("tick", 0, "tick to seek to")
+	fs.Parse(args)
+	replay := loadReplay(fs)
+
+	player := game.NewReplayPlayer(replay, nil)
+	player.Seek(*tick)
+
+	players := player.Engine().State().AllPlayers()
+	sort.Slice(players, func(i, j int) bool { return players[i].ID < players[j].ID })
+
+	fmt.Printf("Tick %d: %d players\n", player.Tick(), len(players))
+	for _, p := range players {
+		status := ""
+		if p.Disconnected {
+			status = " (disconnected)"
+		}
+		fmt.Printf("  %s  %-16s pos=(%.1f, %.1f) vel=(%.1f, %.1f)%s\n",
+			p.ID, p.Name, p.Position.X, p.Position.Y, p.Velocity.X, p.Velocity.Y, status)
+	}
+}
+
+// runStream plays a replay to spectators connecting over UDP.
+func runStream(args []string) {
+	fs := flag.NewFlagSet("stream", flag.ExitOnError)
+	udpPort := fs.String("udp", "9100", "UDP port spectators connect to")
+	speed := fs.Float64("speed", 1, "playback speed multiplier")
+	from := fs.Uint64("from", 0, "tick to start from")
+	wait := fs.Duration("wait", 5*time.Second, "time to wait for spectators before playing")
+	fs.Parse(args)
+	replay := loadReplay(fs)
+
+	t := transport.NewUDPTransport(transport.DefaultConfig())
+	broadcaster := game.NewTransportBroadcaster(nil, t.SendUnreliable)
+
+	player := game.NewReplayPlayer(replay, broadcaster)
+	engine := player.Engine()
+	if *from > 0 {
+		player.Seek(*from)
+	}
+
+	t.OnMessage(func(addr string, data []byte, reliable bool) {
+		msg, err := protocol.Decode(data)
+		if err != nil {
+			return
+		}
+		hello := msg.GetClientHello()
+		if hello == nil {
+			return
+		}
+		spectatorID := hello.PlayerId
+		if spectatorID == "" {
+			spectatorID = addr
+		}
+		if engine.AddSpectator(spectatorID, addr) == nil {
+			log.Printf("❌ [%s] spectator ID conflict: %s", addr, spectatorID)
+			return
+		}
+		if err := engine.FollowPlayer(spectatorID, hello.FollowPlayerId); err != nil {
+			log.Printf("⚠️  [%s] cannot follow %s: %v", addr, hello.FollowPlayerId, err)
+		}
+
+		welcome := protocol.NewServerWelcome(spectatorID, uint32(replay.Header.Config.TickRate), uint64(time.Now().UnixMilli()), "", false)
+		welcome.GetServerWelcome().Spectator = true
+		broadcaster.SendTo(addr, welcome)
+	})
+	t.OnDisconnect(func(addr string) {
+		for _, s := range engine.Spectators().All() {
+			if s.Addr == addr {
+				engine.RemoveSpectator(s.ID)
+				log.Printf("👋 Spectator left: %s", s.ID)
+			}
+		}
+	})
+
+	udpAddr := *udpPort
+	if udpAddr[0] != ':' {
+		udpAddr = ":" + udpAddr
+	}
+	if err := t.Listen(udpAddr); err != nil {
+		log.Fatalf("Failed to listen: %v", err)
+	}
+	defer t.Close()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	log.Printf("📼 Streaming replay on UDP %s (ticks %d-%d, %.1fx)", udpAddr, player.Tick(), replay.EndTick(), *speed)
+	select {
+	case <-time.After(*wait):
+	case <-ctx.Done():
+		return
+	}
+
+	start := time.Now()
+	if err := player.Play(ctx, *speed); err != nil && err != context.Canceled {
+		log.Printf("Playback error: %v", err)
+	}
+	log.Printf("🏁 Playback finished at tick %d after %v", player.Tick(), time.Since(start).Round(time.Millisecond))
+
+}
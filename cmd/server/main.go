@@ -8,32 +8,45 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
-	"sync"
+	"sync/atomic"
 	"syscall"
-	"time"
 
+	"github.com/LemmyAI/gameserver/internal/auth"
 	"github.com/LemmyAI/gameserver/internal/game"
-	"github.com/LemmyAI/gameserver/internal/protocol"
-	"github.com/LemmyAI/gameserver/internal/protocol/gamepb"
+	"github.com/LemmyAI/gameserver/internal/orchestrator"
+	"github.com/LemmyAI/gameserver/internal/room"
 	"github.com/LemmyAI/gameserver/internal/transport"
 )
 
-// Server holds all server state.
+// Server is the room's EngineServer behind a UDP listener.
 type Server struct {
-	transport   transport.Transport
-	engine      *game.Engine
-	broadcaster *game.TransportBroadcaster
-	playerMap   map[string]string // playerID -> addr (multiple players per addr OK)
-	mu          sync.RWMutex
+	*orchestrator.EngineServer
+	ready atomic.Bool // Set once the UDP listener is up (served on /ready)
 }
 
 func main() {
 	// Parse flags
 	udpPort := flag.String("udp", "", "UDP port to listen on (default from env or 9000)")
 	httpPort := flag.String("http", "", "HTTP port (default from env or 8000)")
-	roomID := flag.String("room", "", "Room ID for logging")
+	roomID := flag.String("room", "", "Room ID (session tokens must match when set)")
+	recordPath := flag.String("record", "", "Write a replay of the session to this file")
+	phaseFlag := flag.String("phase", string(room.PhaseInGame), "Initial room phase (the webbridge updates it)")
+
+	// Room settings (0 keeps the game default)
+	tickRate := flag.Int("tick-rate", 0, "Ticks per second")
+	maxPlayers := flag.Int("max-players", 0, "Maximum players")
+	worldWidth := flag.Float64("world-width", 0, "World width")
+	worldHeight := flag.Float64("world-height", 0, "World height")
+	playerSpeed := flag.Float64("speed", 0, "Player speed in units per second")
+	visionRadius := flag.Float64("vision-radius", 0, "Hide non-teammates beyond this distance (0 = everyone sees everyone)")
+	spectatorDelay := flag.Duration("spectator-delay", 0, "How far spectators lag behind players (anti-ghosting)")
 	flag.Parse()
 
+	phase, ok := room.ParsePhase(*phaseFlag)
+	if !ok {
+		log.Fatalf("Unknown room phase: %s", *phaseFlag)
+	}
+
 	log.Printf("🎮 GameServer starting... (room: %s)", *roomID)
 
 	// Determine ports
@@ -59,25 +72,61 @@ func main() {
 	// Create UDP transport
 	t := transport.NewUDPTransport(transport.DefaultConfig())
 
-	// Create server
+	// Create game engine with broadcaster
+	settings := room.Settings{
+		TickRate:    *tickRate,
+		MaxPlayers:  *maxPlayers,
+		WorldWidth:  float32(*worldWidth),
+		WorldHeight: float32(*worldHeight),
+		PlayerSpeed: float32(*playerSpeed),
+
+		VisionRadius: float32(*visionRadius),
+	}
+	config := orchestrator.GameConfig(settings)
+	log.Printf("⚙️  %d Hz, %d players, %.0fx%.0f world, speed %.0f",
+		config.TickRate, config.MaxPlayers, config.WorldWidth, config.WorldHeight, config.PlayerSpeed)
+	broadcaster := game.NewTransportBroadcaster(nil, t.SendUnreliable)
 	srv := &Server{
-		transport: t,
-		playerMap: make(map[string]string),
+		EngineServer: orchestrator.NewEngineServer(orchestrator.Spec{
+			RoomID:   *roomID,
+			Phase:    phase,
+			Settings: settings,
+
+			SpectatorDelay: *spectatorDelay,
+		}, broadcaster),
 	}
+	broadcaster.SetState(srv.Engine().State())
 
-	// Create game engine with broadcaster
-	config := game.DefaultConfig()
-	srv.broadcaster = game.NewTransportBroadcaster(nil, t.SendUnreliable)
-	srv.engine = game.NewEngine(config, srv.broadcaster)
-	srv.broadcaster.SetState(srv.engine.State())
+	// Session tokens are signed by the webbridge with a shared secret
+	if secret := os.Getenv("SESSION_SECRET"); secret != "" {
+		srv.RequireSessions(auth.NewSigner([]byte(secret)))
+		log.Printf("🔐 Session tokens required")
+	} else {
+		log.Printf("⚠️  SESSION_SECRET not set, accepting client-chosen player IDs")
+	}
 
 	// Register transport handlers
-	t.OnMessage(srv.handleMessage)
-	t.OnConnect(srv.handleConnect)
-	t.OnDisconnect(srv.handleDisconnect)
+	srv.Attach(t)
+
+	// Record a replay if requested
+	var recorder *game.Recorder
+	if *recordPath != "" {
+		f, err := os.Create(*recordPath)
+		if err != nil {
+			log.Fatalf("Failed to create replay file: %v", err)
+		}
+		defer f.Close()
+
+		recorder, err = game.NewRecorder(f, config)
+		if err != nil {
+			log.Fatalf("Failed to start replay: %v", err)
+		}
+		srv.Engine().Record(recorder)
+		log.Printf("📼 Recording replay to %s", *recordPath)
+	}
 
 	// Start game engine
-	srv.engine.Start()
+	srv.Start()
 
 	// Start HTTP health server
 	go startHTTPServer(httpAddr, srv)
@@ -87,6 +136,7 @@ func main() {
 	if err := t.Listen(udpAddr); err != nil {
 		log.Fatalf("Failed to listen: %v", err)
 	}
+	srv.ready.Store(true)
 
 	log.Printf("✅ Server ready!")
 	log.Printf("   UDP: %s", udpAddr)
@@ -100,7 +150,14 @@ func main() {
 	<-sigCh
 
 	log.Println("🛑 Shutting down...")
-	srv.engine.Stop()
+	srv.Stop()
+	if recorder != nil {
+		if err := recorder.Close(); err != nil {
+			log.Printf("Error saving replay: %v", err)
+		} else {
+			log.Printf("📼 Replay saved (%d events)", recorder.Events())
+		}
+	}
 	if err := t.Close(); err != nil {
 		log.Printf("Error closing: %v", err)
 	}
@@ -115,6 +172,11 @@ func startHTTPServer(port string, srv *Server) {
 	})
 
 	http.HandleFunc("/ready", func(w http.ResponseWriter, r *http.Request) {
+		if !srv.ready.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte("STARTING"))
+			return
+		}
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("READY"))
 	})
@@ -122,7 +184,7 @@ func startHTTPServer(port string, srv *Server) {
 	http.HandleFunc("/stats", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
-		w.Write([]byte(`{"players": ` + itoa(srv.engine.PlayerCount()) + `}`))
+		w.Write([]byte(`{"players": ` + itoa(srv.Engine().PlayerCount()) + `, "spectators": ` + itoa(srv.Engine().Spectators().Count()) + `, "phase": "` + string(srv.Phase()) + `"}`))
 	})
 
 	log.Printf("🏥 HTTP server listening on :%s", port)
@@ -144,126 +206,3 @@ func itoa(n int) string {
 	}
 	return string(buf[pos:])
 }
-
-// handleMessage processes incoming messages.
-func (s *Server) handleMessage(addr string, data []byte, reliable bool) {
-	// Decode message
-	msg, err := protocol.Decode(data)
-	if err != nil {
-		log.Printf("⚠️  [%s] invalid protobuf: %v", addr, err)
-		return
-	}
-
-	// Route by message type
-	switch payload := msg.Payload.(type) {
-	case *gamepb.Message_ClientHello:
-		s.handleClientHello(addr, payload.ClientHello)
-	case *gamepb.Message_PlayerInput:
-		s.handlePlayerInput(addr, payload.PlayerInput)
-	default:
-		log.Printf("❓ [%s] unknown message type: %s", addr, protocol.MessageTypeName(msg))
-	}
-}
-
-// handleConnect handles new connections (UDP doesn't really have these).
-func (s *Server) handleConnect(addr string) {
-	// UDP is connectionless - we handle "connect" via ClientHello
-}
-
-// handleDisconnect handles disconnections.
-func (s *Server) handleDisconnect(addr string) {
-	// Find all players at this address and remove them
-	s.mu.Lock()
-	var toRemove []string
-	for playerID, playerAddr := range s.playerMap {
-		if playerAddr == addr {
-			toRemove = append(toRemove, playerID)
-		}
-	}
-	for _, playerID := range toRemove {
-		delete(s.playerMap, playerID)
-	}
-	s.mu.Unlock()
-
-	for _, playerID := range toRemove {
-		s.engine.RemovePlayer(playerID)
-	}
-}
-
-// handleClientHello handles new player connections.
-func (s *Server) handleClientHello(addr string, hello *gamepb.ClientHello) {
-	playerID := hello.PlayerId
-	if playerID == "" {
-		log.Printf("❌ [%s] empty player ID", addr)
-		return
-	}
-
-	// Check if player ID already exists
-	s.mu.RLock()
-	_, exists := s.playerMap[playerID]
-	s.mu.RUnlock()
-
-	if exists {
-		// Player already connected, just update address
-		s.mu.Lock()
-		s.playerMap[playerID] = addr
-		s.mu.Unlock()
-		return
-	}
-
-	// Add player to game
-	player := s.engine.AddPlayerWithID(hello.PlayerName, playerID, addr)
-	if player == nil {
-		log.Printf("❌ [%s] server full or ID conflict", addr)
-		return
-	}
-
-	// Track playerID -> addr mapping
-	s.mu.Lock()
-	s.playerMap[playerID] = addr
-	s.mu.Unlock()
-
-	// Send welcome
-	welcome := protocol.NewServerWelcome(
-		player.ID,
-		uint32(s.engine.State().Config().TickRate),
-		uint64(time.Now().UnixMilli()),
-	)
-
-	if err := s.broadcaster.SendTo(addr, welcome); err != nil {
-		log.Printf("❌ send welcome: %v", err)
-		return
-	}
-
-	log.Printf("👋 [%s] Welcome to %s (id=%s)", addr, hello.PlayerName, player.ID)
-}
-
-// handlePlayerInput handles player input.
-func (s *Server) handlePlayerInput(addr string, input *gamepb.PlayerInput) {
-	playerID := input.PlayerId
-	if playerID == "" {
-		return
-	}
-
-	// Verify this player exists
-	s.mu.RLock()
-	_, exists := s.playerMap[playerID]
-	s.mu.RUnlock()
-
-	if !exists {
-		return
-	}
-
-	// Apply input to game state
-	s.engine.ApplyInput(playerID, game.Input{
-		Sequence:  input.Sequence,
-		Timestamp: input.Timestamp,
-		Movement: game.Vec2{
-			X: input.Movement.GetX(),
-			Y: input.Movement.GetY(),
-		},
-		Jump:    input.Jump,
-		Action1: input.GetAction_1(),
-		Action2: input.GetAction_2(),
-	})
-}
\ No newline at end of file
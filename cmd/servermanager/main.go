@@ -1,93 +1,80 @@
+// ServerManager - runs game servers for rooms on this host and exposes an
+// HTTP API to allocate and release them (see orchestrator.Manager)
 package main
 
 import (
-	"fmt"
+	"context"
+	"errors"
+	"flag"
 	"log"
-	"net"
+	"net/http"
 	"os"
-	"os/exec"
-	"sync"
-)
-
-// GameServerProcess manages a child game server process for one room
-type GameServerProcess struct {
-	RoomID    string
-	Port      int
-	Process   *exec.Cmd
-	UDPAddr   *net.UDPAddr
-	mu        sync.RWMutex
-}
-
-// GameServerManager spawns and manages game server instances
-type GameServerManager struct {
-	servers  map[int]*GameServerProcess
-	portMux  sync.Mutex
-	nextPort int
-	mu       sync.RWMutex
-}
-
-func NewGameServerManager() *GameServerManager {
-	return &GameServerManager{
-		servers:  make(map[int]*GameServerProcess),
-		nextPort: 9100, // Start at 9100, increment for each room
-	}
-}
-
-// Spawn creates a new game server process for a room
-func (g *GameServerManager) Spawn(roomID string) (*GameServerProcess, error) {
-	g.portMux.Lock()
-	port := g.nextPort
-	g.nextPort++
-	g.portMux.Unlock()
+	"os/signal"
+	"syscall"
+	"time"
 
-	// Build command to spawn server
-	cmd := exec.Command("./bin/server",
-		"-port", fmt.Sprintf("%d", port),
-		"-http", fmt.Sprintf("%d", port+1000), // HTTP on port+1000
-	)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	"github.com/LemmyAI/gameserver/internal/orchestrator"
+)
 
-	// Set environment to identify this server
-	cmd.Env = append(os.Environ(), fmt.Sprintf("ROOM_ID=%s", roomID))
+func main() {
+	httpPort := flag.String("http", "8090", "HTTP API port")
+	host := flag.String("host", "127.0.0.1", "Host advertised in game server addresses")
+	binary := flag.String("binary", orchestrator.DefaultServerBinary, "Game server executable")
+	launcherName := flag.String("launcher", "process", "How to run game servers: process or inprocess")
+	basePort := flag.Int("base-port", orchestrator.DefaultBasePort, "First game server UDP port")
+	portCount := flag.Int("port-count", orchestrator.DefaultPortCount, "Number of game server ports")
+	spawnTimeout := flag.Duration("spawn-timeout", 5*time.Second, "How long a game server has to become ready")
+	flag.Parse()
 
-	if err := cmd.Start(); err != nil {
-		return nil, fmt.Errorf("failed to start server: %w", err)
+	// MANAGER_SECRET signs the webbridge's API requests. SESSION_SECRET
+	// signs its session tokens and goes to every game server.
+	managerSecret := os.Getenv("MANAGER_SECRET")
+	if managerSecret == "" {
+		log.Fatalf("MANAGER_SECRET must be set (the webbridge signs its requests with it)")
 	}
-
-	// Resolve UDP address
-	addr, err := net.ResolveUDPAddr("udp", fmt.Sprintf("127.0.0.1:%d", port))
-	if err != nil {
-		cmd.Process.Kill()
-		return nil, fmt.Errorf("failed to resolve address: %w", err)
+	sessionSecret := os.Getenv("SESSION_SECRET")
+	if sessionSecret == "" {
+		log.Fatalf("SESSION_SECRET must be set to the webbridge's")
 	}
 
-	gs := &GameServerProcess{
-		RoomID:  roomID,
-		Port:    port,
-		Process: cmd,
-		UDPAddr: addr,
+	var launcher orchestrator.Launcher
+	switch *launcherName {
+	case "process":
+		launcher = &orchestrator.ProcessLauncher{Binary: *binary}
+	case "inprocess":
+		launcher = &orchestrator.InProcessLauncher{}
+	default:
+		log.Fatalf("Unknown launcher: %s", *launcherName)
 	}
 
-	g.mu.Lock()
-	g.servers[port] = gs
-	g.mu.Unlock()
+	config := orchestrator.DefaultManagerConfig()
+	config.Host = *host
+	config.SpawnTimeout = *spawnTimeout
+	config.Env = []string{"SESSION_SECRET=" + sessionSecret}
+	manager := orchestrator.NewManager(config, launcher, orchestrator.NewPortPool(*basePort, *portCount))
 
-	log.Printf("🚀 Spawned game server for room %s on UDP :%d", roomID, port)
-	return gs, nil
-}
+	// Shut down cleanly on Ctrl+C / SIGTERM, taking the game servers along
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-// Get retrieves a game server by port
-func (g *GameServerManager) Get(port int) *GameServerProcess {
-	g.mu.RLock()
-	defer g.mu.RUnlock()
-	return g.servers[port]
-}
+	srv := &http.Server{Addr: ":" + *httpPort, Handler: manager.Handler(managerSecret)}
+	serverErr := make(chan error, 1)
+	go func() { serverErr <- srv.ListenAndServe() }()
+	log.Printf("🧭 ServerManager: http://localhost:%s (%s launcher, UDP ports %d-%d)",
+		*httpPort, *launcherName, *basePort, *basePort+*portCount-1)
 
-// Kill stops a game server process
-func (gs *GameServerProcess) Kill() error {
-	if gs.Process != nil && gs.Process.Process != nil {
-		return gs.Process.Process.Kill()
+	select {
+	case err := <-serverErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("HTTP server error: %v", err)
+		}
+	case <-ctx.Done():
+		log.Println("🛑 Shutting down...")
 	}
-	return nil
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	srv.Shutdown(shutdownCtx)
+	manager.Close()
+	log.Println("👋 ServerManager stopped")
 }
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"log"
+	"strings"
+
+	"github.com/LemmyAI/gameserver/internal/room"
+)
+
+// ChatMsg is a chat line sent to browsers
+type ChatMsg struct {
+	Type     string `json:"type"`
+	ID       string `json:"id"`
+	From     string `json:"from"`
+	FromName string `json:"fromName"`
+	To       string `json:"to,omitempty"`
+	Text     string `json:"text"`
+	Time     int64  `json:"time"`
+	Whisper  bool   `json:"whisper,omitempty"`
+}
+
+func chatMsg(m room.ChatMessage) ChatMsg {
+	return ChatMsg{
+		Type:     "chat",
+		ID:       m.ID,
+		From:     m.From,
+		FromName: m.FromName,
+		To:       m.To,
+		Text:     m.Text,
+		Time:     m.Time.UnixMilli(),
+		Whisper:  m.IsWhisper(),
+	}
+}
+
+// handleChat delivers a chat message: whispers to sender and target,
+// everything else to the whole room
+func (b *Bridge) handleChat(rm *room.Room, m *room.ChatMessage) {
+	if m.IsWhisper() {
+		b.sendToPlayers([]string{m.From, m.To}, chatMsg(*m))
+		return
+	}
+	b.broadcastToRoom(rm.ID, chatMsg(*m))
+}
+
+// sendChat posts a browser's chat line to its room
+func (b *Bridge) sendChat(client *BrowserClient, m *ChatSendMsg) {
+	rm := b.rooms.Get(client.room())
+	if rm == nil {
+		return
+	}
+	if _, err := rm.Chat(client.playerID, m.To, m.Text); err != nil {
+		client.send(map[string]interface{}{
+			"type":  "chat_error",
+			"error": err.Error(),
+		})
+	}
+}
+
+// sendChatHistory catches a new member up on the room's chat
+func (b *Bridge) sendChatHistory(client *BrowserClient, rm *room.Room) {
+	history := rm.ChatHistory()
+	msgs := make([]ChatMsg, 0, len(history))
+	for _, m := range history {
+		msgs = append(msgs, chatMsg(m))
+	}
+	client.send(map[string]interface{}{
+		"type":     "chat_history",
+		"messages": msgs,
+	})
+}
+
+// chatFilter builds the profanity filter from a comma-separated word list
+func chatFilter(blocklist string) room.ChatFilter {
+	var words []string
+	for _, w := range strings.Split(blocklist, ",") {
+		if w = strings.TrimSpace(w); w != "" {
+			words = append(words, w)
+		}
+	}
+	if len(words) == 0 {
+		return nil
+	}
+	log.Printf("🧼 Chat filter: %d words", len(words))
+	return room.NewWordFilter(words...)
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// WebSocket keepalive and flow control
+const (
+	writeWait      = 10 * time.Second  // Longest a single frame may take to write
+	pongWait       = 60 * time.Second  // Browser must answer a ping within this
+	pingPeriod     = pongWait * 9 / 10 // How often browsers are pinged
+	maxMessageSize = 64 << 10          // Largest frame a browser may send
+	sendBuffer     = 256               // Frames queued per browser before it's dropped as too slow
+)
+
+type BrowserClient struct {
+	ws         *websocket.Conn
+	playerID   string
+	version    int           // Negotiated protocol version (see messages.go)
+	format     string        // FormatJSON or FormatProtobuf
+	lastInput  uint64        // Sequence of the last input passed on
+	ackedInput atomic.Uint64 // Last applied sequence sent in an input_ack
+
+	// Room membership. The client's own goroutine changes it as the
+	// browser joins and leaves; hosts kicking them clear it from theirs.
+	memberMu    sync.RWMutex
+	displayName string
+	roomID      string
+	spectator   bool   // Watching only: no inputs, no player slot
+	followID    string // Player the spectator's camera follows
+
+	// Outbound frames, written only by writeLoop
+	out        chan wsFrame
+	stateMu    sync.Mutex
+	state      []byte        // Latest room state not yet written
+	stateReady chan struct{} // Signals state was set
+	closeCode  int
+	closeText  string
+	done       chan struct{} // Closed to stop the writer
+	closeOnce  sync.Once
+	flushed    chan struct{} // Closed once the writer has exited
+	left       chan struct{} // Closed once the bridge has cleaned up after it
+}
+
+type wsFrame struct {
+	kind int // websocket.TextMessage or websocket.BinaryMessage
+	data []byte
+}
+
+// newBrowserClient wraps a browser connection and starts its writer
+func newBrowserClient(conn *websocket.Conn, playerID string) *BrowserClient {
+	c := wrapBrowser(conn, playerID)
+	go c.writeLoop()
+	return c
+}
+
+// wrapBrowser wraps a browser connection without starting its writer
+func wrapBrowser(conn *websocket.Conn, playerID string) *BrowserClient {
+	c := &BrowserClient{
+		ws:          conn,
+		playerID:    playerID,
+		displayName: "Player",
+		out:         make(chan wsFrame, sendBuffer),
+		stateReady:  make(chan struct{}, 1),
+		closeCode:   websocket.CloseNormalClosure,
+		done:        make(chan struct{}),
+		flushed:     make(chan struct{}),
+		left:        make(chan struct{}),
+	}
+
+	conn.SetReadLimit(maxMessageSize)
+	conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+	return c
+}
+
+// room returns the ID of the room the client is in, "" if none
+func (c *BrowserClient) room() string {
+	c.memberMu.RLock()
+	defer c.memberMu.RUnlock()
+	return c.roomID
+}
+
+// isSpectator reports whether the client is watching rather than playing
+func (c *BrowserClient) isSpectator() bool {
+	c.memberMu.RLock()
+	defer c.memberMu.RUnlock()
+	return c.spectator
+}
+
+// following returns the player a spectator follows, "" for none
+func (c *BrowserClient) following() string {
+	c.memberMu.RLock()
+	defer c.memberMu.RUnlock()
+	return c.followID
+}
+
+// name returns the client's display name
+func (c *BrowserClient) name() string {
+	c.memberMu.RLock()
+	defer c.memberMu.RUnlock()
+	return c.displayName
+}
+
+// setName changes the client's display name
+func (c *BrowserClient) setName(name string) {
+	c.memberMu.Lock()
+	defer c.memberMu.Unlock()
+	c.displayName = name
+}
+
+// enterRoom puts the client in a room as a player or spectator
+func (c *BrowserClient) enterRoom(roomID, name string, spectator bool) {
+	c.memberMu.Lock()
+	defer c.memberMu.Unlock()
+	c.roomID = roomID
+	c.displayName = name
+	c.spectator = spectator
+	c.followID = ""
+}
+
+// leaveRoom takes the client out of roomID. Returns false if they'd
+// already left it (or were never in it).
+func (c *BrowserClient) leaveRoom(roomID string) bool {
+	c.memberMu.Lock()
+	defer c.memberMu.Unlock()
+	if roomID == "" || c.roomID != roomID {
+		return false
+	}
+	c.roomID = ""
+	c.spectator = false
+	c.followID = ""
+	return true
+}
+
+// follow points a spectator's camera at a player
+func (c *BrowserClient) follow(playerID string) {
+	c.memberMu.Lock()
+	defer c.memberMu.Unlock()
+	c.followID = playerID
+}
+
+// send queues a JSON message for the browser. Never blocks: a browser
+// that has fallen sendBuffer frames behind is disconnected.
+func (c *BrowserClient) send(msg interface{}) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		log.Printf("⚠️  Failed to encode message for %s: %v", c.playerID, err)
+		return
+	}
+	c.enqueue(wsFrame{kind: websocket.TextMessage, data: data})
+}
+
+// sendBinary queues an encoded gamepb.Message for a protobuf-mode browser
+func (c *BrowserClient) sendBinary(data []byte) {
+	c.enqueue(wsFrame{kind: websocket.BinaryMessage, data: data})
+}
+
+// sendState sets the room state to write next. State that the browser
+// hasn't been sent yet is replaced, not queued behind.
+func (c *BrowserClient) sendState(msg interface{}) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	c.stateMu.Lock()
+	c.state = data
+	c.stateMu.Unlock()
+
+	select {
+	case c.stateReady <- struct{}{}:
+	default:
+	}
+}
+
+func (c *BrowserClient) enqueue(f wsFrame) {
+	select {
+	case <-c.done:
+		return
+	default:
+	}
+
+	select {
+	case c.out <- f:
+	default:
+		log.Printf("🐌 %s is too slow (%d frames behind), disconnecting", c.playerID, sendBuffer)
+		c.close(websocket.CloseTryAgainLater, "too slow")
+	}
+}
+
+// close stops the writer once what's already queued is written, then
+// closes the connection with the given close code
+func (c *BrowserClient) close(code int, text string) {
+	c.closeOnce.Do(func() {
+		c.closeCode, c.closeText = code, text
+		close(c.done)
+	})
+}
+
+// writeLoop is the only goroutine that writes to the connection
+func (c *BrowserClient) writeLoop() {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.ws.Close()
+		close(c.flushed)
+	}()
+
+	for {
+		select {
+		case f := <-c.out:
+			if err := c.write(f.kind, f.data); err != nil {
+				c.close(websocket.CloseAbnormalClosure, "")
+				return
+			}
+
+		case <-c.stateReady:
+			if state := c.takeState(); state != nil {
+				if err := c.write(websocket.TextMessage, state); err != nil {
+					c.close(websocket.CloseAbnormalClosure, "")
+					return
+				}
+			}
+
+		case <-ticker.C:
+			if err := c.write(websocket.PingMessage, nil); err != nil {
+				c.close(websocket.CloseAbnormalClosure, "")
+				return
+			}
+
+		case <-c.done:
+			c.flush()
+			return
+		}
+	}
+}
+
+// flush writes what's still queued and the close frame, all within one
+// writeWait
+func (c *BrowserClient) flush() {
+	c.ws.SetWriteDeadline(time.Now().Add(writeWait))
+	switch c.closeCode {
+	case websocket.CloseAbnormalClosure:
+		return // Connection already broken
+	case websocket.CloseTryAgainLater:
+		// Too far behind to catch up: skip straight to the close frame
+	default:
+	drain:
+		for {
+			select {
+			case f := <-c.out:
+				if c.ws.WriteMessage(f.kind, f.data) != nil {
+					return
+				}
+			default:
+				break drain
+			}
+		}
+	}
+	c.ws.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(c.closeCode, c.closeText))
+}
+
+func (c *BrowserClient) write(kind int, data []byte) error {
+	c.ws.SetWriteDeadline(time.Now().Add(writeWait))
+	return c.ws.WriteMessage(kind, data)
+}
+
+func (c *BrowserClient) takeState() []byte {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+	state := c.state
+	c.state = nil
+	return state
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// wsPair connects a browser-side WebSocket to a bridge-side one
+func wsPair(t *testing.T) (bridge, browser *websocket.Conn) {
+	t.Helper()
+	conns := make(chan *websocket.Conn, 1)
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		conns <- conn
+	}))
+	t.Cleanup(srv.Close)
+
+	browser, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { browser.Close() })
+	return <-conns, browser
+}
+
+// readUntilClose reads text frames until the close frame, returning them
+// and the close code
+func readUntilClose(t *testing.T, browser *websocket.Conn) ([]string, int) {
+	t.Helper()
+	browser.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var frames []string
+	for {
+		_, data, err := browser.ReadMessage()
+		var closeErr *websocket.CloseError
+		if errors.As(err, &closeErr) {
+			return frames, closeErr.Code
+		}
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		frames = append(frames, string(data))
+	}
+}
+
+func TestClientWritesInOrder(t *testing.T) {
+	conn, browser := wsPair(t)
+	c := newBrowserClient(conn, "p1")
+
+	for i := 0; i < 100; i++ {
+		c.send(map[string]int{"n": i})
+	}
+	c.sendBinary([]byte{1, 2, 3})
+
+	browser.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for i := 0; i < 100; i++ {
+		kind, data, err := browser.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := fmt.Sprintf(`{"n":%d}`, i); kind != websocket.TextMessage || string(data) != want {
+			t.Fatalf("frame %d: got %d %s, want %s", i, kind, data, want)
+		}
+	}
+	if kind, data, err := browser.ReadMessage(); err != nil || kind != websocket.BinaryMessage || len(data) != 3 {
+		t.Fatalf("expected the binary frame, got %d %v %v", kind, data, err)
+	}
+
+	c.close(websocket.CloseNormalClosure, "bye")
+	if _, code := readUntilClose(t, browser); code != websocket.CloseNormalClosure {
+		t.Errorf("expected a normal close, got %d", code)
+	}
+	<-c.flushed
+}
+
+func TestClientDrainsOnClose(t *testing.T) {
+	conn, browser := wsPair(t)
+	c := wrapBrowser(conn, "p1")
+
+	// Everything queued before the close still goes out, then the close
+	for i := 0; i < 10; i++ {
+		c.send(i)
+	}
+	c.close(websocket.CloseGoingAway, "shutting down")
+	c.send("too late")
+	go c.writeLoop()
+
+	frames, code := readUntilClose(t, browser)
+	if len(frames) != 10 || frames[0] != "0" || frames[9] != "9" {
+		t.Errorf("expected the 10 queued frames in order, got %v", frames)
+	}
+	if code != websocket.CloseGoingAway {
+		t.Errorf("expected going away, got %d", code)
+	}
+	select {
+	case <-c.flushed:
+	case <-time.After(time.Second):
+		t.Fatal("expected the writer to exit")
+	}
+}
+
+func TestClientTooSlow(t *testing.T) {
+	conn, browser := wsPair(t)
+	c := wrapBrowser(conn, "p1")
+
+	// A browser sendBuffer frames behind is dropped on the next one
+	for i := 0; i < sendBuffer; i++ {
+		c.send(i)
+	}
+	select {
+	case <-c.done:
+		t.Fatal("expected a full buffer to be fine")
+	default:
+	}
+	c.send(sendBuffer)
+	select {
+	case <-c.done:
+	default:
+		t.Fatal("expected an overflowing browser closed")
+	}
+
+	go c.writeLoop()
+	frames, code := readUntilClose(t, browser)
+	if code != websocket.CloseTryAgainLater {
+		t.Errorf("expected try again later, got %d", code)
+	}
+	if len(frames) >= sendBuffer {
+		t.Errorf("expected the backlog skipped, got %d frames", len(frames))
+	}
+}
+
+func TestClientStateCoalescing(t *testing.T) {
+	conn, browser := wsPair(t)
+	c := wrapBrowser(conn, "p1")
+
+	// Only the latest unsent state is kept, with a single wakeup
+	c.sendState("s1")
+	c.sendState("s2")
+	c.sendState("s3")
+	if len(c.stateReady) != 1 {
+		t.Fatalf("expected one pending wakeup, got %d", len(c.stateReady))
+	}
+	if state := c.takeState(); string(state) != `"s3"` {
+		t.Fatalf("expected the latest state, got %s", state)
+	}
+	if state := c.takeState(); state != nil {
+		t.Fatalf("expected state taken once, got %s", state)
+	}
+
+	go c.writeLoop()
+	c.sendState("s4")
+	browser.SetReadDeadline(time.Now().Add(5 * time.Second))
+	// The stale wakeup finds nothing to write; s4 is next
+	if _, data, err := browser.ReadMessage(); err != nil || string(data) != `"s4"` {
+		t.Fatalf("expected s4, got %s %v", data, err)
+	}
+	c.close(websocket.CloseNormalClosure, "")
+	<-c.flushed
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"github.com/LemmyAI/gameserver/internal/cluster"
+)
+
+// joinCluster registers this bridge as a node in the room directory.
+// DIRECTORY_URL points at a directory another node serves; without it the
+// bridge keeps its own and serves it at /cluster/ to other nodes.
+// CLUSTER_SECRET signs directory requests and must be the same on every
+// node; without it this node's directory isn't served. NODE_ID, NODE_URL
+// (how browsers reach this node) and NODE_CAPACITY (most rooms, 0 for no
+// limit) describe the node.
+func (b *Bridge) joinCluster() {
+	secret := os.Getenv("CLUSTER_SECRET")
+	node := cluster.Node{
+		ID:  os.Getenv("NODE_ID"),
+		URL: os.Getenv("NODE_URL"),
+	}
+	if node.ID == "" {
+		node.ID = uuid.New().String()[:8]
+	}
+	if node.URL == "" {
+		node.URL = "http://localhost:" + listenPort()
+	}
+	if n, err := strconv.Atoi(os.Getenv("NODE_CAPACITY")); err == nil && n >= 0 {
+		node.Capacity = n
+	}
+
+	var dir cluster.Directory
+	if dirURL := os.Getenv("DIRECTORY_URL"); dirURL != "" {
+		if secret == "" {
+			log.Fatalf("❌ DIRECTORY_URL needs CLUSTER_SECRET to sign directory requests")
+		}
+		dir = cluster.NewClient(dirURL, secret)
+		log.Printf("🗺️  Room directory: %s", dirURL)
+	} else {
+		dir = cluster.NewMemoryDirectory(cluster.DefaultNodeTTL)
+		if secret != "" {
+			b.directoryHandler = http.StripPrefix("/cluster", cluster.Handler(dir, secret))
+		} else {
+			log.Printf("⚠️  CLUSTER_SECRET not set, room directory not shared with other nodes")
+		}
+	}
+
+	member, err := cluster.Join(dir, node, cluster.DefaultHeartbeatInterval, b.rooms.Count)
+	if err != nil {
+		log.Fatalf("❌ Failed to join room directory: %v", err)
+	}
+	b.node = member
+	log.Printf("🖥️  Node %s at %s (capacity %d)", node.ID, node.URL, node.Capacity)
+
+	// Rooms restored from the store live here
+	for _, rm := range b.rooms.AllRooms() {
+		b.placeRoom(rm.ID)
+	}
+}
+
+// placeRoom records that a room lives on this node
+func (b *Bridge) placeRoom(roomID string) {
+	if err := b.node.Directory().Assign(roomID, b.node.ID()); err != nil {
+		log.Printf("⚠️  Failed to place room %s on node %s: %v", roomID, b.node.ID(), err)
+	}
+}
+
+// deleteRoom removes a room from the registry and the directory
+func (b *Bridge) deleteRoom(roomID string) {
+	b.rooms.Delete(roomID)
+	b.releaseRoom(roomID)
+}
+
+// releaseRoom drops a room from the directory
+func (b *Bridge) releaseRoom(roomID string) {
+	if err := b.node.Directory().Release(roomID); err != nil && !errors.Is(err, cluster.ErrUnknownRoom) {
+		log.Printf("⚠️  Failed to release room %s: %v", roomID, err)
+	}
+}
+
+// roomOwner returns the node hosting a room when that's another live node.
+// Rooms the directory doesn't know, or whose node is down, are handled
+// here (and usually aren't found).
+func (b *Bridge) roomOwner(roomID string) (cluster.Node, bool) {
+	node, err := b.node.Directory().Lookup(roomID)
+	if err != nil || node.ID == b.node.ID() {
+		return cluster.Node{}, false
+	}
+	return node, true
+}
+
+// redirectToOwner sends an HTTP request for a room on another node there
+func (b *Bridge) redirectToOwner(w http.ResponseWriter, r *http.Request, roomID string) bool {
+	node, elsewhere := b.roomOwner(roomID)
+	if !elsewhere {
+		return false
+	}
+	http.Redirect(w, r, node.URL+r.URL.RequestURI(), http.StatusTemporaryRedirect)
+	return true
+}
+
+// redirectCreate sends a room creation to another node when this one is
+// full. Returns true if the request was answered.
+func (b *Bridge) redirectCreate(w http.ResponseWriter, r *http.Request) bool {
+	node, err := b.node.Directory().Pick(b.node.ID())
+	switch {
+	case errors.Is(err, cluster.ErrNoCapacity):
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		return true
+	case err != nil:
+		// Directory unreachable: keep the room here
+		log.Printf("⚠️  Room placement failed, creating locally: %v", err)
+		return false
+	case node.ID != b.node.ID():
+		http.Redirect(w, r, node.URL+r.URL.RequestURI(), http.StatusTemporaryRedirect)
+		return true
+	}
+	return false
+}
+
+// sendReconnect tells a browser its room lives on another node
+func (b *Bridge) sendReconnect(client *BrowserClient, node cluster.Node, roomID, invite string) {
+	link := node.URL + "/room/" + roomID
+	if invite != "" {
+		link += "?invite=" + url.QueryEscape(invite)
+	}
+	client.send(map[string]interface{}{
+		"type":   "reconnect",
+		"roomId": roomID,
+		"node":   node.ID,
+		"url":    link,
+	})
+	log.Printf("↪️  %s sent to node %s for room %s", client.playerID, node.ID, roomID)
+}
+
+// listenPort is the HTTP port (Render sets PORT)
+func listenPort() string {
+	if port := os.Getenv("PORT"); port != "" {
+		return port
+	}
+	return "8081"
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/LemmyAI/gameserver/internal/orchestrator"
+	"github.com/LemmyAI/gameserver/internal/protocol/gamepb"
+)
+
+// Embedded engines see room control as coming from embeddedAddr and each
+// player from embeddedAddr/<playerID>, the way a game server process sees
+// the bridge's room connection and player sessions
+const embeddedAddr = "webbridge"
+
+// gameLink is a game server the bridge hands messages to directly, with no
+// UDP hop
+type gameLink interface {
+	Deliver(addr string, msg *gamepb.Message)
+}
+
+// embeddedLauncher runs each room's game engine inside the bridge
+// (-engine embedded). Its messages go straight to the room's browsers.
+type embeddedLauncher struct {
+	bridge *Bridge
+}
+
+// Launch starts the room's engine
+func (l *embeddedLauncher) Launch(ctx context.Context, spec orchestrator.Spec) (orchestrator.Instance, error) {
+	if spec.RoomID == "" {
+		return nil, orchestrator.ErrInvalidSpec
+	}
+	e := &embeddedEngine{done: make(chan struct{})}
+	e.server = orchestrator.NewEngineServer(spec, &roomBroadcaster{bridge: l.bridge, roomID: spec.RoomID})
+	e.server.RequireSessions(l.bridge.sessions)
+	e.server.Start()
+	return e, nil
+}
+
+// embeddedEngine is a room engine running inside the bridge
+type embeddedEngine struct {
+	server   *orchestrator.EngineServer
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+func (e *embeddedEngine) Addr() string          { return "" }
+func (e *embeddedEngine) PID() int              { return 0 }
+func (e *embeddedEngine) Done() <-chan struct{} { return e.done }
+
+func (e *embeddedEngine) ExitStatus() string {
+	select {
+	case <-e.done:
+		return "stopped"
+	default:
+		return ""
+	}
+}
+
+// Stop stops the engine's tick loop
+func (e *embeddedEngine) Stop(grace time.Duration) error {
+	e.stopOnce.Do(func() {
+		e.server.Stop()
+		close(e.done)
+	})
+	return nil
+}
+
+// Deliver hands the engine a message from addr
+func (e *embeddedEngine) Deliver(addr string, msg *gamepb.Message) {
+	e.server.Handle(addr, msg)
+}
+
+// roomBroadcaster is the game.Broadcaster of an embedded engine: whatever
+// the engine sends a player goes to their session, as if over UDP
+type roomBroadcaster struct {
+	bridge *Bridge
+	roomID string
+}
+
+// Broadcast passes the message to every player's session but excludeID's.
+// Spectators only get what the engine sends them through its spectator
+// feed.
+func (r *roomBroadcaster) Broadcast(msg *gamepb.Message, excludeID string) error {
+	gr := r.gameRoom()
+	if gr == nil {
+		return nil
+	}
+	gr.Mu.RLock()
+	sessions := make([]*playerSession, 0, len(gr.sessions))
+	for id, s := range gr.sessions {
+		if id != excludeID && !s.spectator {
+			sessions = append(sessions, s)
+		}
+	}
+	gr.Mu.RUnlock()
+
+	for _, s := range sessions {
+		r.bridge.handleSessionMessage(gr, s, msg)
+	}
+	return nil
+}
+
+// SendTo passes the message to the session at addr
+func (r *roomBroadcaster) SendTo(addr string, msg *gamepb.Message) error {
+	gr := r.gameRoom()
+	if gr == nil {
+		return nil
+	}
+	gr.Mu.RLock()
+	var session *playerSession
+	for _, s := range gr.sessions {
+		if s.addr == addr {
+			session = s
+			break
+		}
+	}
+	gr.Mu.RUnlock()
+
+	if session != nil {
+		r.bridge.handleSessionMessage(gr, session, msg)
+	}
+	return nil
+}
+
+func (r *roomBroadcaster) gameRoom() *GameRoom {
+	r.bridge.mu.RLock()
+	defer r.bridge.mu.RUnlock()
+	return r.bridge.gameRooms[r.roomID]
+}
@@ -4,20 +4,31 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"log"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
-	"os/exec"
+	"os/signal"
+	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/google/uuid"
 
+	"github.com/LemmyAI/gameserver/internal/auth"
+	"github.com/LemmyAI/gameserver/internal/cluster"
+	"github.com/LemmyAI/gameserver/internal/matchmaker"
+	"github.com/LemmyAI/gameserver/internal/orchestrator"
 	"github.com/LemmyAI/gameserver/internal/protocol"
 	"github.com/LemmyAI/gameserver/internal/protocol/gamepb"
 	"github.com/LemmyAI/gameserver/internal/room"
@@ -28,22 +39,23 @@ var upgrader = websocket.Upgrader{
 	CheckOrigin: func(r *http.Request) bool { return true },
 }
 
-type BrowserClient struct {
-	ws       *websocket.Conn
-	playerID string
-	name     string
-	roomID   string
-}
-
-// GameRoom holds the game server process and connection for one room
+// GameRoom holds the game server and connection for one room
 type GameRoom struct {
 	ID         string
-	UDPConn    *net.UDPConn
+	Port       int // Leased UDP port (HTTP is Port+orchestrator.HTTPPortOffset)
+	UDPConn    *net.UDPConn // Room control; players each have a session
 	UDPAddr    *net.UDPAddr
-	Process    *exec.Cmd
-	State      map[string]*gamepb.PlayerState
+	Server     orchestrator.Instance
 	Mu         sync.RWMutex
 	WebRTC     *webrtc.Manager // WebRTC manager for this room
+
+	// Per-player links to the game server (see session.go)
+	sessions map[string]*playerSession // playerID -> session
+	names    map[string]string         // playerID -> display name
+
+	// Supervision (see supervisor.go)
+	stopping bool // Exit was requested, not a crash
+	status   ServerStatus
 }
 
 type Bridge struct {
@@ -51,138 +63,171 @@ type Bridge struct {
 	gameRooms    map[string]*GameRoom // roomID -> game room
 	mu           sync.RWMutex
 	rooms        *room.Registry
-	basePort     int
-}
+	matchmaker   *matchmaker.Matchmaker
 
-func NewBridge() *Bridge {
-	config := room.DefaultConfig()
-	config.RoomTTL = 1 * time.Minute // Kill empty rooms after 1 minute
+	// Game servers
+	launcher     orchestrator.Launcher
+	ports        *orchestrator.PortPool
+	spawning     map[string]*pendingSpawn // roomID -> server still starting
+	spawnTimeout time.Duration
+	maxRestarts  int
 
-	bridge := &Bridge{
-		clients:   make(map[*websocket.Conn]*BrowserClient),
-		gameRooms: make(map[string]*GameRoom),
-		rooms:     room.NewRegistry(config),
-		basePort:  9100, // Game servers start at port 9100
-	}
+	// Session tokens shared with spawned game servers
+	sessionSecret string
+	sessions      *auth.Signer
 
-	// Register cleanup callback - kill game server when room expires
-	bridge.rooms.OnRoomExpired(func(r *room.Room) {
-		log.Printf("🗑️  Room %s expired (empty for 1 minute), stopping game server", r.ID)
-		bridge.stopGameRoom(r.ID)
-	})
+	// How far spectators lag behind players (anti-ghosting)
+	spectatorDelay time.Duration
 
-	return bridge
+	// Room directory shared with other nodes (see cluster.go)
+	node             *cluster.Member
+	directoryHandler http.Handler // Set when this node serves the directory
 }
 
-// spawnGameServer creates a new game server process for a room
-func (b *Bridge) spawnGameServer(roomID string) (*GameRoom, error) {
-	b.mu.Lock()
-	defer b.mu.Unlock()
+// sessionTTL is how long a minted session token is valid for ClientHello
+const sessionTTL = 5 * time.Minute
 
-	// Check if already exists
-	if gr, exists := b.gameRooms[roomID]; exists {
-		return gr, nil
-	}
+// apiTokenTTL is how long a browser's token is valid for the room HTTP API
+const apiTokenTTL = 12 * time.Hour
 
-	// Calculate port (simple: 9100 + hash of roomID)
-	port := b.basePort + (int(roomID[0]) % 1000)
-	httpPort := port + 1000
+// identityTTL is how long a browser can reconnect as the same player
+const identityTTL = 24 * time.Hour
 
-	// Spawn server process
-	cmd := exec.Command("./bin/server",
-		"-udp", fmt.Sprintf("%d", port),
-		"-http", fmt.Sprintf("%d", httpPort),
-		"-room", roomID,
-	)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+// takeoverWait caps how long a reconnecting browser waits for the bridge
+// to clean up after its previous connection
+const takeoverWait = 5 * time.Second
 
-	if err := cmd.Start(); err != nil {
-		return nil, fmt.Errorf("failed to spawn server: %w", err)
-	}
+var errUnknownAction = errors.New("unknown action")
 
-	// Wait a bit for server to start
-	time.Sleep(100 * time.Millisecond)
+// NewBridge creates a bridge whose room engines run as game server
+// processes ("process") or inside the bridge ("embedded")
+func NewBridge(engine string) *Bridge {
+	config := room.DefaultConfig()
+	config.RoomTTL = 1 * time.Minute // Kill empty rooms after 1 minute
 
-	// Resolve UDP address
-	addr, err := net.ResolveUDPAddr("udp", fmt.Sprintf("127.0.0.1:%d", port))
-	if err != nil {
-		cmd.Process.Kill()
-		return nil, fmt.Errorf("failed to resolve address: %w", err)
+	secret := os.Getenv("SESSION_SECRET")
+	if secret == "" {
+		secret = auth.GenerateSecret()
 	}
+	// Invites outlive a restart only if SESSION_SECRET is fixed
+	config.InviteSecret = []byte("invite:" + secret)
 
-	// Create UDP connection to the new server
-	conn, err := net.DialUDP("udp", nil, addr)
-	if err != nil {
-		cmd.Process.Kill()
-		return nil, fmt.Errorf("failed to dial server: %w", err)
+	// CHAT_BLOCKLIST masks these words in chat (comma-separated)
+	if filter := chatFilter(os.Getenv("CHAT_BLOCKLIST")); filter != nil {
+		config.ChatFilter = filter
 	}
 
-	gr := &GameRoom{
-		ID:      roomID,
-		UDPConn: conn,
-		UDPAddr: addr,
-		Process: cmd,
-		State:   make(map[string]*gamepb.PlayerState),
-		WebRTC:  webrtc.NewManager(roomID),
+	// ROOM_STORE keeps rooms (and their links) across restarts
+	var store room.Store = room.NewMemoryStore()
+	if path := os.Getenv("ROOM_STORE"); path != "" {
+		fileStore, err := room.OpenFileStore(path)
+		if err != nil {
+			log.Fatalf("❌ Failed to open room store %s: %v", path, err)
+		}
+		log.Printf("💾 Room store: %s (%d rooms restored)", path, fileStore.Count())
+		store = fileStore
 	}
-	b.gameRooms[roomID] = gr
 
-	// Start receiving for this room
-	go b.receiveUDP(gr)
-	
-	// Start WebRTC track handler
-	go b.handleWebRTCTracks(gr)
+	bridge := &Bridge{
+		clients:       make(map[*websocket.Conn]*BrowserClient),
+		gameRooms:     make(map[string]*GameRoom),
+		rooms:         room.NewRegistryWithStore(config, store),
+		ports:         orchestrator.NewPortPool(orchestrator.DefaultBasePort, orchestrator.DefaultPortCount),
+		spawning:      make(map[string]*pendingSpawn),
+		spawnTimeout:  defaultSpawnTimeout,
+		maxRestarts:   maxRestartsFromEnv(),
+		sessionSecret: secret,
+		sessions:      auth.NewSigner([]byte(secret)),
+	}
 
-	log.Printf("🚀 Spawned game server for room %s on UDP :%d", roomID, port)
-	return gr, nil
-}
+	switch engine {
+	case "process":
+		bridge.launcher = launcherFromEnv()
+	case "embedded":
+		log.Println("🧩 Room engines run inside the bridge")
+		bridge.launcher = &embeddedLauncher{bridge: bridge}
+	default:
+		log.Fatalf("❌ Unknown room engine: %s", engine)
+	}
 
-func (b *Bridge) receiveUDP(gr *GameRoom) {
-	buf := make([]byte, 4096)
-	for {
-		n, err := gr.UDPConn.Read(buf)
-		if err != nil {
-			log.Printf("UDP read error for room %s: %v", gr.ID, err)
-			return
-		}
+	if delay, err := time.ParseDuration(os.Getenv("SPECTATOR_DELAY")); err == nil {
+		bridge.spectatorDelay = delay
+	}
+	if timeout, err := time.ParseDuration(os.Getenv("SPAWN_TIMEOUT")); err == nil && timeout > 0 {
+		bridge.spawnTimeout = timeout
+	}
 
-		msg, err := protocol.Decode(buf[:n])
-		if err != nil {
-			continue
-		}
+	bridge.joinCluster()
 
-		switch payload := msg.Payload.(type) {
-		case *gamepb.Message_ServerWelcome:
-			log.Printf("🎮 Room %s: Welcome! Player ID: %s", gr.ID, payload.ServerWelcome.PlayerId)
+	// Keep browsers and game servers in step with the rooms
+	go bridge.handleRoomEvents(bridge.rooms.Subscribe())
 
-		case *gamepb.Message_StateDelta:
-			if payload.StateDelta != nil {
-				gr.Mu.Lock()
-				for _, p := range payload.StateDelta.ChangedPlayers {
-					gr.State[p.PlayerId] = p
-				}
-				for _, id := range payload.StateDelta.RemovedPlayers {
-					delete(gr.State, id)
-				}
-				gr.Mu.Unlock()
-				b.broadcastRoomState(gr)
-			}
+	bridge.matchmaker = matchmaker.New(matchmaker.DefaultConfig(), bridge.createMatchRoom)
 
-		case *gamepb.Message_StateSnapshot:
-			if payload.StateSnapshot != nil {
-				gr.Mu.Lock()
-				gr.State = make(map[string]*gamepb.PlayerState)
-				for _, p := range payload.StateSnapshot.Players {
-					gr.State[p.PlayerId] = p
-				}
-				gr.Mu.Unlock()
-				b.broadcastRoomState(gr)
-			}
+	return bridge
+}
+
+// handleRoomEvents reacts to registry events until the registry closes
+func (b *Bridge) handleRoomEvents(sub *room.Subscription) {
+	for e := range sub.C {
+		switch e.Type {
+		case room.EventRoomExpired:
+			log.Printf("🗑️  Room %s expired (empty for 1 minute), stopping game server", e.Room.ID)
+			b.stopGameRoom(e.Room.ID)
+			b.releaseRoom(e.Room.ID)
+		case room.EventPhaseChanged:
+			b.handlePhaseChange(e.Room, e.From, e.To)
+		case room.EventHostChanged:
+			b.broadcastHost(e.Room)
+		case room.EventTeamChanged:
+			b.handleTeamChange(e.Room, e.PlayerID, e.Team)
+		case room.EventChat:
+			b.handleChat(e.Room, e.Chat)
 		}
 	}
 }
 
+// Shutdown tells every browser the bridge is going away, stops all game
+// servers and closes the room registry
+func (b *Bridge) Shutdown() {
+	b.mu.Lock()
+	clients := make([]*BrowserClient, 0, len(b.clients))
+	for _, client := range b.clients {
+		clients = append(clients, client)
+	}
+	roomIDs := make([]string, 0, len(b.gameRooms))
+	for id := range b.gameRooms {
+		roomIDs = append(roomIDs, id)
+	}
+	b.mu.Unlock()
+
+	for _, client := range clients {
+		client.send(map[string]interface{}{
+			"type":   "server_shutdown",
+			"roomId": client.room(),
+		})
+		client.close(websocket.CloseGoingAway, "server shutting down")
+	}
+	for _, client := range clients {
+		<-client.flushed
+	}
+	b.matchmaker.Close()
+	var wg sync.WaitGroup
+	for _, id := range roomIDs {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			b.stopGameRoom(id)
+		}(id)
+	}
+	wg.Wait()
+	b.node.Close()
+	if err := b.rooms.Close(); err != nil {
+		log.Printf("⚠️  Failed to close room registry: %v", err)
+	}
+	log.Printf("👋 Bridge shut down (%d clients notified, %d game servers stopped)", len(clients), len(roomIDs))
+}
+
 type PlayerMsg struct {
 	ID   string  `json:"id"`
 	Name string  `json:"name"`
@@ -191,53 +236,110 @@ type PlayerMsg struct {
 	VX   float32 `json:"vx"`
 	VY   float32 `json:"vy"`
 	Rot  float32 `json:"rot"`
+	Team uint32  `json:"team,omitempty"`
 }
 
+// StateMsg is the full room state
 type StateMsg struct {
-	Type    string      `json:"type"`
-	YourID  string      `json:"yourId"`
-	RoomID  string      `json:"roomId,omitempty"`
+	Type      string      `json:"type"`
+	YourID    string      `json:"yourId"`
+	RoomID    string      `json:"roomId,omitempty"`
+	Players   []PlayerMsg `json:"players"`
+	Following string      `json:"following,omitempty"`
+}
+
+// DeltaMsg carries the players that changed since the last state or delta
+type DeltaMsg struct {
+	Type    string      `json:"type"` // "delta"
+	Tick    uint64      `json:"tick"`
 	Players []PlayerMsg `json:"players"`
+	Removed []string    `json:"removed,omitempty"`
 }
 
-// broadcastRoomState sends state only to players in this room
-func (b *Bridge) broadcastRoomState(gr *GameRoom) {
-	b.mu.RLock()
-	defer b.mu.RUnlock()
+// PlayerSpawnedMsg announces a player entering the game
+type PlayerSpawnedMsg struct {
+	Type   string    `json:"type"` // "player_spawned"
+	Player PlayerMsg `json:"player"`
+}
 
-	gr.Mu.RLock()
-	players := make([]PlayerMsg, 0, len(gr.State))
-	for id, p := range gr.State {
-		x, y := float32(500), float32(500)
-		vx, vy := float32(0), float32(0)
-		if p.Position != nil {
-			x, y = p.Position.X, p.Position.Y
-		}
-		if p.Velocity != nil {
-			vx, vy = p.Velocity.X, p.Velocity.Y
-		}
-		players = append(players, PlayerMsg{
-			ID:  id,
-			X:   x,
-			Y:   y,
-			VX:  vx,
-			VY:  vy,
-			Rot: p.Rotation,
-		})
+// PlayerRemovedMsg announces a player leaving the game
+type PlayerRemovedMsg struct {
+	Type     string `json:"type"` // "player_removed"
+	PlayerID string `json:"playerId"`
+	Name     string `json:"name"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// wantsDeltas reports whether a browser is sent deltas and events after
+// its first full state. Older protocol versions get the full state every
+// time.
+func (b *Bridge) wantsDeltas(client *BrowserClient) bool {
+	return client.format == FormatJSON && client.version >= 2
+}
+
+// deltaMsg converts a state delta for browsers
+func (b *Bridge) deltaMsg(gr *GameRoom, delta *gamepb.GameStateDelta) DeltaMsg {
+	msg := DeltaMsg{
+		Type:    "delta",
+		Tick:    delta.Tick,
+		Players: make([]PlayerMsg, 0, len(delta.ChangedPlayers)),
+		Removed: delta.RemovedPlayers,
 	}
+	for _, p := range delta.ChangedPlayers {
+		msg.Players = append(msg.Players, playerMsg(p, b.playerName(gr, p.PlayerId)))
+	}
+	return msg
+}
+
+// playerMsgs converts player states for browsers
+func (b *Bridge) playerMsgs(gr *GameRoom, states []*gamepb.PlayerState) []PlayerMsg {
+	players := make([]PlayerMsg, 0, len(states))
+	for _, p := range states {
+		players = append(players, playerMsg(p, b.playerName(gr, p.PlayerId)))
+	}
+	return players
+}
+
+// playerName returns a player's display name from the room, remembered
+// so it's still known when the game server reports them gone
+func (b *Bridge) playerName(gr *GameRoom, playerID string) string {
+	gr.Mu.RLock()
+	name, known := gr.names[playerID]
 	gr.Mu.RUnlock()
+	if known {
+		return name
+	}
 
-	for ws, client := range b.clients {
-		if client.roomID == gr.ID {
-			state := StateMsg{
-				Type:    "state",
-				YourID:  client.playerID,
-				RoomID:  gr.ID,
-				Players: players,
-			}
-			ws.WriteJSON(state)
-		}
+	rm := b.rooms.Get(gr.ID)
+	if rm == nil {
+		return ""
+	}
+	p, ok := rm.GetPlayer(playerID)
+	if !ok {
+		return ""
+	}
+	gr.Mu.Lock()
+	gr.names[playerID] = p.Name
+	gr.Mu.Unlock()
+	return p.Name
+}
+
+func playerMsg(p *gamepb.PlayerState, name string) PlayerMsg {
+	msg := PlayerMsg{
+		ID:   p.PlayerId,
+		Name: name,
+		X:    500,
+		Y:    500,
+		Rot:  p.Rotation,
+		Team: p.Team,
+	}
+	if p.Position != nil {
+		msg.X, msg.Y = p.Position.X, p.Position.Y
+	}
+	if p.Velocity != nil {
+		msg.VX, msg.VY = p.Velocity.X, p.Velocity.Y
 	}
+	return msg
 }
 
 // broadcastToRoom sends a message to all clients in a room
@@ -245,81 +347,436 @@ func (b *Bridge) broadcastToRoom(roomID string, msg interface{}) {
 	b.mu.RLock()
 	defer b.mu.RUnlock()
 
-	for ws, client := range b.clients {
-		if client.roomID == roomID {
-			ws.WriteJSON(msg)
+	for _, client := range b.clients {
+		if client.room() == roomID {
+			client.send(msg)
 		}
 	}
 }
 
-// stopGameRoom kills the game server process for a room
+// stopGameRoom gracefully stops the game server process for a room
 func (b *Bridge) stopGameRoom(roomID string) {
 	b.mu.Lock()
-	defer b.mu.Unlock()
+	gr, exists := b.gameRooms[roomID]
+	delete(b.gameRooms, roomID)
+	b.mu.Unlock()
 
-	if gr, exists := b.gameRooms[roomID]; exists {
-		if gr.Process != nil && gr.Process.Process != nil {
-			gr.Process.Process.Kill()
-			gr.UDPConn.Close()
-		}
-		delete(b.gameRooms, roomID)
+	if exists {
+		b.terminate(gr)
 		log.Printf("🛑 Stopped game server for room %s", roomID)
 	}
 }
 
+// handlePhaseChange tells the room and its game server about a new phase
+func (b *Bridge) handlePhaseChange(rm *room.Room, from, to room.Phase) {
+	log.Printf("🚦 Room %s: %s → %s", rm.ID, from, to)
+
+	msg := map[string]interface{}{
+		"type":   "phase_changed",
+		"roomId": rm.ID,
+		"phase":  to,
+		"from":   from,
+	}
+	if to == room.PhaseCountdown {
+		msg["countdownMs"] = rm.CountdownRemaining().Milliseconds()
+	}
+	b.broadcastToRoom(rm.ID, msg)
+
+	b.sendToGameServer(rm.ID, protocol.NewRoomControl(b.controlToken(rm.ID), string(to)))
+
+	if to == room.PhaseClosed {
+		b.stopGameRoom(rm.ID)
+		b.deleteRoom(rm.ID)
+	}
+}
+
+// controlToken mints a token that lets the bridge steer a room's game server
+func (b *Bridge) controlToken(roomID string) string {
+	return b.sessions.MintRole("webbridge", roomID, auth.RoleControl, sessionTTL)
+}
+
+// sendToGameServer sends a message to a room's game server, if running
+func (b *Bridge) sendToGameServer(roomID string, msg *gamepb.Message) {
+	b.mu.RLock()
+	gr, exists := b.gameRooms[roomID]
+	b.mu.RUnlock()
+	if !exists {
+		return
+	}
+	gr.send(msg)
+}
+
+// moderate applies a host moderation action and tells everyone affected.
+// Actions: kick, ban, unban, transfer_host, mute, unmute, lock, unlock.
+func (b *Bridge) moderate(rm *room.Room, hostID, action, targetID, reason string) error {
+	switch action {
+	case "kick", "ban":
+		var err error
+		if action == "ban" {
+			err = rm.Ban(hostID, targetID)
+		} else {
+			err = rm.Kick(hostID, targetID)
+		}
+		if err != nil {
+			return err
+		}
+		b.removeMember(rm, targetID, reason, action == "ban")
+
+	case "unban":
+		if err := rm.Unban(hostID, targetID); err != nil {
+			return err
+		}
+
+	case "transfer_host":
+		if err := rm.TransferHost(hostID, targetID); err != nil {
+			return err
+		}
+
+	case "mute", "unmute":
+		muted := action == "mute"
+		if err := rm.Mute(hostID, targetID, muted); err != nil {
+			return err
+		}
+		b.sendToPlayers([]string{targetID}, map[string]interface{}{
+			"type":  "muted",
+			"muted": muted,
+		})
+
+	case "lock", "unlock":
+		locked := action == "lock"
+		if err := rm.SetLocked(hostID, locked); err != nil {
+			return err
+		}
+		b.broadcastToRoom(rm.ID, map[string]interface{}{
+			"type":   "room_locked",
+			"locked": locked,
+		})
+
+	default:
+		return errUnknownAction
+	}
+
+	log.Printf("🛡️  Room %s: host %s %s %s", rm.ID, hostID, action, targetID)
+	return nil
+}
+
+// removeMember disconnects a kicked or banned member from the room,
+// its game server and WebRTC
+func (b *Bridge) removeMember(rm *room.Room, targetID, reason string, banned bool) {
+	var name string
+	var kicked []*BrowserClient
+
+	b.mu.RLock()
+	for _, client := range b.clients {
+		if client.playerID == targetID && client.leaveRoom(rm.ID) {
+			name = client.name()
+			kicked = append(kicked, client)
+		}
+	}
+	gr := b.gameRooms[rm.ID]
+	b.mu.RUnlock()
+
+	for _, client := range kicked {
+		client.send(map[string]interface{}{
+			"type":   "kicked",
+			"roomId": rm.ID,
+			"reason": reason,
+			"banned": banned,
+		})
+	}
+
+	b.sendToGameServer(rm.ID, protocol.NewKickControl(b.controlToken(rm.ID), targetID, reason))
+	if gr != nil {
+		gr.WebRTC.RemovePeerConnection(targetID)
+		b.closeSession(gr, targetID, "kicked")
+	}
+
+	b.broadcastToRoom(rm.ID, map[string]interface{}{
+		"type":           "player_kicked",
+		"playerId":       targetID,
+		"playerName":     name,
+		"banned":         banned,
+		"playerCount":    rm.PlayerCount(),
+		"spectatorCount": rm.SpectatorCount(),
+	})
+}
+
+// broadcastHost tells the room who the host is
+func (b *Bridge) broadcastHost(rm *room.Room) {
+	b.broadcastToRoom(rm.ID, map[string]interface{}{
+		"type":   "host_changed",
+		"hostId": rm.Host(),
+	})
+}
+
+// handleLifecycle applies a ready-check or host phase command
+func (b *Bridge) handleLifecycle(client *BrowserClient, m *LifecycleMsg) {
+	rm := b.rooms.Get(client.room())
+	if rm == nil || client.isSpectator() {
+		return
+	}
+
+	var err error
+	switch m.Type {
+	case "ready":
+		if err = rm.SetReady(client.playerID, m.Ready); err == nil {
+			b.broadcastToRoom(rm.ID, map[string]interface{}{
+				"type":     "player_ready",
+				"playerId": client.playerID,
+				"ready":    m.Ready,
+				"allReady": rm.AllReady(),
+			})
+		}
+	case "start_game":
+		err = rm.StartCountdown(client.playerID)
+	case "cancel_start":
+		err = rm.CancelCountdown(client.playerID)
+	case "end_game":
+		err = rm.EndGame(client.playerID)
+	case "return_to_lobby":
+		err = rm.ReturnToLobby(client.playerID)
+	case "close_room":
+		err = rm.Close(client.playerID)
+	}
+
+	if err != nil {
+		client.send(map[string]interface{}{
+			"type":  "error",
+			"error": err.Error(),
+		})
+	}
+}
+
 // ================== HTTP API ==================
 
+type CreateRoomRequest struct {
+	Password   string   `json:"password,omitempty"`
+	InviteOnly bool     `json:"inviteOnly,omitempty"`
+	Name       string   `json:"name,omitempty"`
+	Mode       string   `json:"mode,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	Visibility string   `json:"visibility,omitempty"` // "public" (default) or "unlisted"
+	Teams      int      `json:"teams,omitempty"`      // Split players into this many teams
+	Settings   *RoomSettings `json:"settings,omitempty"`
+}
+
+// RoomSettings tune the room's game server; omitted fields keep the
+// server defaults
+type RoomSettings struct {
+	TickRate    int     `json:"tickRate,omitempty"`
+	MaxPlayers  int     `json:"maxPlayers,omitempty"`
+	WorldWidth  float32 `json:"worldWidth,omitempty"`
+	WorldHeight float32 `json:"worldHeight,omitempty"`
+	PlayerSpeed float32 `json:"playerSpeed,omitempty"`
+
+	VisionRadius float32 `json:"visionRadius,omitempty"` // Hide non-teammates beyond this
+}
+
 type CreateRoomResponse struct {
-	RoomID    string `json:"roomId"`
-	JoinLink  string `json:"joinLink"`
-	CreatedAt int64  `json:"createdAt"`
-	HostID    string `json:"hostId"`
+	RoomID          string `json:"roomId"`
+	JoinLink        string `json:"joinLink"`
+	CreatedAt       int64  `json:"createdAt"`
+	HostID          string `json:"hostId,omitempty"`
+	InviteToken     string `json:"inviteToken"`
+	InviteLink      string `json:"inviteLink"`
+	InviteExpiresAt int64  `json:"inviteExpiresAt"`
 }
 
 type RoomInfoResponse struct {
+	RoomID         string   `json:"roomId"`
+	PlayerCount    int      `json:"playerCount"`
+	MaxPlayers     int      `json:"maxPlayers"`
+	Players        []string `json:"players"`
+	SpectatorCount int      `json:"spectatorCount"`
+	Spectators     []string `json:"spectators"`
+	Name           string   `json:"name"`
+	Mode           string   `json:"mode"`
+	Tags           []string `json:"tags"`
+	Visibility     string   `json:"visibility"`
+	Phase          string   `json:"phase"`
+	HasPassword    bool     `json:"hasPassword"`
+	InviteOnly     bool     `json:"inviteOnly"`
+	Teams          int      `json:"teams"`
+	Settings       RoomSettings `json:"settings"`
+	Server         *ServerStatus `json:"server,omitempty"` // Nil until a game server is spawned
+	CreatedAt      int64    `json:"createdAt"`
+}
+
+type RoomListing struct {
 	RoomID      string   `json:"roomId"`
+	Name        string   `json:"name"`
+	Mode        string   `json:"mode"`
+	Tags        []string `json:"tags"`
 	PlayerCount int      `json:"playerCount"`
 	MaxPlayers  int      `json:"maxPlayers"`
-	Players     []string `json:"players"`
+	HasPassword bool     `json:"hasPassword"`
+	Phase       string   `json:"phase"`
+	JoinLink    string   `json:"joinLink"`
 	CreatedAt   int64    `json:"createdAt"`
 }
 
+type ListRoomsResponse struct {
+	Rooms      []RoomListing `json:"rooms"`
+	NextCursor string        `json:"nextCursor,omitempty"`
+}
+
 type ErrorResponse struct {
 	Error string `json:"error"`
 }
 
-func (b *Bridge) handleCreateRoom(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
+func (b *Bridge) handleRooms(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		b.handleListRooms(w, r)
+	case http.MethodPost:
+		b.handleCreateRoom(w, r)
+	default:
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		json.NewEncoder(w).Encode(ErrorResponse{Error: "method not allowed"})
+	}
+}
+
+// handleListRooms serves the room browser:
+// GET /rooms?mode=&tag=&notFull=true&hasPassword=false&sort=players|age&reverse=true&limit=20&cursor=
+func (b *Bridge) handleListRooms(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	query := room.ListQuery{
+		Mode:    params.Get("mode"),
+		Tag:     params.Get("tag"),
+		Sort:    params.Get("sort"),
+		Cursor:  params.Get("cursor"),
+		NotFull: params.Get("notFull") == "true",
+		Reverse: params.Get("reverse") == "true",
+	}
+	if v := params.Get("hasPassword"); v != "" {
+		hasPassword := v == "true"
+		query.HasPassword = &hasPassword
+	}
+	if v := params.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "invalid limit"})
+			return
+		}
+		query.Limit = limit
+	}
+
+	page, err := b.rooms.List(query)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	rm := b.rooms.Create()
-	host := r.URL.Query().Get("host")
-	if host == "" {
-		host = uuid.New().String()[:8]
+	resp := ListRoomsResponse{
+		Rooms:      make([]RoomListing, 0, len(page.Rooms)),
+		NextCursor: page.NextCursor,
+	}
+	for _, l := range page.Rooms {
+		resp.Rooms = append(resp.Rooms, RoomListing{
+			RoomID:      l.ID,
+			Name:        l.Name,
+			Mode:        l.Mode,
+			Tags:        l.Tags,
+			PlayerCount: l.Players,
+			MaxPlayers:  l.MaxPlayers,
+			HasPassword: l.HasPassword,
+			Phase:       string(l.Phase),
+			JoinLink:    roomLink(r, l.ID),
+			CreatedAt:   l.CreatedAt.Unix(),
+		})
 	}
 
-	rm.Join(host, "Host")
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	json.NewEncoder(w).Encode(resp)
+}
 
-	// Build join link from request host
+// roomLink builds a room's join link from the request host
+func roomLink(r *http.Request, roomID string) string {
 	scheme := "http"
 	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
 		scheme = "https"
 	}
-	joinLink := fmt.Sprintf("%s://%s/room/%s", scheme, r.Host, rm.ID)
+	return fmt.Sprintf("%s://%s/room/%s", scheme, r.Host, roomID)
+}
+
+func (b *Bridge) handleCreateRoom(w http.ResponseWriter, r *http.Request) {
+	// A full node hands the room to another
+	if b.redirectCreate(w, r) {
+		return
+	}
+
+	// Body is optional; an empty one creates a public room
+	var req CreateRoomRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "invalid request body"})
+			return
+		}
+	}
+
+	visibility := room.VisibilityPublic
+	if req.Visibility != "" {
+		v, ok := room.ParseVisibility(req.Visibility)
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "invalid visibility"})
+			return
+		}
+		visibility = v
+	}
+	if req.Teams < 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "invalid team count"})
+		return
+	}
+	var settings room.Settings
+	if req.Settings != nil {
+		settings = room.Settings(*req.Settings)
+		if err := settings.Validate(b.rooms.Config().MaxPlayers); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+	}
+
+	rm, invite := b.rooms.CreateWithOptions(room.Options{
+		Password:   req.Password,
+		InviteOnly: req.InviteOnly,
+		Name:       req.Name,
+		Mode:       req.Mode,
+		Tags:       req.Tags,
+		Visibility: visibility,
+		Teams:      req.Teams,
+		Settings:   settings,
+	})
+	b.placeRoom(rm.ID)
+
+	// An explicit host ID reserves the host slot; otherwise the first
+	// browser to join becomes host
+	host := r.URL.Query().Get("host")
+	if host != "" {
+		rm.JoinWith(host, "Host", room.Credentials{Invite: invite})
+	}
+
+	joinLink := roomLink(r, rm.ID)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 	json.NewEncoder(w).Encode(CreateRoomResponse{
-		RoomID:    rm.ID,
-		JoinLink:  joinLink,
-		CreatedAt: rm.CreatedAt.Unix(),
-		HostID:    host,
+		RoomID:          rm.ID,
+		JoinLink:        joinLink,
+		CreatedAt:       rm.CreatedAt.Unix(),
+		HostID:          host,
+		InviteToken:     invite,
+		InviteLink:      joinLink + "?invite=" + url.QueryEscape(invite),
+		InviteExpiresAt: time.Now().Add(room.DefaultInviteTTL).Unix(),
 	})
 
-	log.Printf("🏠 Room created: %s (host: %s)", rm.ID, host)
+	log.Printf("🏠 Room created: %s (host: %s, private: %v)", rm.ID, host, rm.IsPrivate())
 }
 
 func (b *Bridge) handleGetRoom(w http.ResponseWriter, r *http.Request) {
@@ -339,15 +796,37 @@ func (b *Bridge) handleGetRoom(w http.ResponseWriter, r *http.Request) {
 	}
 
 	playerIDs := rm.PlayerIDs()
+	spectatorIDs := rm.SpectatorIDs()
+
+	var server *ServerStatus
+	b.mu.RLock()
+	gr := b.gameRooms[roomID]
+	b.mu.RUnlock()
+	if gr != nil {
+		status := gr.Status()
+		server = &status
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 	json.NewEncoder(w).Encode(RoomInfoResponse{
-		RoomID:      rm.ID,
-		PlayerCount: len(playerIDs),
-		MaxPlayers:  rm.MaxPlayer,
-		Players:     playerIDs,
-		CreatedAt:   rm.CreatedAt.Unix(),
+		RoomID:         rm.ID,
+		PlayerCount:    len(playerIDs),
+		MaxPlayers:     rm.MaxPlayer,
+		Players:        playerIDs,
+		SpectatorCount: len(spectatorIDs),
+		Spectators:     spectatorIDs,
+		Name:           rm.Name,
+		Mode:           rm.Mode,
+		Tags:           rm.Tags,
+		Visibility:     string(rm.Visibility),
+		Phase:          string(rm.GetPhase()),
+		HasPassword:    rm.HasPassword,
+		InviteOnly:     rm.InviteOnly,
+		Teams:          rm.Teams,
+		Settings:       RoomSettings(rm.GetSettings()),
+		Server:         server,
+		CreatedAt:      rm.CreatedAt.Unix(),
 	})
 }
 
@@ -360,6 +839,9 @@ func (b *Bridge) handleDeleteRoom(w http.ResponseWriter, r *http.Request) {
 	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/rooms/"), "/")
 	roomID := parts[0]
 
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
 	rm := b.rooms.Get(roomID)
 	if rm == nil {
 		w.WriteHeader(http.StatusNotFound)
@@ -367,241 +849,595 @@ func (b *Bridge) handleDeleteRoom(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Only the host may delete the room, with the apiToken from room_joined
+	claims, err := b.sessions.Verify(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+	if err != nil || claims.RoomID != roomID {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "invalid token"})
+		return
+	}
+	if rm.Host() != claims.PlayerID {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: room.ErrNotHost.Error()})
+		return
+	}
+
 	// Stop game server
 	b.stopGameRoom(roomID)
-	b.rooms.Delete(roomID)
+	b.deleteRoom(roomID)
 
+	json.NewEncoder(w).Encode(map[string]string{"status": "deleted"})
+}
+
+// ModerateRequest is the body of POST /rooms/{id}/{action}
+type ModerateRequest struct {
+	PlayerID string `json:"playerId"`
+	Reason   string `json:"reason"`
+}
+
+// handleModerate serves POST /rooms/{id}/{kick|ban|unban|transfer|mute|unmute|lock|unlock}.
+// The caller proves who they are with the apiToken from room_joined.
+func (b *Bridge) handleModerate(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Access-Control-Allow-Origin", "*")
-	json.NewEncoder(w).Encode(map[string]string{"status": "deleted"})
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/rooms/"), "/")
+	if len(parts) != 2 {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "not found"})
+		return
+	}
+	roomID, action := parts[0], parts[1]
+	if action == "transfer" {
+		action = "transfer_host"
+	}
+
+	rm := b.rooms.Get(roomID)
+	if rm == nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "room not found"})
+		return
+	}
+
+	claims, err := b.sessions.Verify(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+	if err != nil || claims.RoomID != roomID {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "invalid token"})
+		return
+	}
+
+	var req ModerateRequest
+	if r.Body != nil {
+		json.NewDecoder(r.Body).Decode(&req)
+	}
+
+	if err := b.moderate(rm, claims.PlayerID, action, req.PlayerID, req.Reason); err != nil {
+		switch err {
+		case room.ErrNotHost:
+			w.WriteHeader(http.StatusForbidden)
+		case room.ErrNotInRoom, errUnknownAction:
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
 }
 
 // ================== WebSocket ==================
 
 func (b *Bridge) handleWS(w http.ResponseWriter, r *http.Request) {
+	version, format, negotiateErr := negotiate(r)
+
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		return
 	}
 	defer conn.Close()
 
-	client := &BrowserClient{
-		ws:       conn,
-		playerID: uuid.New().String()[:8],
-		name:     "Player",
-		roomID:   "",
+	if negotiateErr != nil {
+		conn.WriteJSON(newErrorMsg(ErrCodeUnsupported, "", negotiateErr.Error()))
+		conn.WriteMessage(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseProtocolError, "unsupported protocol"))
+		return
 	}
 
+	client := newBrowserClient(conn, b.identify(r.URL.Query().Get("session")))
+	client.version = version
+	client.format = format
+	defer client.close(websocket.CloseNormalClosure, "")
+
 	b.mu.Lock()
 	b.clients[conn] = client
 	b.mu.Unlock()
 
-	log.Printf("📱 Browser connected: %s", client.playerID)
+	log.Printf("📱 Browser connected: %s (v%d, %s)", client.playerID, version, format)
 
-	conn.WriteJSON(map[string]interface{}{
-		"type": "welcome",
-		"id":   client.playerID,
+	client.send(WelcomeMsg{
+		Type:    "welcome",
+		ID:      client.playerID,
+		Version: version,
+		Format:  format,
+		Session: b.sessions.MintRole(client.playerID, "", auth.RoleIdentity, identityTTL),
 	})
 
 	for {
-		_, msg, err := conn.ReadMessage()
+		frameType, frame, err := conn.ReadMessage()
 		if err != nil {
 			break
 		}
 
-		var data map[string]interface{}
-		if err := json.Unmarshal(msg, &data); err != nil {
+		if frameType == websocket.BinaryMessage {
+			b.handleBinaryMessage(client, frame)
 			continue
 		}
 
-		switch data["type"] {
-		case "input":
-			if client.roomID == "" {
-				continue
-			}
+		msg, errMsg := decodeClientMessage(frame)
+		if errMsg != nil {
+			client.send(errMsg)
+			continue
+		}
+		b.handleClientMessage(client, msg)
+	}
 
-			b.mu.RLock()
-			gr, exists := b.gameRooms[client.roomID]
-			b.mu.RUnlock()
+	// Cleanup
+	b.mu.Lock()
+	delete(b.clients, conn)
+	b.mu.Unlock()
 
-			if !exists {
-				continue
-			}
+	b.leaveRoom(client)
+	b.matchmaker.Cancel(client.playerID)
+	close(client.left)
 
-			dx, _ := data["dx"].(float64)
-			dy, _ := data["dy"].(float64)
-			ts := uint64(time.Now().UnixMilli())
+	log.Printf("📱 Browser disconnected: %s", client.playerID)
+}
 
-			input := protocol.NewPlayerInput(client.playerID, ts, ts, float32(dx), float32(dy), false, false, false)
-			if inputData, err := protocol.Encode(input); err == nil {
-				gr.UDPConn.Write(inputData)
-			}
+// identify returns the player ID a browser's identity token (from an
+// earlier welcome) proves, or a new one. A page that reconnects, e.g. to
+// the room it was matched into, keeps its ID; an older connection still
+// using it is closed first.
+func (b *Bridge) identify(token string) string {
+	claims, err := b.sessions.Verify(token)
+	if err != nil || claims.Role != auth.RoleIdentity || claims.PlayerID == "" {
+		return uuid.New().String()[:8]
+	}
 
-		case "join_room":
-			roomID, _ := data["roomId"].(string)
-			playerName, _ := data["name"].(string)
-			if playerName == "" {
-				playerName = "Player"
-			}
+	if old := b.clientByID(claims.PlayerID); old != nil {
+		old.close(websocket.ClosePolicyViolation, "connected elsewhere")
+		select {
+		case <-old.left:
+		case <-time.After(takeoverWait):
+		}
+		if b.clientByID(claims.PlayerID) != nil {
+			return uuid.New().String()[:8]
+		}
+	}
+	return claims.PlayerID
+}
 
-			rm, player, err := b.rooms.Join(roomID, client.playerID, playerName)
-			if err != nil {
-				conn.WriteJSON(map[string]interface{}{
-					"type":  "error",
-					"error": err.Error(),
-				})
-				continue
-			}
+// clientByID returns the connected browser with a player ID, if any
+func (b *Bridge) clientByID(playerID string) *BrowserClient {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, client := range b.clients {
+		if client.playerID == playerID {
+			return client
+		}
+	}
+	return nil
+}
 
-			client.roomID = roomID
-			client.name = playerName
+// handleClientMessage applies a decoded JSON message from a browser
+func (b *Bridge) handleClientMessage(client *BrowserClient, msg clientMessage) {
+	switch m := msg.(type) {
+	case *HelloMsg:
+		if m.Name != "" {
+			client.setName(m.Name)
+		}
 
-			// Spawn game server for this room
-			gr, err := b.spawnGameServer(roomID)
-			if err != nil {
-				conn.WriteJSON(map[string]interface{}{
-					"type":  "error",
-					"error": "failed to start game server",
-				})
-				continue
-			}
+	case *InputMsg:
+		b.handleInput(client, m)
 
-			// Send hello to game server
-			hello := protocol.NewClientHello(client.playerID, client.name, "1.0")
-			if helloData, err := protocol.Encode(hello); err == nil {
-				gr.UDPConn.Write(helloData)
-			}
+	case *JoinRoomMsg:
+		roomID := m.RoomID
+		playerName := m.Name
+		if playerName == "" {
+			playerName = client.name()
+		}
+		creds := room.Credentials{Password: m.Password, Invite: m.Invite}
 
-			conn.WriteJSON(map[string]interface{}{
-				"type":        "room_joined",
-				"roomId":      roomID,
-				"playerId":    client.playerID,
-				"isHost":      player.IsHost,
-				"playerCount": rm.PlayerCount(),
+		// Rooms on another node are joined there
+		if node, elsewhere := b.roomOwner(roomID); elsewhere {
+			b.sendReconnect(client, node, roomID, creds.Invite)
+			return
+		}
+
+		if m.Spectate {
+			b.handleSpectate(client, roomID, playerName, creds)
+			return
+		}
+
+		rm, player, err := b.rooms.Join(roomID, client.playerID, playerName, creds)
+		if err != nil {
+			client.send(map[string]interface{}{
+				"type":  "error",
+				"error": err.Error(),
 			})
+			return
+		}
 
-			b.broadcastToRoom(roomID, map[string]interface{}{
-				"type":        "player_joined",
-				"playerId":    client.playerID,
-				"playerName":  playerName,
-				"playerCount": rm.PlayerCount(),
+		client.enterRoom(roomID, playerName, false)
+
+		// Spawn game server for this room
+		gr, err := b.spawnGameServer(roomID)
+		if err != nil {
+			client.send(map[string]interface{}{
+				"type":  "error",
+				"error": "failed to start game server: " + err.Error(),
 			})
+			return
+		}
 
-			log.Printf("🚪 %s joined room %s (%d players)", client.playerID, roomID, rm.PlayerCount())
+		// Send hello to game server with a signed session token
+		b.sendHello(gr, client)
+		b.sendTeams(rm)
+
+		client.send(map[string]interface{}{
+			"type":        "room_joined",
+			"roomId":      roomID,
+			"playerId":    client.playerID,
+			"isHost":      player.IsHost,
+			"playerCount": rm.PlayerCount(),
+			"phase":       rm.GetPhase(),
+			"locked":      rm.IsLocked(),
+			"team":        player.Team,
+			"teams":       rm.Teams,
+			"apiToken":    b.sessions.Mint(client.playerID, roomID, apiTokenTTL),
+		})
+		b.sendChatHistory(client, rm)
 
-		case "leave_room":
-			if client.roomID != "" {
-				rm := b.rooms.Get(client.roomID)
-				if rm != nil {
-					rm.Leave(client.playerID)
-					b.broadcastToRoom(client.roomID, map[string]interface{}{
-						"type":       "player_left",
-						"playerId":   client.playerID,
-						"playerName": client.name,
-					})
-				}
-				client.roomID = ""
-			}
+		b.broadcastToRoom(roomID, map[string]interface{}{
+			"type":        "player_joined",
+			"playerId":    client.playerID,
+			"playerName":  playerName,
+			"playerCount": rm.PlayerCount(),
+		})
 
-		// WebRTC Signaling
-		case "webrtc_offer":
-			if client.roomID == "" {
-				continue
-			}
-			b.mu.RLock()
-			gr, exists := b.gameRooms[client.roomID]
-			b.mu.RUnlock()
+		log.Printf("🚪 %s joined room %s (%d players)", client.playerID, roomID, rm.PlayerCount())
 
-			if !exists {
-				continue
-			}
+	case *LifecycleMsg:
+		if client.room() == "" {
+			return
+		}
+		b.handleLifecycle(client, m)
 
-			sdp, _ := data["sdp"].(string)
-			answer, err := gr.WebRTC.HandleOffer(client.playerID, sdp)
-			if err != nil {
-				log.Printf("❌ WebRTC offer error: %v", err)
-				conn.WriteJSON(map[string]interface{}{
-					"type":  "webrtc_error",
-					"error": err.Error(),
-				})
-				continue
+	case *ModerateMsg:
+		rm := b.rooms.Get(client.room())
+		if rm == nil {
+			return
+		}
+		action := m.Type
+		if action == "lock_room" {
+			action = "unlock"
+			if m.Locked {
+				action = "lock"
 			}
-
-			// Send answer back to client
-			conn.WriteJSON(map[string]interface{}{
-				"type":     "webrtc_answer",
-				"roomId":   client.roomID,
-				"playerId": client.playerID,
-				"sdp":      answer.SDP,
+		}
+		if err := b.moderate(rm, client.playerID, action, m.PlayerID, m.Reason); err != nil {
+			client.send(map[string]interface{}{
+				"type":  "error",
+				"error": err.Error(),
 			})
-			
-			log.Printf("✅ [%s] Sent WebRTC answer, senders: %d", client.playerID, gr.WebRTC.GetSenders(client.playerID))
+		}
 
-		case "webrtc_answer":
-			if client.roomID == "" {
-				continue
-			}
-			b.mu.RLock()
-			gr, exists := b.gameRooms[client.roomID]
-			b.mu.RUnlock()
+	case *TeamMsg:
+		if client.room() == "" {
+			return
+		}
+		b.handleTeamCommand(client, m)
 
-			if !exists {
-				continue
-			}
+	case *ChatSendMsg:
+		b.sendChat(client, m)
 
-			sdp, _ := data["sdp"].(string)
-			if err := gr.WebRTC.HandleAnswer(client.playerID, sdp); err != nil {
+	case *QueueMatchMsg:
+		b.queueMatch(client, m)
+
+	case *FollowMsg:
+		if client.room() == "" || !client.isSpectator() {
+			return
+		}
+		rm := b.rooms.Get(client.room())
+		if rm == nil {
+			return
+		}
+		if err := rm.Follow(client.playerID, m.PlayerID); err != nil {
+			client.send(map[string]interface{}{
+				"type":  "error",
+				"error": err.Error(),
+			})
+			return
+		}
+		client.follow(m.PlayerID)
+		if gr := b.clientGameRoom(client); gr != nil {
+			// The game server switches the spectator's feed to the player's view
+			b.sendSpectatorHello(gr, client)
+		}
+		client.send(map[string]interface{}{
+			"type":     "following",
+			"playerId": m.PlayerID,
+		})
+
+	// WebRTC Signaling
+	case *WebRTCSDPMsg:
+		gr := b.clientGameRoom(client)
+		if gr == nil {
+			return
+		}
+
+		if m.Type == "webrtc_answer" {
+			if err := gr.WebRTC.HandleAnswer(client.playerID, m.SDP); err != nil {
 				log.Printf("❌ WebRTC answer error: %v", err)
 			}
+			return
+		}
 
-		case "webrtc_ice":
-			if client.roomID == "" {
-				continue
-			}
-			b.mu.RLock()
-			gr, exists := b.gameRooms[client.roomID]
-			b.mu.RUnlock()
+		answer, err := gr.WebRTC.HandleOffer(client.playerID, m.SDP)
+		if err != nil {
+			log.Printf("❌ WebRTC offer error: %v", err)
+			client.send(map[string]interface{}{
+				"type":  "webrtc_error",
+				"error": err.Error(),
+			})
+			return
+		}
 
-			if !exists {
-				continue
-			}
+		// Send answer back to client
+		client.send(map[string]interface{}{
+			"type":     "webrtc_answer",
+			"roomId":   client.room(),
+			"playerId": client.playerID,
+			"sdp":      answer.SDP,
+		})
 
-			candidate, _ := data["candidate"].(json.RawMessage)
-			if err := gr.WebRTC.HandleICECandidate(client.playerID, candidate); err != nil {
-				log.Printf("❌ WebRTC ICE error: %v", err)
+		log.Printf("✅ [%s] Sent WebRTC answer, senders: %d", client.playerID, gr.WebRTC.GetSenders(client.playerID))
+
+	case *WebRTCICEMsg:
+		gr := b.clientGameRoom(client)
+		if gr == nil {
+			return
+		}
+		if err := gr.WebRTC.HandleICECandidate(client.playerID, m.Candidate); err != nil {
+			log.Printf("❌ WebRTC ICE error: %v", err)
+		}
+
+	case *Envelope:
+		switch m.Type {
+		case "create_invite":
+			rm := b.rooms.Get(client.room())
+			if rm == nil {
+				return
+			}
+			invite, err := rm.CreateInvite(client.playerID, room.DefaultInviteTTL)
+			if err != nil {
+				client.send(map[string]interface{}{
+					"type":  "error",
+					"error": err.Error(),
+				})
+				return
 			}
+			client.send(map[string]interface{}{
+				"type":      "invite_created",
+				"invite":    invite,
+				"expiresAt": time.Now().Add(room.DefaultInviteTTL).UnixMilli(),
+			})
+
+		case "cancel_match":
+			b.matchmaker.Cancel(client.playerID)
+
+		case "leave_room":
+			b.leaveRoom(client)
 		}
 	}
+}
 
-	// Cleanup
-	b.mu.Lock()
-	delete(b.clients, conn)
-	roomID := client.roomID
-	b.mu.Unlock()
+// handleBinaryMessage applies a gamepb.Message from a protobuf-mode
+// browser. Inputs go to the game server as the browser's player; chat goes
+// to the room.
+func (b *Bridge) handleBinaryMessage(client *BrowserClient, frame []byte) {
+	if client.format != FormatProtobuf {
+		client.send(newErrorMsg(ErrCodeUnsupported, "", "binary frames need /ws?format=protobuf"))
+		return
+	}
+	msg, err := protocol.Decode(frame)
+	if err != nil {
+		client.send(newErrorMsg(ErrCodeMalformed, "", "invalid protobuf: "+err.Error()))
+		return
+	}
 
-	if roomID != "" {
-		rm := b.rooms.Get(roomID)
-		if rm != nil {
-			rm.Leave(client.playerID)
-			b.broadcastToRoom(roomID, map[string]interface{}{
-				"type":       "player_left",
-				"playerId":   client.playerID,
-				"playerName": client.name,
-			})
+	switch payload := msg.Payload.(type) {
+	case *gamepb.Message_PlayerInput:
+		if payload.PlayerInput == nil {
+			client.send(newErrorMsg(ErrCodeInvalid, "PlayerInput", "empty input"))
+			return
+		}
+		payload.PlayerInput.PlayerId = client.playerID
+		b.forwardInput(client, msg)
+
+	case *gamepb.Message_Chat:
+		if payload.Chat == nil || payload.Chat.Text == "" {
+			client.send(newErrorMsg(ErrCodeInvalid, "Chat", "missing field: text"))
+			return
 		}
+		b.sendChat(client, &ChatSendMsg{Text: payload.Chat.Text, To: payload.Chat.ToId})
+
+	default:
+		name := protocol.MessageTypeName(msg)
+		client.send(newErrorMsg(ErrCodeUnsupported, name, fmt.Sprintf("%s can't be sent by browsers", name)))
 	}
+}
 
-	log.Printf("📱 Browser disconnected: %s", client.playerID)
+// handleInput checks a browser's input sequence and passes the input on.
+// Older browsers don't number their inputs, so the bridge does. The ack
+// comes once the game server has applied it (see ackInput).
+func (b *Bridge) handleInput(client *BrowserClient, m *InputMsg) {
+	numbered := m.Seq != 0
+	switch {
+	case !numbered && client.version >= 3:
+		client.send(newErrorMsg(ErrCodeInvalid, m.Type, "missing field: seq"))
+		return
+	case !numbered:
+		m.Seq = client.lastInput + 1
+	case m.Seq <= client.lastInput:
+		client.send(newErrorMsg(ErrCodeInvalid, m.Type, fmt.Sprintf("stale seq %d (last was %d)", m.Seq, client.lastInput)))
+		return
+	}
+	if m.TS == 0 {
+		m.TS = uint64(time.Now().UnixMilli())
+	}
+
+	msg := protocol.NewPlayerInput(client.playerID, m.Seq, m.TS, float32(m.DX), float32(m.DY), m.Jump, m.Action1, m.Action2)
+	if !b.forwardInput(client, msg) {
+		return
+	}
+	client.lastInput = m.Seq
+}
+
+// forwardInput passes a player's input to their room's game server.
+// Reports whether there was a server to pass it to.
+func (b *Bridge) forwardInput(client *BrowserClient, msg *gamepb.Message) bool {
+	if client.isSpectator() {
+		return false
+	}
+	gr := b.clientGameRoom(client)
+	if gr == nil {
+		return false
+	}
+	s := gr.session(client.playerID)
+	if s == nil {
+		return false
+	}
+	gr.sendAs(s, msg)
+	return true
+}
+
+// clientGameRoom returns the game room of the client's room, if running
+func (b *Bridge) clientGameRoom(client *BrowserClient) *GameRoom {
+	roomID := client.room()
+	if roomID == "" {
+		return nil
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.gameRooms[roomID]
+}
+
+// handleSpectate adds a browser client to a room as a spectator.
+// Spectators join the game server's spectator feed, which lags behind the
+// players by spectatorDelay; they can't send inputs.
+func (b *Bridge) handleSpectate(client *BrowserClient, roomID, name string, creds room.Credentials) {
+	rm, _, err := b.rooms.Spectate(roomID, client.playerID, name, creds)
+	if err != nil {
+		client.send(map[string]interface{}{
+			"type":  "error",
+			"error": err.Error(),
+		})
+		return
+	}
+
+	client.enterRoom(roomID, name, true)
+
+	// Make sure there's a game server producing state to watch
+	gr, err := b.spawnGameServer(roomID)
+	if err != nil {
+		client.send(map[string]interface{}{
+			"type":  "error",
+			"error": "failed to start game server: " + err.Error(),
+		})
+		return
+	}
+
+	client.send(map[string]interface{}{
+		"type":           "room_joined",
+		"roomId":         roomID,
+		"playerId":       client.playerID,
+		"spectator":      true,
+		"phase":          rm.GetPhase(),
+		"playerCount":    rm.PlayerCount(),
+		"spectatorCount": rm.SpectatorCount(),
+	})
+	b.sendSpectatorHello(gr, client)
+	b.sendChatHistory(client, rm)
+
+	b.broadcastToRoom(roomID, map[string]interface{}{
+		"type":           "spectator_joined",
+		"playerId":       client.playerID,
+		"playerName":     name,
+		"spectatorCount": rm.SpectatorCount(),
+	})
+
+	log.Printf("👀 %s spectating room %s (%d spectators)", client.playerID, roomID, rm.SpectatorCount())
+}
+
+// leaveRoom removes a client from its room and tells the others
+func (b *Bridge) leaveRoom(client *BrowserClient) {
+	roomID, spectator := client.room(), client.isSpectator()
+	if !client.leaveRoom(roomID) {
+		return // Not in a room, or kicked meanwhile
+	}
+	rm := b.rooms.Get(roomID)
+	if rm == nil {
+		return
+	}
+	rm.Leave(client.playerID)
+	b.mu.RLock()
+	gr := b.gameRooms[roomID]
+	b.mu.RUnlock()
+	if gr != nil {
+		b.closeSession(gr, client.playerID, "left")
+	}
+
+	msgType := "player_left"
+	if spectator {
+		msgType = "spectator_left"
+	}
+	b.broadcastToRoom(roomID, map[string]interface{}{
+		"type":           msgType,
+		"playerId":       client.playerID,
+		"playerName":     client.name(),
+		"playerCount":    rm.PlayerCount(),
+		"spectatorCount": rm.SpectatorCount(),
+	})
 }
 
 func (b *Bridge) handleStatus(w http.ResponseWriter, r *http.Request) {
 	b.mu.RLock()
 	clientCount := len(b.clients)
-	roomCount := len(b.gameRooms)
+	startingCount := len(b.spawning)
+	gameRooms := make([]*GameRoom, 0, len(b.gameRooms))
+	for _, gr := range b.gameRooms {
+		gameRooms = append(gameRooms, gr)
+	}
 	b.mu.RUnlock()
 
+	// Per-room game server process status
+	servers := make(map[string]ServerStatus, len(gameRooms))
+	for _, gr := range gameRooms {
+		servers[gr.ID] = gr.Status()
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	fmt.Fprintf(w, `{"browser_clients":%d,"game_rooms":%d,"rooms":%d}`, clientCount, roomCount, b.rooms.Count())
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"node":            b.node.ID(),
+		"browser_clients": clientCount,
+		"game_rooms":      len(gameRooms),
+		"starting":        startingCount,
+		"ports_leased":    b.ports.Leases(),
+		"rooms":           b.rooms.Count(),
+		"servers":         servers,
+	})
 }
 
 // handleWebRTCTracks handles incoming WebRTC tracks and renegotiation
@@ -630,36 +1466,30 @@ func (b *Bridge) handleWebRTCTracks(gr *GameRoom) {
 			
 			// Find the WebSocket connection for this player
 			b.mu.RLock()
-			var targetConn *websocket.Conn
 			var foundClient *BrowserClient
-			for ws, client := range b.clients {
-				if client.playerID == renegotiate.PlayerID && client.roomID == gr.ID {
-					targetConn = ws
+			for _, client := range b.clients {
+				if client.playerID == renegotiate.PlayerID && client.room() == gr.ID {
 					foundClient = client
 					break
 				}
 			}
 			b.mu.RUnlock()
 			
-			if targetConn == nil {
+			if foundClient == nil {
 				log.Printf("⚠️ [RENEGOTIATE] No connection found for player %s", renegotiate.PlayerID)
 				continue
 			}
 			
-			log.Printf("🔄 [RENEGOTIATE] Found connection for %s (name: %s)", renegotiate.PlayerID, foundClient.name)
+			log.Printf("🔄 [RENEGOTIATE] Found connection for %s (name: %s)", renegotiate.PlayerID, foundClient.name())
 			
 			// Send offer to client
-			err = targetConn.WriteJSON(map[string]interface{}{
+			foundClient.send(map[string]interface{}{
 				"type":     "webrtc_offer",
 				"roomId":   gr.ID,
 				"playerId": renegotiate.PlayerID,
 				"sdp":      offer.SDP,
 			})
-			if err != nil {
-				log.Printf("❌ [RENEGOTIATE] Failed to send offer: %v", err)
-			} else {
-				log.Printf("📤 [RENEGOTIATE] Sent offer to %s", renegotiate.PlayerID)
-			}
+			log.Printf("📤 [RENEGOTIATE] Sent offer to %s", renegotiate.PlayerID)
 		}
 	}
 }
@@ -670,28 +1500,36 @@ func (b *Bridge) handleLanding(w http.ResponseWriter, r *http.Request) {
 }
 
 func (b *Bridge) handleRoomPage(w http.ResponseWriter, r *http.Request) {
+	if b.redirectToOwner(w, r, strings.TrimPrefix(r.URL.Path, "/room/")) {
+		return
+	}
 	w.Header().Set("Content-Type", "text/html")
 	w.Write([]byte(roomPageHTML))
 }
 
 func main() {
-	bridge := NewBridge()
+	engine := flag.String("engine", "process", "Where room engines run: process (a game server per room) or embedded (in the bridge)")
+	flag.Parse()
+
+	bridge := NewBridge(*engine)
 
 	fs := http.FileServer(http.Dir("./cmd/webbridge/public"))
 	http.Handle("/static/", http.StripPrefix("/static/", fs))
-	http.HandleFunc("/rooms", bridge.handleCreateRoom)
+	http.HandleFunc("/rooms", bridge.handleRooms)
 	http.HandleFunc("/rooms/", bridge.handleRoomRoutes)
+	http.HandleFunc("/match/queue", bridge.handleMatchQueue)
+	http.HandleFunc("/match/status", bridge.handleMatchStatus)
 	http.HandleFunc("/ws", bridge.handleWS)
+	http.HandleFunc("/ws/schema", bridge.handleSchema)
 	http.HandleFunc("/status", bridge.handleStatus)
 	http.HandleFunc("/", bridge.handleLanding)
 	http.HandleFunc("/room/", bridge.handleRoomPage)
-
-	// Get port from environment (Render sets PORT)
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8081"
+	if bridge.directoryHandler != nil {
+		http.Handle("/cluster/", bridge.directoryHandler)
 	}
 
+	port := listenPort()
+
 	// Check for HTTPS certs (local dev)
 	certFile := "certs/localhost+2.pem"
 	keyFile := "certs/localhost+2-key.pem"
@@ -701,13 +1539,21 @@ func main() {
 	
 	// Check if we're behind a proxy (Render provides HTTPS)
 	_, isRender := os.LookupEnv("RENDER")
-	
+
+	// Shut down cleanly on Ctrl+C / SIGTERM (Render sends SIGTERM on deploy)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	srv := &http.Server{}
+	serverErr := make(chan error, 1)
+
 	if isRender || (certErr != nil || keyErr != nil) {
 		// HTTP only (Render handles HTTPS termination, or local dev without certs)
 		log.Printf("🌐 Web Bridge: http://localhost:%s", port)
 		log.Println("📡 Game servers will be spawned per-room starting at port 9100")
 		log.Println("🎥 WebRTC enabled (native Pion)")
-		log.Fatal(http.ListenAndServe(":"+port, nil))
+		srv.Addr = ":" + port
+		go func() { serverErr <- srv.ListenAndServe() }()
 	} else {
 		// HTTPS available (local dev with mkcert)
 		log.Println("🔐 HTTPS enabled")
@@ -715,19 +1561,41 @@ func main() {
 		log.Println("🌐 Also: https://192.168.0.39:8443")
 		log.Println("📡 Game servers will be spawned per-room starting at port 9100")
 		log.Println("🎥 WebRTC enabled (native Pion)")
-		log.Fatal(http.ListenAndServeTLS(":8443", certFile, keyFile, nil))
+		srv.Addr = ":8443"
+		go func() { serverErr <- srv.ListenAndServeTLS(certFile, keyFile) }()
 	}
+
+	select {
+	case err := <-serverErr:
+		log.Fatal(err)
+	case <-ctx.Done():
+	}
+
+	log.Println("🛑 Shutting down...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	// Stop accepting requests first; hijacked websockets aren't waited on
+	srv.Shutdown(shutdownCtx)
+	bridge.Shutdown()
 }
 
 func (b *Bridge) handleRoomRoutes(w http.ResponseWriter, r *http.Request) {
+	roomID := strings.Split(strings.TrimPrefix(r.URL.Path, "/rooms/"), "/")[0]
+	if r.Method != http.MethodOptions && b.redirectToOwner(w, r, roomID) {
+		return
+	}
+
 	switch r.Method {
 	case http.MethodGet:
 		b.handleGetRoom(w, r)
 	case http.MethodDelete:
 		b.handleDeleteRoom(w, r)
+	case http.MethodPost:
+		b.handleModerate(w, r)
 	case http.MethodOptions:
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
 		w.WriteHeader(http.StatusOK)
 	default:
 		w.WriteHeader(http.StatusMethodNotAllowed)
@@ -774,21 +1642,126 @@ var landingPageHTML = `<!DOCTYPE html>
         .btn:hover { transform: translateY(-2px); box-shadow: 0 8px 24px rgba(0, 212, 255, 0.3); }
         .btn:active { transform: translateY(0); }
         .footer { margin-top: 3rem; color: #555; font-size: 0.9rem; }
+        .options { margin-bottom: 1.5rem; color: #aaa; display: flex; gap: 1rem; justify-content: center; align-items: center; }
+        .options input[type=password], .options input[type=text], .options select { padding: 8px 12px; border-radius: 6px; border: 1px solid #333; background: #111; color: #fff; }
+        #room-list { margin-top: 2rem; min-width: 420px; text-align: left; }
+        #room-list .room { display: flex; justify-content: space-between; padding: 10px 14px; border-bottom: 1px solid #222; cursor: pointer; }
+        #room-list .room:hover { background: #16161f; }
+        #room-list .meta { color: #777; font-size: 0.9rem; }
     </style>
 </head>
 <body>
     <div class="container">
         <h1>🎮 GameServer</h1>
         <p>Create a room and invite your friends</p>
+        <div class="options">
+            <input type="text" id="room-name" placeholder="Room name">
+            <input type="text" id="room-mode" placeholder="Mode (e.g. ffa)">
+            <input type="password" id="room-password" placeholder="Password (optional)">
+            <label><input type="checkbox" id="invite-only"> Invite only</label>
+            <select id="room-teams">
+                <option value="0">Free for all</option>
+                <option value="2">2 teams</option>
+                <option value="3">3 teams</option>
+                <option value="4">4 teams</option>
+            </select>
+        </div>
         <button class="btn" onclick="createRoom()">Create Room</button>
+        <button class="btn" id="quick-match" onclick="quickMatch()">Quick Match</button>
+        <div id="room-list"></div>
+        <div class="options">
+            <label><input type="checkbox" id="filter-open" onchange="loadRooms()"> Not full</label>
+            <label><input type="checkbox" id="filter-nopass" onchange="loadRooms()"> No password</label>
+            <select id="sort" onchange="loadRooms()">
+                <option value="players">Most players</option>
+                <option value="age">Newest</option>
+            </select>
+            <button class="btn" id="more-rooms" style="display:none; padding: 8px 16px; font-size: 0.9rem" onclick="loadRooms(nextCursor)">More</button>
+        </div>
         <div class="footer"><p>Multiplayer game server • Voice & Video enabled</p></div>
     </div>
     <script>
         async function createRoom() {
-            const res = await fetch('/rooms', { method: 'POST' });
+            const name = document.getElementById('room-name').value;
+            const mode = document.getElementById('room-mode').value;
+            const password = document.getElementById('room-password').value;
+            const inviteOnly = document.getElementById('invite-only').checked;
+            const teams = parseInt(document.getElementById('room-teams').value, 10);
+            const res = await fetch('/rooms', {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify({ name, mode, password, inviteOnly, teams })
+            });
+            const data = await res.json();
+            // Private rooms: the creator gets in with the invite
+            window.location.href = (password || inviteOnly) ? data.inviteLink : data.joinLink;
+        }
+
+        // Matchmaking: queue with the mode field over the WebSocket. The
+        // welcome's session goes with us to the room so we keep the
+        // player ID the match reserved a seat for.
+        let matchWs = null;
+        function quickMatch() {
+            const btn = document.getElementById('quick-match');
+            if (matchWs) {
+                matchWs.send(JSON.stringify({ type: 'cancel_match' }));
+                return;
+            }
+            const mode = document.getElementById('room-mode').value || 'ffa';
+            const proto = location.protocol === 'https:' ? 'wss:' : 'ws:';
+            const session = sessionStorage.getItem('session') || '';
+            matchWs = new WebSocket(proto + '//' + location.host + '/ws?v=3&session=' + encodeURIComponent(session));
+            matchWs.onmessage = (event) => {
+                const data = JSON.parse(event.data);
+                switch (data.type) {
+                    case 'welcome':
+                        sessionStorage.setItem('session', data.session);
+                        matchWs.send(JSON.stringify({ type: 'queue_match', mode }));
+                        break;
+                    case 'match_queued':
+                        btn.textContent = 'Searching... (cancel)';
+                        break;
+                    case 'match_found':
+                        window.location.href = '/room/' + data.roomId;
+                        break;
+                    case 'match_failed':
+                    case 'match_cancelled':
+                    case 'error':
+                        matchWs.close();
+                        break;
+                }
+            };
+            matchWs.onclose = () => { matchWs = null; btn.textContent = 'Quick Match'; };
+        }
+
+        // Room browser
+        let nextCursor = '';
+        async function loadRooms(cursor) {
+            const params = new URLSearchParams({ sort: document.getElementById('sort').value });
+            if (document.getElementById('filter-open').checked) params.set('notFull', 'true');
+            if (document.getElementById('filter-nopass').checked) params.set('hasPassword', 'false');
+            if (cursor) params.set('cursor', cursor);
+
+            const res = await fetch('/rooms?' + params);
             const data = await res.json();
-            window.location.href = data.joinLink;
+            const list = document.getElementById('room-list');
+            if (!cursor) list.innerHTML = '';
+            for (const room of data.rooms) {
+                const row = document.createElement('div');
+                row.className = 'room';
+                const name = document.createElement('span');
+                name.textContent = (room.hasPassword ? '🔒 ' : '') + (room.name || room.roomId.slice(0, 8));
+                const meta = document.createElement('span');
+                meta.className = 'meta';
+                meta.textContent = (room.mode ? room.mode + ' • ' : '') + room.playerCount + '/' + room.maxPlayers + ' • ' + room.phase.replace('_', ' ');
+                row.append(name, meta);
+                row.onclick = () => window.location.href = room.joinLink;
+                list.appendChild(row);
+            }
+            nextCursor = data.nextCursor || '';
+            document.getElementById('more-rooms').style.display = nextCursor ? '' : 'none';
         }
+        loadRooms();
     </script>
 </body>
 </html>
@@ -823,6 +1796,24 @@ var roomPageHTML = `<!DOCTYPE html>
                 <span class="hud-label">Players:</span>
                 <span class="hud-value" id="player-count">0</span>
             </div>
+            <div class="hud-row">
+                <span class="hud-label">Phase:</span>
+                <span class="hud-value" id="phase">lobby</span>
+            </div>
+            <div id="lobby-controls">
+                <button id="btn-ready" onclick="toggleReady()">Ready</button>
+                <button id="btn-start" onclick="startGame()">Start Game</button>
+                <button id="btn-end" onclick="endGame()" style="display:none">End Game</button>
+                <button id="btn-lobby" onclick="returnToLobby()" style="display:none">Back to Lobby</button>
+                <button id="btn-lock" onclick="toggleLock()" style="display:none">🔓</button>
+                <button id="btn-invite" onclick="createInvite()" style="display:none">✉️</button>
+                <button id="btn-team" onclick="switchTeam()" style="display:none">Team</button>
+                <button id="btn-balance" onclick="balanceTeams()" style="display:none">⚖️</button>
+            </div>
+        </div>
+        <div id="chat">
+            <div id="chat-log"></div>
+            <input type="text" id="chat-input" maxlength="280" placeholder="Chat (/w id message to whisper)">
         </div>
         <div id="share">
             <span>Share link:</span>
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/LemmyAI/gameserver/internal/auth"
+	"github.com/LemmyAI/gameserver/internal/matchmaker"
+	"github.com/LemmyAI/gameserver/internal/room"
+)
+
+// maxMatchWait caps how long GET /match/status?wait= holds a request
+const maxMatchWait = 60 * time.Second
+
+var errPartyNotInRoom = errors.New("party members must be players in your room")
+
+type QueueRequest struct {
+	Mode      string   `json:"mode"`
+	PlayerID  string   `json:"playerId"`        // Set from the caller's token
+	Party     []string `json:"party,omitempty"` // Other players in the caller's room
+	Skill     int      `json:"skill"`
+	LatencyMs int      `json:"latencyMs,omitempty"`
+}
+
+type MatchStatusResponse struct {
+	State    string            `json:"state"`
+	TicketID string            `json:"ticketId,omitempty"`
+	WaitedMs int64             `json:"waitedMs"`
+	Match    *matchmaker.Match `json:"match,omitempty"`
+	JoinLink string            `json:"joinLink,omitempty"`
+	Error    string            `json:"error,omitempty"`
+}
+
+// createMatchRoom opens an unlisted room for a match and starts its game
+// server so players land in a running room. Only the matched players get
+// player slots.
+func (b *Bridge) createMatchRoom(mode string, players []string) (string, error) {
+	rm, _ := b.rooms.CreateWithOptions(room.Options{
+		Mode:       mode,
+		Visibility: room.VisibilityUnlisted,
+		Seats:      players,
+	})
+	b.placeRoom(rm.ID)
+	if _, err := b.spawnGameServer(rm.ID); err != nil {
+		b.deleteRoom(rm.ID)
+		return "", err
+	}
+	log.Printf("🎯 Match room %s (%s) for %v", rm.ID, mode, players)
+	return rm.ID, nil
+}
+
+// request turns a queue request into a matchmaker request
+func (q QueueRequest) request() matchmaker.Request {
+	return matchmaker.Request{
+		Mode:    q.Mode,
+		Players: append([]string{q.PlayerID}, q.Party...),
+		Skill:   q.Skill,
+		Latency: time.Duration(q.LatencyMs) * time.Millisecond,
+	}
+}
+
+// partyInRoom checks that a party leader brings only players from the
+// room they're in, so nobody can be queued (and seated) by a stranger
+func (b *Bridge) partyInRoom(roomID, leaderID string, party []string) error {
+	if len(party) == 0 {
+		return nil
+	}
+	rm := b.rooms.Get(roomID)
+	if rm == nil || !rm.IsPlayer(leaderID) {
+		return errPartyNotInRoom
+	}
+	for _, id := range party {
+		if !rm.IsPlayer(id) {
+			return errPartyNotInRoom
+		}
+	}
+	return nil
+}
+
+// matchCaller verifies the Authorization: Bearer token on a matchmaking
+// request: the session from a browser's welcome, or its room API token.
+// It answers CORS preflights (ok is false) since the header needs one.
+func (b *Bridge) matchCaller(w http.ResponseWriter, r *http.Request) (*auth.Claims, bool) {
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+		w.WriteHeader(http.StatusOK)
+		return nil, false
+	}
+	claims, err := b.sessions.Verify(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+	if err != nil || (claims.Role != auth.RoleIdentity && claims.Role != auth.RolePlayer) {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "session token required"})
+		return nil, false
+	}
+	return claims, true
+}
+
+// handleMatchQueue joins (POST) or leaves (DELETE) the queue as the
+// player the bearer token names
+func (b *Bridge) handleMatchQueue(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	claims, ok := b.matchCaller(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		var req QueueRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || (req.PlayerID != "" && req.PlayerID != claims.PlayerID) {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "mode required; playerId must be your own"})
+			return
+		}
+		req.PlayerID = claims.PlayerID
+		if err := b.partyInRoom(claims.RoomID, claims.PlayerID, req.Party); err != nil {
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+		ticket, err := b.matchmaker.Enqueue(req.request())
+		if err != nil {
+			w.WriteHeader(matchErrorStatus(err))
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(MatchStatusResponse{
+			State:    string(matchmaker.StateQueued),
+			TicketID: ticket.ID,
+		})
+
+	case http.MethodDelete:
+		if err := b.matchmaker.Cancel(claims.PlayerID); err != nil {
+			w.WriteHeader(matchErrorStatus(err))
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "method not allowed"})
+	}
+}
+
+// handleMatchStatus reports the bearer's matchmaking state. With
+// ?wait=30s it long-polls until the player is matched or the ticket fails.
+func (b *Bridge) handleMatchStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	claims, ok := b.matchCaller(w, r)
+	if !ok {
+		return
+	}
+	playerID := claims.PlayerID
+	status := b.matchmaker.Status(playerID)
+
+	if wait, err := time.ParseDuration(r.URL.Query().Get("wait")); err == nil && status.State == matchmaker.StateQueued {
+		if wait > maxMatchWait {
+			wait = maxMatchWait
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), wait)
+		status, _ = b.matchmaker.Wait(ctx, playerID)
+		cancel()
+	}
+
+	json.NewEncoder(w).Encode(b.matchStatusResponse(r, status))
+}
+
+func (b *Bridge) matchStatusResponse(r *http.Request, status matchmaker.Status) MatchStatusResponse {
+	resp := MatchStatusResponse{
+		State:    string(status.State),
+		WaitedMs: status.Waited.Milliseconds(),
+		Match:    status.Match,
+		Error:    status.Error,
+	}
+	if status.Ticket != nil {
+		resp.TicketID = status.Ticket.ID
+	}
+	if status.Match != nil {
+		resp.JoinLink = roomLink(r, status.Match.RoomID)
+	}
+	return resp
+}
+
+func matchErrorStatus(err error) int {
+	switch err {
+	case matchmaker.ErrAlreadyQueued:
+		return http.StatusConflict
+	case matchmaker.ErrNotQueued:
+		return http.StatusNotFound
+	case matchmaker.ErrClosed:
+		return http.StatusServiceUnavailable
+	case errPartyNotInRoom:
+		return http.StatusForbidden
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// queueMatch puts a browser (and its party) in the queue and tells every
+// party member over the WebSocket when the match is found
+func (b *Bridge) queueMatch(client *BrowserClient, m *QueueMatchMsg) {
+	req := QueueRequest{
+		Mode:      m.Mode,
+		PlayerID:  client.playerID,
+		Skill:     m.Skill,
+		LatencyMs: m.LatencyMs,
+	}
+	for _, id := range m.Party {
+		if id != "" && id != client.playerID {
+			req.Party = append(req.Party, id)
+		}
+	}
+
+	err := b.partyInRoom(client.room(), client.playerID, req.Party)
+	var ticket *matchmaker.Ticket
+	if err == nil {
+		ticket, err = b.matchmaker.Enqueue(req.request())
+	}
+	if err != nil {
+		client.send(map[string]interface{}{
+			"type":  "error",
+			"error": err.Error(),
+		})
+		return
+	}
+	b.sendToPlayers(ticket.Players, map[string]interface{}{
+		"type":     "match_queued",
+		"mode":     ticket.Mode,
+		"ticketId": ticket.ID,
+		"party":    ticket.Players,
+	})
+
+	go func() {
+		status, err := b.matchmaker.Wait(context.Background(), client.playerID)
+		if err != nil {
+			return
+		}
+		msg := map[string]interface{}{
+			"type":  "match_failed",
+			"error": status.Error,
+		}
+		switch status.State {
+		case matchmaker.StateMatched:
+			msg = map[string]interface{}{
+				"type":    "match_found",
+				"matchId": status.Match.ID,
+				"roomId":  status.Match.RoomID,
+				"mode":    status.Match.Mode,
+				"players": status.Match.Players,
+			}
+		case matchmaker.StateIdle:
+			msg = map[string]interface{}{"type": "match_cancelled"}
+		case matchmaker.StateQueued:
+			return // Cancelled and queued again; the new ticket reports
+		}
+		b.sendToPlayers(ticket.Players, msg)
+	}()
+}
+
+// sendToPlayers sends a message to every connected browser with one of
+// the given player IDs
+func (b *Bridge) sendToPlayers(playerIDs []string, msg interface{}) {
+	wanted := make(map[string]bool, len(playerIDs))
+	for _, id := range playerIDs {
+		wanted[id] = true
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, client := range b.clients {
+		if wanted[client.playerID] {
+			client.send(msg)
+		}
+	}
+}
@@ -0,0 +1,369 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// WebSocket protocol versions this bridge speaks. Browsers ask for one
+// with /ws?v=N and get the highest both sides support in the welcome;
+// browsers that don't ask get version 1.
+//
+//	1: full "state" on every update
+//	2: one full "state" on join, then "delta", "player_spawned" and
+//	   "player_removed"
+//	3: "input" must carry the client's seq; "input_ack" reports the last
+//	   one the game server applied
+const (
+	ProtocolVersion    = 3
+	minProtocolVersion = 1
+)
+
+// Message formats a browser can pick with /ws?format=
+const (
+	FormatJSON     = "json"     // Everything is JSON text frames
+	FormatProtobuf = "protobuf" // Game traffic is gamepb.Message in binary frames
+)
+
+// Error codes in ErrorMsg
+const (
+	ErrCodeMalformed   = "malformed"    // Not JSON, or a field has the wrong type
+	ErrCodeUnknownType = "unknown_type" // No such message type
+	ErrCodeInvalid     = "invalid"      // Well formed but missing or bad values
+	ErrCodeUnsupported = "unsupported"  // Not available in this format or version
+)
+
+var (
+	errMissingField = errors.New("missing field")
+	errBadValue     = errors.New("bad value")
+)
+
+// ================== Browser → bridge ==================
+
+// clientMessage is a decoded browser message
+type clientMessage interface {
+	validate() error
+}
+
+// Envelope is the part every browser message has. Messages with nothing
+// else decode to a bare *Envelope.
+type Envelope struct {
+	Type string `json:"type"`
+}
+
+func (*Envelope) validate() error { return nil }
+
+// HelloMsg sets the player's display name before joining a room
+type HelloMsg struct {
+	Envelope
+	Name string `json:"name"`
+}
+
+// InputMsg moves the player and presses buttons. Seq must increase with
+// every input; TS is the client's clock in milliseconds. Before version 3
+// both may be left out and the bridge fills them in.
+type InputMsg struct {
+	Envelope
+	Seq     uint64  `json:"seq"`
+	TS      uint64  `json:"ts"`
+	DX      float64 `json:"dx"`
+	DY      float64 `json:"dy"`
+	Jump    bool    `json:"jump"`
+	Action1 bool    `json:"action1"`
+	Action2 bool    `json:"action2"`
+}
+
+func (m *InputMsg) validate() error {
+	if m.DX < -1 || m.DX > 1 || m.DY < -1 || m.DY > 1 {
+		return fmt.Errorf("%w: dx and dy must be within [-1, 1]", errBadValue)
+	}
+	return nil
+}
+
+// JoinRoomMsg joins a room as a player or spectator
+type JoinRoomMsg struct {
+	Envelope
+	RoomID   string `json:"roomId"`
+	Name     string `json:"name"`
+	Password string `json:"password"`
+	Invite   string `json:"invite"`
+	Spectate bool   `json:"spectate"`
+}
+
+func (m *JoinRoomMsg) validate() error {
+	return require("roomId", m.RoomID)
+}
+
+// LifecycleMsg is a ready-check or host phase command (ready, start_game,
+// cancel_start, end_game, return_to_lobby, close_room)
+type LifecycleMsg struct {
+	Envelope
+	Ready bool `json:"ready"` // ready only
+}
+
+// ModerateMsg is a host moderation command (kick, ban, unban,
+// transfer_host, mute, unmute, lock_room)
+type ModerateMsg struct {
+	Envelope
+	PlayerID string `json:"playerId"`
+	Reason   string `json:"reason"`
+	Locked   bool   `json:"locked"` // lock_room only
+}
+
+func (m *ModerateMsg) validate() error {
+	if m.Type == "lock_room" {
+		return nil
+	}
+	return require("playerId", m.PlayerID)
+}
+
+// TeamMsg is a team command (choose_team, assign_team, balance_teams)
+type TeamMsg struct {
+	Envelope
+	Team     int    `json:"team"`
+	PlayerID string `json:"playerId"` // assign_team only
+}
+
+func (m *TeamMsg) validate() error {
+	if m.Team < 0 {
+		return fmt.Errorf("%w: team", errBadValue)
+	}
+	if m.Type == "assign_team" {
+		return require("playerId", m.PlayerID)
+	}
+	return nil
+}
+
+// ChatSendMsg sends a chat line to the room, or a whisper to one member
+type ChatSendMsg struct {
+	Envelope
+	Text string `json:"text"`
+	To   string `json:"to"`
+}
+
+func (m *ChatSendMsg) validate() error {
+	return require("text", m.Text)
+}
+
+// QueueMatchMsg joins the matchmaking queue with an optional party
+type QueueMatchMsg struct {
+	Envelope
+	Mode      string   `json:"mode"`
+	Skill     int      `json:"skill"`
+	LatencyMs int      `json:"latencyMs"`
+	Party     []string `json:"party"`
+}
+
+func (m *QueueMatchMsg) validate() error {
+	return require("mode", m.Mode)
+}
+
+// FollowMsg points a spectator's camera at a player ("" for free camera)
+type FollowMsg struct {
+	Envelope
+	PlayerID string `json:"playerId"`
+}
+
+// WebRTCSDPMsg carries a WebRTC offer or answer
+type WebRTCSDPMsg struct {
+	Envelope
+	SDP string `json:"sdp"`
+}
+
+func (m *WebRTCSDPMsg) validate() error {
+	return require("sdp", m.SDP)
+}
+
+// WebRTCICEMsg carries a WebRTC ICE candidate
+type WebRTCICEMsg struct {
+	Envelope
+	Candidate json.RawMessage `json:"candidate"`
+}
+
+func (m *WebRTCICEMsg) validate() error {
+	if len(m.Candidate) == 0 || string(m.Candidate) == "null" {
+		return fmt.Errorf("%w: candidate", errMissingField)
+	}
+	return nil
+}
+
+// clientMessages maps each browser message type to its struct
+var clientMessages = map[string]func() clientMessage{
+	"hello":           func() clientMessage { return &HelloMsg{} },
+	"input":           func() clientMessage { return &InputMsg{} },
+	"join_room":       func() clientMessage { return &JoinRoomMsg{} },
+	"leave_room":      func() clientMessage { return &Envelope{} },
+	"ready":           func() clientMessage { return &LifecycleMsg{} },
+	"start_game":      func() clientMessage { return &LifecycleMsg{} },
+	"cancel_start":    func() clientMessage { return &LifecycleMsg{} },
+	"end_game":        func() clientMessage { return &LifecycleMsg{} },
+	"return_to_lobby": func() clientMessage { return &LifecycleMsg{} },
+	"close_room":      func() clientMessage { return &LifecycleMsg{} },
+	"kick":            func() clientMessage { return &ModerateMsg{} },
+	"ban":             func() clientMessage { return &ModerateMsg{} },
+	"unban":           func() clientMessage { return &ModerateMsg{} },
+	"transfer_host":   func() clientMessage { return &ModerateMsg{} },
+	"mute":            func() clientMessage { return &ModerateMsg{} },
+	"unmute":          func() clientMessage { return &ModerateMsg{} },
+	"lock_room":       func() clientMessage { return &ModerateMsg{} },
+	"choose_team":     func() clientMessage { return &TeamMsg{} },
+	"assign_team":     func() clientMessage { return &TeamMsg{} },
+	"balance_teams":   func() clientMessage { return &TeamMsg{} },
+	"chat":            func() clientMessage { return &ChatSendMsg{} },
+	"create_invite":   func() clientMessage { return &Envelope{} },
+	"queue_match":     func() clientMessage { return &QueueMatchMsg{} },
+	"cancel_match":    func() clientMessage { return &Envelope{} },
+	"follow":          func() clientMessage { return &FollowMsg{} },
+	"webrtc_offer":    func() clientMessage { return &WebRTCSDPMsg{} },
+	"webrtc_answer":   func() clientMessage { return &WebRTCSDPMsg{} },
+	"webrtc_ice":      func() clientMessage { return &WebRTCICEMsg{} },
+}
+
+// decodeClientMessage parses a JSON browser message into its struct.
+// Errors come back as the ErrorMsg to send.
+func decodeClientMessage(data []byte) (clientMessage, *ErrorMsg) {
+	var env Envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, newErrorMsg(ErrCodeMalformed, "", "invalid JSON: "+err.Error())
+	}
+	newMsg, known := clientMessages[env.Type]
+	if !known {
+		return nil, newErrorMsg(ErrCodeUnknownType, env.Type, fmt.Sprintf("unknown message type %q", env.Type))
+	}
+
+	msg := newMsg()
+	if err := json.Unmarshal(data, msg); err != nil {
+		return nil, newErrorMsg(ErrCodeMalformed, env.Type, err.Error())
+	}
+	if err := msg.validate(); err != nil {
+		return nil, newErrorMsg(ErrCodeInvalid, env.Type, err.Error())
+	}
+	return msg, nil
+}
+
+// require fails if a required string field is empty
+func require(field, value string) error {
+	if value == "" {
+		return fmt.Errorf("%w: %s", errMissingField, field)
+	}
+	return nil
+}
+
+// ================== Bridge → browser ==================
+
+// WelcomeMsg is the first thing a browser hears, with the negotiated
+// protocol version and format
+type WelcomeMsg struct {
+	Type    string `json:"type"` // "welcome"
+	ID      string `json:"id"`
+	Version int    `json:"version"`
+	Format  string `json:"format"`
+	Session string `json:"session"` // Pass back as /ws?session= to keep this ID
+}
+
+// InputAckMsg tells a browser the game server has applied its inputs up to
+// Seq, leaving the player at X, Y. A predicting client resets to that
+// position and replays its inputs after Seq.
+type InputAckMsg struct {
+	Type string  `json:"type"` // "input_ack"
+	Seq  uint64  `json:"seq"`
+	X    float32 `json:"x"`
+	Y    float32 `json:"y"`
+}
+
+// ErrorMsg reports a message the bridge couldn't accept. Ref is the type
+// of the offending message, when known.
+type ErrorMsg struct {
+	Type  string `json:"type"` // "error"
+	Code  string `json:"code"`
+	Ref   string `json:"ref,omitempty"`
+	Error string `json:"error"`
+}
+
+func newErrorMsg(code, ref, text string) *ErrorMsg {
+	return &ErrorMsg{Type: "error", Code: code, Ref: ref, Error: text}
+}
+
+// negotiate picks the protocol version and format from the /ws query.
+// Returns an error for versions this bridge no longer speaks.
+func negotiate(r *http.Request) (version int, format string, err error) {
+	version = minProtocolVersion
+	if v := r.URL.Query().Get("v"); v != "" {
+		asked, convErr := strconv.Atoi(v)
+		if convErr != nil {
+			return 0, "", fmt.Errorf("%w: v", errBadValue)
+		}
+		if asked < minProtocolVersion {
+			return 0, "", fmt.Errorf("protocol version %d is no longer supported (minimum %d)", asked, minProtocolVersion)
+		}
+		version = min(asked, ProtocolVersion)
+	}
+
+	switch format = r.URL.Query().Get("format"); format {
+	case "":
+		format = FormatJSON
+	case FormatJSON, FormatProtobuf:
+	default:
+		return 0, "", fmt.Errorf("%w: format %q", errBadValue, format)
+	}
+	return version, format, nil
+}
+
+// handleSchema describes the browser messages: GET /ws/schema
+func (b *Bridge) handleSchema(w http.ResponseWriter, r *http.Request) {
+	messages := make(map[string]map[string]string, len(clientMessages))
+	for msgType, newMsg := range clientMessages {
+		messages[msgType] = schemaFields(reflect.TypeOf(newMsg()).Elem())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"version":    ProtocolVersion,
+		"minVersion": minProtocolVersion,
+		"formats":    []string{FormatJSON, FormatProtobuf},
+		"messages":   messages,
+	})
+}
+
+// schemaFields lists a message struct's JSON fields and their types
+func schemaFields(t reflect.Type) map[string]string {
+	fields := make(map[string]string)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous {
+			for name, kind := range schemaFields(f.Type) {
+				fields[name] = kind
+			}
+			continue
+		}
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		fields[name] = schemaType(f.Type)
+	}
+	return fields
+}
+
+func schemaType(t reflect.Type) string {
+	switch {
+	case t == reflect.TypeOf(json.RawMessage{}):
+		return "object"
+	case t.Kind() == reflect.String:
+		return "string"
+	case t.Kind() == reflect.Bool:
+		return "boolean"
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Float64:
+		return "number"
+	case t.Kind() == reflect.Slice:
+		return schemaType(t.Elem()) + "[]"
+	default:
+		return "object"
+	}
+}
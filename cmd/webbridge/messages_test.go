@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		query   string
+		version int
+		format  string
+		err     bool
+	}{
+		{"", 1, FormatJSON, false},
+		{"v=1", 1, FormatJSON, false},
+		{"v=2", 2, FormatJSON, false},
+		{"v=3", 3, FormatJSON, false},
+		{"v=99", ProtocolVersion, FormatJSON, false}, // Newer browsers get our best
+		{"v=3&format=json", 3, FormatJSON, false},
+		{"v=2&format=protobuf", 2, FormatProtobuf, false},
+		{"v=0", 0, "", true},
+		{"v=two", 0, "", true},
+		{"format=xml", 0, "", true},
+	}
+	for _, tt := range tests {
+		version, format, err := negotiate(httptest.NewRequest("GET", "/ws?"+tt.query, nil))
+		if (err != nil) != tt.err {
+			t.Errorf("%q: got error %v", tt.query, err)
+			continue
+		}
+		if version != tt.version || format != tt.format {
+			t.Errorf("%q: got v%d %q, want v%d %q", tt.query, version, format, tt.version, tt.format)
+		}
+	}
+}
+
+func TestDecodeClientMessages(t *testing.T) {
+	tests := []struct {
+		json string
+		code string // "" = accepted
+	}{
+		{`{"type":"hello","name":"Alice"}`, ""},
+		{`{"type":"input","seq":1,"dx":1,"dy":-1}`, ""},
+		{`{"type":"input","dx":0.5}`, ""}, // seq is checked per version by handleInput
+		{`{"type":"input","dx":2}`, ErrCodeInvalid},
+		{`{"type":"input","seq":"one"}`, ErrCodeMalformed},
+		{`{"type":"join_room","roomId":"r1","spectate":true}`, ""},
+		{`{"type":"join_room"}`, ErrCodeInvalid},
+		{`{"type":"leave_room"}`, ""},
+		{`{"type":"ready","ready":true}`, ""},
+		{`{"type":"start_game"}`, ""},
+		{`{"type":"cancel_start"}`, ""},
+		{`{"type":"end_game"}`, ""},
+		{`{"type":"return_to_lobby"}`, ""},
+		{`{"type":"close_room"}`, ""},
+		{`{"type":"kick","playerId":"p2","reason":"afk"}`, ""},
+		{`{"type":"kick"}`, ErrCodeInvalid},
+		{`{"type":"ban","playerId":"p2"}`, ""},
+		{`{"type":"ban"}`, ErrCodeInvalid},
+		{`{"type":"unban","playerId":"p2"}`, ""},
+		{`{"type":"unban"}`, ErrCodeInvalid},
+		{`{"type":"transfer_host","playerId":"p2"}`, ""},
+		{`{"type":"transfer_host"}`, ErrCodeInvalid},
+		{`{"type":"mute","playerId":"p2"}`, ""},
+		{`{"type":"mute"}`, ErrCodeInvalid},
+		{`{"type":"unmute","playerId":"p2"}`, ""},
+		{`{"type":"unmute"}`, ErrCodeInvalid},
+		{`{"type":"lock_room","locked":true}`, ""},
+		{`{"type":"choose_team","team":1}`, ""},
+		{`{"type":"choose_team","team":-1}`, ErrCodeInvalid},
+		{`{"type":"assign_team","team":2,"playerId":"p2"}`, ""},
+		{`{"type":"assign_team","team":2}`, ErrCodeInvalid},
+		{`{"type":"balance_teams"}`, ""},
+		{`{"type":"chat","text":"hi","to":"p2"}`, ""},
+		{`{"type":"chat"}`, ErrCodeInvalid},
+		{`{"type":"create_invite"}`, ""},
+		{`{"type":"queue_match","mode":"duel","party":["p2"]}`, ""},
+		{`{"type":"queue_match"}`, ErrCodeInvalid},
+		{`{"type":"cancel_match"}`, ""},
+		{`{"type":"follow","playerId":"p2"}`, ""},
+		{`{"type":"follow"}`, ""}, // Free camera
+		{`{"type":"webrtc_offer","sdp":"v=0"}`, ""},
+		{`{"type":"webrtc_offer"}`, ErrCodeInvalid},
+		{`{"type":"webrtc_answer","sdp":"v=0"}`, ""},
+		{`{"type":"webrtc_answer"}`, ErrCodeInvalid},
+		{`{"type":"webrtc_ice","candidate":{"candidate":"c"}}`, ""},
+		{`{"type":"webrtc_ice","candidate":null}`, ErrCodeInvalid},
+		{`{"type":"teleport"}`, ErrCodeUnknownType},
+		{`not json`, ErrCodeMalformed},
+	}
+
+	covered := make(map[string]bool)
+	for _, tt := range tests {
+		msg, errMsg := decodeClientMessage([]byte(tt.json))
+		code := ""
+		if errMsg != nil {
+			code = errMsg.Code
+		}
+		if code != tt.code {
+			t.Errorf("%s: got code %q (%v), want %q", tt.json, code, errMsg, tt.code)
+		}
+		if msg != nil {
+			var env Envelope
+			json.Unmarshal([]byte(tt.json), &env)
+			covered[env.Type] = true
+		}
+	}
+
+	// New message types need a case above
+	for msgType := range clientMessages {
+		if !covered[msgType] {
+			t.Errorf("no accepted %q message in the table", msgType)
+		}
+	}
+}
+
+func TestHandleInputSeq(t *testing.T) {
+	conn, _ := wsPair(t)
+	b := &Bridge{}
+
+	tests := []struct {
+		version   int
+		lastInput uint64
+		seq       uint64
+		err       string // Substring of the error sent back, "" = none
+	}{
+		{3, 0, 0, "missing field: seq"},
+		{3, 5, 5, "stale seq 5"},
+		{3, 5, 4, "stale seq 4"},
+		{3, 5, 6, ""},
+		{2, 5, 0, ""}, // The bridge numbers it
+		{1, 0, 0, ""},
+	}
+	for _, tt := range tests {
+		c := wrapBrowser(conn, "p1")
+		c.version = tt.version
+		c.lastInput = tt.lastInput
+		b.handleInput(c, &InputMsg{Envelope: Envelope{Type: "input"}, Seq: tt.seq})
+
+		var got ErrorMsg
+		select {
+		case f := <-c.out:
+			json.Unmarshal(f.data, &got)
+		default:
+		}
+		if tt.err == "" {
+			if got.Type != "" {
+				t.Errorf("v%d seq %d after %d: unexpected %+v", tt.version, tt.seq, tt.lastInput, got)
+			}
+			continue
+		}
+		if got.Code != ErrCodeInvalid || got.Ref != "input" || !strings.Contains(got.Error, tt.err) {
+			t.Errorf("v%d seq %d after %d: got %+v, want %q", tt.version, tt.seq, tt.lastInput, got, tt.err)
+		}
+	}
+}
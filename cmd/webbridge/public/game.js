@@ -4,6 +4,8 @@
 const pathParts = window.location.pathname.split('/');
 const ROOM_ID = pathParts[2] || null;
 const PLAYER_NAME = 'Player' + Math.floor(Math.random() * 1000);
+const SPECTATING = new URLSearchParams(window.location.search).has('spectate');
+const INVITE = new URLSearchParams(window.location.search).get('invite') || '';
 
 console.log('🎮 Room ID:', ROOM_ID, 'Player name:', PLAYER_NAME);
 
@@ -12,7 +14,21 @@ let ws = null;
 let myId = null;
 let players = {};
 let myPlayer = { x: 500, y: 500, vx: 0, vy: 0 };
-let keys = { up: false, down: false, left: false, right: false };
+let keys = { up: false, down: false, left: false, right: false, jump: false, action1: false, action2: false };
+let inputSeq = 0;      // Sequence of the last input sent
+let following = null; // Spectators: player the camera follows
+
+// Room lifecycle
+let phase = 'lobby';
+let isHost = false;
+let ready = false;
+let locked = false;
+let roomPassword = '';
+let teamCount = 0;  // 0 = free for all
+let myTeam = 0;
+
+// Team colours (index = team number, 0 = no team)
+const TEAM_COLORS = ['#7c3aed', '#ef4444', '#3b82f6', '#22c55e', '#eab308'];
 
 // Dynamic host detection
 const HOST = window.location.host;
@@ -357,8 +373,13 @@ function toggleCam() {
 
 // ================== Game Logic ==================
 
+// WebSocket protocol version this client speaks (see /ws/schema)
+const PROTOCOL_VERSION = 3;
+
 function connect() {
-    const wsUrl = `${WS_PROTOCOL}//${HOST}/ws`;
+    // Reconnect as the same player (e.g. after a match sent us here)
+    const session = sessionStorage.getItem('session') || '';
+    const wsUrl = `${WS_PROTOCOL}//${HOST}/ws?v=${PROTOCOL_VERSION}&session=${encodeURIComponent(session)}`;
     console.log('🔌 Connecting to:', wsUrl);
     
     ws = new WebSocket(wsUrl);
@@ -386,30 +407,42 @@ function connect() {
     };
 }
 
+// applyPlayers updates player positions from a state or delta
+function applyPlayers(list) {
+    (list || []).forEach(p => {
+        players[p.id] = p;
+        if (p.id === myId) {
+            myPlayer.x = p.x;
+            myPlayer.y = p.y;
+        }
+    });
+}
+
 function handleMessage(data) {
     // Log received messages (except state updates which are frequent)
-    if (data.type !== 'state') {
+    if (data.type !== 'state' && data.type !== 'delta' && data.type !== 'input_ack') {
         console.log('📨 Received:', data.type, data);
     }
     
     switch (data.type) {
         case 'welcome':
             myId = data.id;
-            console.log('✅ Got player ID from welcome:', myId);
+            sessionStorage.setItem('session', data.session);
+            console.log('✅ Got player ID from welcome:', myId, 'protocol v' + data.version);
             document.getElementById('player-id').textContent = myId;
             
-            // Join room
-            ws.send(JSON.stringify({
-                type: 'join_room',
-                roomId: ROOM_ID,
-                name: PLAYER_NAME
-            }));
+            joinRoom();
             break;
             
         case 'room_joined':
             console.log('✅ Joined room:', data.roomId, 'myId:', myId);
             document.getElementById('room-id').textContent = data.roomId;
             document.getElementById('player-count').textContent = data.playerCount;
+            isHost = !!data.isHost;
+            locked = !!data.locked;
+            teamCount = data.teams || 0;
+            myTeam = data.team || 0;
+            setPhase(data.phase || 'lobby');
             
             // Connect WebRTC after joining room
             console.log('🎬 Starting WebRTC connection...');
@@ -425,19 +458,142 @@ function handleMessage(data) {
         case 'player_left':
             showNotification(`👋 ${data.playerName} left`);
             document.getElementById('player-count').textContent = data.playerCount || '?';
+            if (following === data.playerId) following = null;
+            delete players[data.playerId];
+            break;
+
+        case 'spectator_joined':
+        case 'spectator_left':
+            console.log('👀 Spectators:', data.spectatorCount);
+            break;
+
+        case 'phase_changed':
+            setPhase(data.phase);
+            if (data.phase === 'countdown') {
+                showToast(`Game starts in ${Math.ceil(data.countdownMs / 1000)}s`);
+            } else if (data.phase === 'in_game') {
+                showToast('Go!');
+            } else if (data.phase === 'closed') {
+                showToast('Room closed by host');
+            }
+            break;
+
+        case 'player_ready':
+            if (data.playerId === myId) ready = data.ready;
+            updateLobbyUI();
+            break;
+
+        case 'host_changed':
+            isHost = data.hostId === myId;
+            if (isHost) showToast('You are now the host');
+            updateLobbyUI();
+            break;
+
+        case 'team_changed':
+            if (data.playerId === myId) {
+                myTeam = data.team;
+                showToast(`You are on team ${myTeam}`);
+                updateLobbyUI();
+            }
+            if (players[data.playerId]) players[data.playerId].team = data.team;
+            break;
+
+        case 'chat':
+            addChatLine(data);
+            break;
+
+        case 'chat_history':
+            document.getElementById('chat-log').innerHTML = '';
+            (data.messages || []).forEach(addChatLine);
+            break;
+
+        case 'chat_error':
+            showToast(data.error);
+            break;
+
+        case 'muted':
+            showToast(data.muted ? 'You were muted by the host' : 'You can chat again');
+            break;
+
+        case 'room_locked':
+            locked = data.locked;
+            showToast(locked ? 'Room locked' : 'Room unlocked');
+            updateLobbyUI();
+            break;
+
+        case 'player_kicked':
+            showToast(`${data.playerName || 'A player'} was ${data.banned ? 'banned' : 'kicked'}`);
+            document.getElementById('player-count').textContent = data.playerCount;
+            delete players[data.playerId];
+            break;
+
+        case 'kicked':
+            showToast(data.banned ? 'You were banned from this room' : 'You were kicked from this room');
+            players = {};
+            myId = null;
+            ws.close();
+            break;
+
+        case 'match_found':
+            // Matchmaking put us in a new room; the session keeps our ID
+            window.location.href = '/room/' + data.roomId;
+            break;
+
+        case 'match_failed':
+            showToast('Matchmaking failed: ' + data.error);
+            break;
+
+        case 'reconnect':
+            // The room lives on another node
+            window.location.href = data.url;
+            break;
+
+        case 'server_shutdown':
+            showToast('Server is restarting, rejoin in a moment');
+            break;
+
+        case 'server_restarting':
+            // Game server crashed; the bridge is bringing it back
+            players = {};
+            showToast('Game server crashed, restarting (attempt ' + data.attempt + ')');
+            break;
+
+        case 'server_restarted':
+            showToast('Game server is back');
+            break;
+
+        case 'server_crashed':
+            players = {};
+            showToast('Game server crashed: ' + data.error);
+            break;
+
+        case 'following':
+            following = data.playerId || null;
+            showToast(following ? 'Following ' + following.slice(0, 4) : 'Free camera');
             break;
             
         case 'state':
-            // Update player positions
-            if (data.players) {
-                data.players.forEach(p => {
-                    players[p.id] = p;
-                    if (p.id === myId) {
-                        myPlayer.x = p.x;
-                        myPlayer.y = p.y;
-                    }
-                });
-            }
+            // Full state: replaces whatever we had
+            players = {};
+            applyPlayers(data.players);
+            break;
+
+        case 'delta':
+            applyPlayers(data.players);
+            (data.removed || []).forEach(id => delete players[id]);
+            break;
+
+        case 'player_spawned':
+            applyPlayers([data.player]);
+            break;
+
+        case 'player_removed':
+            delete players[data.playerId];
+            break;
+
+        case 'input_ack':
+            // We draw the server's positions without predicting, so
+            // there's nothing to reconcile
             break;
             
         case 'webrtc_answer':
@@ -453,7 +609,27 @@ function handleMessage(data) {
             handleWebRTCRenegotiateOffer(data);
             break;
             
+        case 'invite_created': {
+            const inviteLink = window.location.origin + '/room/' + ROOM_ID + '?invite=' + encodeURIComponent(data.invite);
+            navigator.clipboard?.writeText(inviteLink);
+            showToast('Invite link copied!');
+            break;
+        }
+            
         case 'error':
+            if (data.code) {
+                // The bridge rejected a message we sent
+                console.warn('⚠️ Rejected', data.ref || 'message', '(' + data.code + '):', data.error);
+            }
+            // Private room: ask for the password and try again
+            if (data.error === 'room requires a password' || data.error === 'wrong room password') {
+                const pw = prompt(data.error === 'wrong room password' ? 'Wrong password, try again:' : 'Room password:');
+                if (pw) {
+                    roomPassword = pw;
+                    joinRoom();
+                    break;
+                }
+            }
             showToast('Error: ' + data.error);
             break;
     }
@@ -495,10 +671,19 @@ function gameLoop() {
         ctx.arc(p.x, p.y, 30, 0, Math.PI * 2);
         ctx.fill();
         
+        // Highlight the followed player
+        if (p.id === following) {
+            ctx.beginPath();
+            ctx.arc(p.x, p.y, 22, 0, Math.PI * 2);
+            ctx.strokeStyle = '#facc15';
+            ctx.lineWidth = 2;
+            ctx.stroke();
+        }
+
         // Draw player
         ctx.beginPath();
         ctx.arc(p.x, p.y, 15, 0, Math.PI * 2);
-        ctx.fillStyle = isMe ? '#00d4ff' : '#7c3aed';
+        ctx.fillStyle = p.team ? TEAM_COLORS[p.team % TEAM_COLORS.length] : (isMe ? '#00d4ff' : '#7c3aed');
         ctx.fill();
         ctx.strokeStyle = isMe ? '#00ffff' : '#9b59b6';
         ctx.lineWidth = 2;
@@ -508,7 +693,7 @@ function gameLoop() {
         ctx.fillStyle = '#fff';
         ctx.font = '12px Inter, sans-serif';
         ctx.textAlign = 'center';
-        ctx.fillText(isMe ? 'You' : p.id.slice(0, 4), p.x, p.y - 25);
+        ctx.fillText(isMe ? 'You' : (p.name || p.id.slice(0, 4)), p.x, p.y - 25);
     });
     
     requestAnimationFrame(gameLoop);
@@ -517,11 +702,15 @@ function gameLoop() {
 // ================== Input ==================
 
 document.addEventListener('keydown', (e) => {
+    if (e.target.tagName === 'INPUT') return;
     switch (e.key) {
         case 'w': case 'W': case 'ArrowUp': keys.up = true; break;
         case 's': case 'S': case 'ArrowDown': keys.down = true; break;
         case 'a': case 'A': case 'ArrowLeft': keys.left = true; break;
         case 'd': case 'D': case 'ArrowRight': keys.right = true; break;
+        case ' ': keys.jump = true; break;
+        case 'j': case 'J': keys.action1 = true; break;
+        case 'k': case 'K': keys.action2 = true; break;
     }
 });
 
@@ -531,27 +720,133 @@ document.addEventListener('keyup', (e) => {
         case 's': case 'S': case 'ArrowDown': keys.down = false; break;
         case 'a': case 'A': case 'ArrowLeft': keys.left = false; break;
         case 'd': case 'D': case 'ArrowRight': keys.right = false; break;
+        case ' ': keys.jump = false; break;
+        case 'j': case 'J': keys.action1 = false; break;
+        case 'k': case 'K': keys.action2 = false; break;
+    }
+});
+
+// ================== Chat ==================
+
+function addChatLine(msg) {
+    const log = document.getElementById('chat-log');
+    const line = document.createElement('div');
+    if (msg.whisper) line.className = 'whisper';
+    const from = document.createElement('span');
+    from.className = 'from';
+    from.textContent = msg.whisper && msg.from === myId ? `→ ${msg.to.slice(0, 4)}` : msg.fromName + ':';
+    line.appendChild(from);
+    line.appendChild(document.createTextNode(msg.text));
+    log.appendChild(line);
+    log.scrollTop = log.scrollHeight;
+}
+
+// Enter sends; "/w <id> text" whispers
+document.getElementById('chat-input').addEventListener('keydown', (e) => {
+    if (e.key !== 'Enter') return;
+    const input = e.target;
+    const text = input.value.trim();
+    if (!text) return;
+    const whisper = text.match(/^\/w\s+(\S+)\s+(.+)$/);
+    if (whisper) {
+        sendRoomCommand('chat', { to: whisper[1], text: whisper[2] });
+    } else {
+        sendRoomCommand('chat', { text });
+    }
+    input.value = '';
+});
+
+// Spectators click a player to follow them (click empty space to stop)
+// Hosts shift-click a player to kick them
+canvas.addEventListener('click', (e) => {
+    if (!ws || ws.readyState !== WebSocket.OPEN) return;
+    const target = Object.values(players).find(p => Math.hypot(p.x - e.clientX, p.y - e.clientY) < 20);
+    if (SPECTATING) {
+        ws.send(JSON.stringify({ type: 'follow', playerId: target ? target.id : '' }));
+    } else if (isHost && e.shiftKey && target && target.id !== myId) {
+        if (confirm(`Kick ${target.id}?`)) {
+            sendRoomCommand('kick', { playerId: target.id });
+        }
     }
 });
 
 // Send input to server
 setInterval(() => {
+    if (SPECTATING || phase !== 'in_game') return;
     if (ws && ws.readyState === WebSocket.OPEN && myId) {
         const dx = (keys.right ? 1 : 0) - (keys.left ? 1 : 0);
         const dy = (keys.down ? 1 : 0) - (keys.up ? 1 : 0);
         
-        if (dx !== 0 || dy !== 0) {
-            ws.send(JSON.stringify({
+        if (dx !== 0 || dy !== 0 || keys.jump || keys.action1 || keys.action2) {
+            const input = {
                 type: 'input',
+                seq: ++inputSeq,
+                ts: Date.now(),
                 dx: dx,
-                dy: dy
-            }));
+                dy: dy,
+                jump: keys.jump,
+                action1: keys.action1,
+                action2: keys.action2
+            };
+            ws.send(JSON.stringify(input));
         }
     }
 }, 1000 / 60); // 60 Hz
 
 // ================== UI ==================
 
+function setPhase(newPhase) {
+    phase = newPhase;
+    if (phase === 'lobby') ready = false;
+    document.getElementById('phase').textContent = phase.replace('_', ' ');
+    updateLobbyUI();
+}
+
+// Show the buttons that make sense for this player in this phase
+function updateLobbyUI() {
+    const show = (id, visible) => document.getElementById(id).style.display = visible ? '' : 'none';
+    const player = !SPECTATING;
+
+    show('btn-ready', player && !isHost && (phase === 'lobby' || phase === 'countdown'));
+    show('btn-start', player && isHost && phase === 'lobby');
+    show('btn-end', player && isHost && phase === 'in_game');
+    show('btn-lobby', player && isHost && phase === 'results');
+    show('btn-lock', player && isHost);
+    show('btn-invite', player && isHost);
+    show('btn-team', player && teamCount > 0 && phase === 'lobby');
+    show('btn-balance', player && isHost && teamCount > 0 && phase === 'lobby');
+    document.getElementById('btn-team').textContent = `Team ${myTeam}`;
+    document.getElementById('btn-lock').textContent = locked ? '🔒' : '🔓';
+    document.getElementById('btn-ready').textContent = ready ? 'Not ready' : 'Ready';
+}
+
+// Join with whatever credentials we have (invite from the URL, password
+// from a prompt)
+function joinRoom() {
+    sendRoomCommand('join_room', {
+        roomId: ROOM_ID,
+        name: PLAYER_NAME,
+        spectate: SPECTATING,
+        invite: INVITE,
+        password: roomPassword
+    });
+}
+
+function sendRoomCommand(type, extra = {}) {
+    if (ws && ws.readyState === WebSocket.OPEN) {
+        ws.send(JSON.stringify({ type, ...extra }));
+    }
+}
+
+function toggleReady() { sendRoomCommand('ready', { ready: !ready }); }
+function startGame() { sendRoomCommand('start_game'); }
+function endGame() { sendRoomCommand('end_game'); }
+function returnToLobby() { sendRoomCommand('return_to_lobby'); }
+function toggleLock() { sendRoomCommand('lock_room', { locked: !locked }); }
+function createInvite() { sendRoomCommand('create_invite'); }
+function switchTeam() { sendRoomCommand('choose_team', { team: myTeam % teamCount + 1 }); }
+function balanceTeams() { sendRoomCommand('balance_teams'); }
+
 function showToast(message) {
     const toast = document.createElement('div');
     toast.className = 'toast';
@@ -577,6 +872,12 @@ function copyLink() {
 window.toggleMic = toggleMic;
 window.toggleCam = toggleCam;
 window.copyLink = copyLink;
+window.toggleReady = toggleReady;
+window.startGame = startGame;
+window.endGame = endGame;
+window.returnToLobby = returnToLobby;
+window.toggleLock = toggleLock;
+window.createInvite = createInvite;
 
 // Start
 connect();
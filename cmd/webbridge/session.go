@@ -0,0 +1,369 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"net"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/LemmyAI/gameserver/internal/protocol"
+	"github.com/LemmyAI/gameserver/internal/protocol/gamepb"
+)
+
+// playerSession is one browser player's own link to the room's game
+// server: a UDP socket per player (or, for embedded engines, an address
+// per player), so the server can tell players apart and a leave or timeout
+// only affects the player it belongs to. The room's own connection
+// (GameRoom.UDPConn) carries room control only.
+//
+// Each browser's state comes from its own session, so it sees exactly
+// what the server shows that player. Spectators have sessions too and get
+// the server's spectator feed, which the server delays and filters by the
+// player they follow.
+type playerSession struct {
+	playerID  string
+	addr      string                        // How an embedded engine knows the player
+	conn      *net.UDPConn                  // Nil for embedded engines
+	server    string                        // Game server address conn is dialed to
+	client    atomic.Pointer[BrowserClient] // Who gets what the server sends
+	removed   atomic.Bool                   // The server has said it removed the player
+	spectator bool
+
+	// What a JSON browser has been shown so far
+	mu    sync.Mutex
+	state map[string]*gamepb.PlayerState
+}
+
+// openSession returns the browser's player (or spectator) session,
+// dialing one if they have none, it's for the other role, or the server
+// has moved since (a restart on another address)
+func (b *Bridge) openSession(gr *GameRoom, client *BrowserClient, spectator bool) (*playerSession, error) {
+	playerID := client.playerID
+	gr.Mu.RLock()
+	s := gr.sessions[playerID]
+	server, serverAddr := gr.Server, gr.UDPAddr
+	gr.Mu.RUnlock()
+
+	if s != nil && s.spectator != spectator {
+		s = nil
+	}
+
+	if _, direct := server.(gameLink); direct {
+		if s == nil {
+			s = newSession(client, embeddedAddr+"/"+playerID, spectator)
+			gr.addSession(s)
+		}
+		s.client.Store(client)
+		return s, nil
+	}
+
+	if serverAddr == nil {
+		return nil, errServerStopped
+	}
+	if s != nil && s.server == serverAddr.String() {
+		s.client.Store(client)
+		return s, nil
+	}
+	conn, err := net.DialUDP("udp", nil, serverAddr)
+	if err != nil {
+		return nil, err
+	}
+	s = newSession(client, conn.LocalAddr().String(), spectator)
+	s.conn = conn
+	s.server = serverAddr.String()
+	gr.addSession(s)
+	go b.receiveSession(gr, s)
+	go s.keepAlive()
+	return s, nil
+}
+
+func newSession(client *BrowserClient, addr string, spectator bool) *playerSession {
+	s := &playerSession{
+		playerID:  client.playerID,
+		addr:      addr,
+		spectator: spectator,
+		state:     make(map[string]*gamepb.PlayerState),
+	}
+	s.client.Store(client)
+	return s
+}
+
+// addSession registers a session, replacing the player's previous one
+func (gr *GameRoom) addSession(s *playerSession) {
+	gr.Mu.Lock()
+	old := gr.sessions[s.playerID]
+	gr.sessions[s.playerID] = s
+	gr.Mu.Unlock()
+
+	if old != nil && old.conn != nil {
+		old.conn.Close()
+	}
+}
+
+// session returns a player's session, nil if they have none
+func (gr *GameRoom) session(playerID string) *playerSession {
+	gr.Mu.RLock()
+	defer gr.Mu.RUnlock()
+	return gr.sessions[playerID]
+}
+
+// sendAs delivers a message to the game server from a player's session
+func (gr *GameRoom) sendAs(s *playerSession, msg *gamepb.Message) {
+	gr.Mu.RLock()
+	server := gr.Server
+	gr.Mu.RUnlock()
+
+	if link, direct := server.(gameLink); direct {
+		link.Deliver(s.addr, msg)
+		return
+	}
+	if s.conn == nil {
+		return
+	}
+	if data, err := protocol.Encode(msg); err == nil {
+		s.conn.Write(data)
+	}
+}
+
+// closeSession tells the game server a player left for good (or a
+// spectator stopped watching) and drops their session
+func (b *Bridge) closeSession(gr *GameRoom, playerID, reason string) {
+	gr.Mu.Lock()
+	s := gr.sessions[playerID]
+	delete(gr.sessions, playerID)
+	gr.Mu.Unlock()
+	if s == nil {
+		return
+	}
+
+	gr.sendAs(s, protocol.NewPlayerLeave(playerID, reason))
+	if s.conn != nil {
+		s.conn.Close()
+	}
+	log.Printf("🔌 %s left the game server for room %s (%s)", playerID, gr.ID, reason)
+}
+
+// closeSessions drops every session without a leave, for a server that's
+// going away
+func (gr *GameRoom) closeSessions() {
+	gr.Mu.Lock()
+	sessions := gr.sessions
+	gr.sessions = make(map[string]*playerSession)
+	gr.Mu.Unlock()
+
+	for _, s := range sessions {
+		if s.conn != nil {
+			s.conn.Close()
+		}
+	}
+}
+
+// receiveSession reads what the game server sends one player
+func (b *Bridge) receiveSession(gr *GameRoom, s *playerSession) {
+	buf := make([]byte, 4096)
+	for {
+		n, err := s.conn.Read(buf)
+		if errors.Is(err, syscall.ECONNREFUSED) {
+			// Server is down; the supervisor restarts it on the same port
+			continue
+		}
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return // Session closed or replaced
+			}
+			log.Printf("UDP read error for %s in room %s: %v", s.playerID, gr.ID, err)
+			return
+		}
+
+		msg, err := protocol.Decode(buf[:n])
+		if err != nil {
+			continue
+		}
+		b.handleSessionMessage(gr, s, msg)
+	}
+}
+
+// handleSessionMessage passes on a message the game server sent one
+// player or spectator. Their protobuf-mode browser gets it as is; a JSON
+// browser gets it converted.
+func (b *Bridge) handleSessionMessage(gr *GameRoom, s *playerSession, msg *gamepb.Message) {
+	if welcome := msg.GetServerWelcome(); welcome != nil {
+		log.Printf("🎮 Room %s: Welcome! Player ID: %s", gr.ID, welcome.PlayerId)
+	}
+
+	client := s.client.Load()
+	if client.format == FormatProtobuf {
+		if data, err := protocol.Encode(msg); err == nil {
+			client.sendBinary(data)
+		}
+	}
+
+	if !s.spectator {
+		if client.format == FormatJSON && client.version >= 3 {
+			b.ackInput(client, msg)
+		}
+		if leave := msg.GetPlayerLeave(); leave != nil && leave.PlayerId == s.playerID && !s.removed.Swap(true) {
+			// Off this goroutine: an embedded engine may be delivering it
+			// from inside its own kick
+			go b.leftGame(gr, client, leave.Reason)
+		}
+	}
+	if client.format == FormatJSON {
+		b.handleStateMessage(gr, s, client, msg)
+	}
+}
+
+// leftGame takes a player the game server removed on its own (an
+// anti-cheat kick or a timeout) out of the room too, so their browser
+// hears about it and they can't act in a room they're no longer playing
+// in. Players who left or were kicked through the bridge are already out.
+func (b *Bridge) leftGame(gr *GameRoom, client *BrowserClient, reason string) {
+	rm := b.rooms.Get(gr.ID)
+	if rm == nil || client.room() != gr.ID {
+		return
+	}
+	rm.Leave(client.playerID)
+	log.Printf("🥾 Room %s: game server removed %s (%s)", gr.ID, client.playerID, reason)
+	b.removeMember(rm, client.playerID, reason, false)
+}
+
+// ackInput sends a browser an input_ack when the game server reports (in
+// the player's own state) that it applied another of their inputs
+func (b *Bridge) ackInput(client *BrowserClient, msg *gamepb.Message) {
+	var players []*gamepb.PlayerState
+	switch payload := msg.Payload.(type) {
+	case *gamepb.Message_StateDelta:
+		players = payload.StateDelta.GetChangedPlayers()
+	case *gamepb.Message_StateSnapshot:
+		players = payload.StateSnapshot.GetPlayers()
+	default:
+		return
+	}
+	for _, p := range players {
+		if p.PlayerId != client.playerID {
+			continue
+		}
+		// Servers restart the count when a player resumes, so any
+		// change is news
+		if seq := p.LastInput; seq != 0 && client.ackedInput.Swap(seq) != seq {
+			client.send(InputAckMsg{
+				Type: "input_ack",
+				Seq:  seq,
+				X:    p.GetPosition().GetX(),
+				Y:    p.GetPosition().GetY(),
+			})
+		}
+		return
+	}
+}
+
+// handleStateMessage applies a message from a JSON browser's session to
+// what they've been shown and sends them the state, delta or event for it:
+// deltas and events to browsers that take them (see wantsDeltas), the full
+// state to the rest
+func (b *Bridge) handleStateMessage(gr *GameRoom, s *playerSession, client *BrowserClient, msg *gamepb.Message) {
+	deltas := b.wantsDeltas(client)
+
+	switch payload := msg.Payload.(type) {
+	case *gamepb.Message_StateDelta:
+		if payload.StateDelta == nil {
+			return
+		}
+		s.mu.Lock()
+		for _, p := range payload.StateDelta.ChangedPlayers {
+			s.state[p.PlayerId] = p
+		}
+		for _, id := range payload.StateDelta.RemovedPlayers {
+			delete(s.state, id)
+		}
+		s.mu.Unlock()
+		if deltas {
+			client.send(b.deltaMsg(gr, payload.StateDelta))
+		} else {
+			client.sendState(b.sessionState(gr, s, client))
+		}
+
+	case *gamepb.Message_StateSnapshot:
+		if payload.StateSnapshot == nil {
+			return
+		}
+		s.mu.Lock()
+		s.state = make(map[string]*gamepb.PlayerState)
+		for _, p := range payload.StateSnapshot.Players {
+			s.state[p.PlayerId] = p
+		}
+		s.mu.Unlock()
+		if deltas {
+			// Deltas that follow build on this state, so it can't be dropped
+			client.send(b.sessionState(gr, s, client))
+		} else {
+			client.sendState(b.sessionState(gr, s, client))
+		}
+
+	case *gamepb.Message_PlayerJoin:
+		if p := payload.PlayerJoin.GetPlayer(); p != nil {
+			s.mu.Lock()
+			s.state[p.PlayerId] = p
+			s.mu.Unlock()
+			if deltas {
+				client.send(PlayerSpawnedMsg{
+					Type:   "player_spawned",
+					Player: playerMsg(p, b.playerName(gr, p.PlayerId)),
+				})
+			}
+		}
+
+	case *gamepb.Message_PlayerLeave:
+		if leave := payload.PlayerLeave; leave != nil {
+			s.mu.Lock()
+			delete(s.state, leave.PlayerId)
+			s.mu.Unlock()
+			if deltas {
+				client.send(PlayerRemovedMsg{
+					Type:     "player_removed",
+					PlayerID: leave.PlayerId,
+					Name:     b.playerName(gr, leave.PlayerId),
+					Reason:   leave.Reason,
+				})
+			}
+		}
+	}
+}
+
+// sessionState is the full state a browser has been shown
+func (b *Bridge) sessionState(gr *GameRoom, s *playerSession, client *BrowserClient) StateMsg {
+	s.mu.Lock()
+	states := make([]*gamepb.PlayerState, 0, len(s.state))
+	for _, p := range s.state {
+		states = append(states, p)
+	}
+	s.mu.Unlock()
+
+	return StateMsg{
+		Type:      "state",
+		YourID:    client.playerID,
+		RoomID:    gr.ID,
+		Players:   b.playerMsgs(gr, states),
+		Following: client.following(),
+	}
+}
+
+// keepAlive sends the server a heartbeat every protocol.HeartbeatInterval
+// until the session closes, so a player who stops pressing keys isn't
+// taken for gone
+func (s *playerSession) keepAlive() {
+	ticker := time.NewTicker(protocol.HeartbeatInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		data, err := protocol.Encode(protocol.NewHeartbeat(uint64(time.Now().UnixMilli())))
+		if err != nil {
+			return
+		}
+		if _, err := s.conn.Write(data); errors.Is(err, net.ErrClosed) {
+			return
+		}
+	}
+}
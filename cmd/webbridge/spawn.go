@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"time"
+
+	"github.com/LemmyAI/gameserver/internal/orchestrator"
+	"github.com/LemmyAI/gameserver/internal/protocol"
+	"github.com/LemmyAI/gameserver/internal/protocol/gamepb"
+	"github.com/LemmyAI/gameserver/internal/room"
+	"github.com/LemmyAI/gameserver/internal/webrtc"
+)
+
+// How long a spawned server has to become ready (SPAWN_TIMEOUT)
+const defaultSpawnTimeout = 5 * time.Second
+
+var errServerStopped = errors.New("game server stopped")
+
+// launcherFromEnv picks how game servers run: through a server manager
+// (SERVER_MANAGER_URL), in this process (GAME_LAUNCHER=inprocess), or as
+// child processes (the default). A server manager needs MANAGER_SECRET to
+// sign requests and the same SESSION_SECRET as this bridge, since its
+// servers verify the tokens the bridge mints.
+func launcherFromEnv() orchestrator.Launcher {
+	if url := os.Getenv("SERVER_MANAGER_URL"); url != "" {
+		secret := os.Getenv("MANAGER_SECRET")
+		if secret == "" || os.Getenv("SESSION_SECRET") == "" {
+			log.Fatalf("❌ SERVER_MANAGER_URL needs MANAGER_SECRET and SESSION_SECRET set")
+		}
+		log.Printf("🧭 Game servers run by server manager at %s", url)
+		return &orchestrator.RemoteLauncher{URL: url, Secret: secret}
+	}
+	switch os.Getenv("GAME_LAUNCHER") {
+	case "inprocess":
+		log.Println("🧪 Game servers run in-process")
+		return &orchestrator.InProcessLauncher{}
+	case "", "process":
+		return &orchestrator.ProcessLauncher{}
+	default:
+		log.Fatalf("❌ Unknown GAME_LAUNCHER: %s", os.Getenv("GAME_LAUNCHER"))
+		return nil
+	}
+}
+
+// pendingSpawn lets concurrent joins wait for a server that's starting
+type pendingSpawn struct {
+	done chan struct{}
+	gr   *GameRoom
+	err  error
+}
+
+// spawnGameServer returns the room's game server, starting one if needed.
+// Joins that arrive while it starts wait for the same server.
+func (b *Bridge) spawnGameServer(roomID string) (*GameRoom, error) {
+	b.mu.Lock()
+	if gr, exists := b.gameRooms[roomID]; exists {
+		b.mu.Unlock()
+		return gr, nil
+	}
+	if p, starting := b.spawning[roomID]; starting {
+		b.mu.Unlock()
+		<-p.done
+		return p.gr, p.err
+	}
+	p := &pendingSpawn{done: make(chan struct{})}
+	b.spawning[roomID] = p
+	b.mu.Unlock()
+
+	p.gr, p.err = b.startGameServer(roomID)
+
+	b.mu.Lock()
+	delete(b.spawning, roomID)
+	if p.err == nil {
+		b.gameRooms[roomID] = p.gr
+	}
+	b.mu.Unlock()
+	close(p.done)
+
+	if p.err != nil {
+		log.Printf("❌ Game server for room %s: %v", roomID, p.err)
+		return nil, p.err
+	}
+
+	// Start WebRTC track handler
+	go b.handleWebRTCTracks(p.gr)
+
+	return p.gr, nil
+}
+
+// startGameServer launches a game server on a leased port (embedded
+// engines need none) and waits until it's ready
+func (b *Bridge) startGameServer(roomID string) (*GameRoom, error) {
+	gr := &GameRoom{
+		ID:       roomID,
+		WebRTC:   webrtc.NewManager(roomID),
+		sessions: make(map[string]*playerSession),
+		names:    make(map[string]string),
+	}
+	if _, embedded := b.launcher.(*embeddedLauncher); !embedded {
+		port, err := b.ports.Acquire(roomID)
+		if err != nil {
+			return nil, err
+		}
+		gr.Port = port
+	}
+	if err := b.launch(gr); err != nil {
+		b.ports.Release(gr.Port)
+		return nil, err
+	}
+
+	if gr.UDPAddr != nil {
+		log.Printf("🚀 Spawned game server for room %s on UDP %s", roomID, gr.UDPAddr)
+	}
+	go b.supervise(gr)
+	return gr, nil
+}
+
+// launch starts the room's game server on its leased port, waits until
+// it's ready and connects to it
+func (b *Bridge) launch(gr *GameRoom) error {
+	// Game server starts in the room's current phase and settings
+	spec := orchestrator.Spec{
+		RoomID: gr.ID,
+		Port:   gr.Port,
+		Phase:  room.PhaseLobby,
+		Env:    []string{"SESSION_SECRET=" + b.sessionSecret},
+
+		SpectatorDelay: b.spectatorDelay,
+	}
+	if rm := b.rooms.Get(gr.ID); rm != nil {
+		spec.Phase = rm.GetPhase()
+		spec.Settings = rm.GetSettings()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), b.spawnTimeout)
+	defer cancel()
+	server, err := b.launcher.Launch(ctx, spec)
+	if err != nil {
+		return err
+	}
+	if _, direct := server.(gameLink); !direct {
+		if err := gr.connect(server.Addr()); err != nil {
+			server.Stop(0)
+			return fmt.Errorf("failed to connect to server: %w", err)
+		}
+	}
+
+	gr.Mu.Lock()
+	if gr.stopping {
+		// Stopped while a restart was starting up
+		gr.Mu.Unlock()
+		server.Stop(0)
+		return errServerStopped
+	}
+	gr.Server = server
+	gr.status.State = ServerRunning
+	gr.status.PID = server.PID()
+	gr.status.StartedAt = time.Now()
+	gr.Mu.Unlock()
+	return nil
+}
+
+// connect dials the game server's UDP address. Local restarts reuse the
+// port, so the connection usually outlives the server; a server that comes
+// back elsewhere gets a fresh connection.
+func (gr *GameRoom) connect(addr string) error {
+	gr.Mu.RLock()
+	same := gr.UDPAddr != nil && gr.UDPAddr.String() == addr
+	gr.Mu.RUnlock()
+	if same {
+		return nil
+	}
+
+	udpAddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return err
+	}
+	conn, err := net.DialUDP("udp", nil, udpAddr)
+	if err != nil {
+		return err
+	}
+
+	gr.Mu.Lock()
+	old := gr.UDPConn
+	gr.UDPAddr = udpAddr
+	gr.UDPConn = conn
+	gr.Mu.Unlock()
+	if old != nil {
+		old.Close()
+	}
+	return nil
+}
+
+// conn returns the connection to the room's game server, nil for
+// embedded engines
+func (gr *GameRoom) conn() *net.UDPConn {
+	gr.Mu.RLock()
+	defer gr.Mu.RUnlock()
+	return gr.UDPConn
+}
+
+// send delivers a message to the room's game server
+func (gr *GameRoom) send(msg *gamepb.Message) {
+	gr.Mu.RLock()
+	server, conn := gr.Server, gr.UDPConn
+	gr.Mu.RUnlock()
+
+	if link, direct := server.(gameLink); direct {
+		link.Deliver(embeddedAddr, msg)
+		return
+	}
+	if conn == nil {
+		return
+	}
+	if data, err := protocol.Encode(msg); err == nil {
+		conn.Write(data)
+	}
+}
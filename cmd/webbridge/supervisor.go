@@ -0,0 +1,222 @@
+package main
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/LemmyAI/gameserver/internal/auth"
+	"github.com/LemmyAI/gameserver/internal/protocol"
+)
+
+// ServerState is where a room's game server process is in its life
+type ServerState string
+
+const (
+	ServerRunning    ServerState = "running"
+	ServerRestarting ServerState = "restarting"
+	ServerCrashed    ServerState = "crashed" // Out of restarts
+	ServerStopping   ServerState = "stopping"
+	ServerStopped    ServerState = "stopped"
+)
+
+const (
+	// Crashed servers are restarted this many times (GAME_SERVER_RESTARTS)
+	defaultMaxRestarts = 3
+	restartBackoff     = 500 * time.Millisecond // Times the restart count
+
+	// How long a server gets to exit after SIGTERM before SIGKILL
+	stopGrace = 5 * time.Second
+)
+
+// ServerStatus reports on a room's game server
+type ServerStatus struct {
+	State     ServerState `json:"state"`
+	PID       int         `json:"pid,omitempty"`
+	Port      int         `json:"port"`
+	StartedAt time.Time   `json:"startedAt"`
+	Restarts  int         `json:"restarts"`
+	LastExit  string      `json:"lastExit,omitempty"`
+}
+
+// Status returns the game server's process status
+func (gr *GameRoom) Status() ServerStatus {
+	gr.Mu.RLock()
+	defer gr.Mu.RUnlock()
+	s := gr.status
+	s.Port = gr.Port
+	return s
+}
+
+// maxRestartsFromEnv reads GAME_SERVER_RESTARTS
+func maxRestartsFromEnv() int {
+	if n, err := strconv.Atoi(os.Getenv("GAME_SERVER_RESTARTS")); err == nil && n >= 0 {
+		return n
+	}
+	return defaultMaxRestarts
+}
+
+// supervise waits on the room's game server. An exit nobody asked for is
+// a crash: the server is restarted on the same port and connected players
+// are sent a fresh hello, until it runs out of restarts.
+func (b *Bridge) supervise(gr *GameRoom) {
+	for {
+		gr.Mu.RLock()
+		server := gr.Server
+		gr.Mu.RUnlock()
+		<-server.Done()
+
+		gr.Mu.Lock()
+		if gr.stopping {
+			gr.Mu.Unlock()
+			return
+		}
+		gr.status.LastExit = server.ExitStatus()
+		gr.status.PID = 0
+		gr.Mu.Unlock()
+
+		log.Printf("💥 Game server for room %s crashed: %s", gr.ID, server.ExitStatus())
+		if !b.restart(gr) {
+			return
+		}
+	}
+}
+
+// restart relaunches a crashed server, backing off between attempts.
+// Returns false if the room gave up or is being stopped.
+func (b *Bridge) restart(gr *GameRoom) bool {
+	for {
+		gr.Mu.Lock()
+		if gr.stopping {
+			gr.Mu.Unlock()
+			return false
+		}
+		if gr.status.Restarts >= b.maxRestarts {
+			gr.status.State = ServerCrashed
+			gr.Mu.Unlock()
+			b.abandonGameRoom(gr)
+			return false
+		}
+		gr.status.Restarts++
+		gr.status.State = ServerRestarting
+		attempt := gr.status.Restarts
+		gr.Mu.Unlock()
+
+		b.broadcastToRoom(gr.ID, map[string]interface{}{
+			"type":    "server_restarting",
+			"attempt": attempt,
+		})
+		time.Sleep(restartBackoff * time.Duration(attempt))
+
+		if err := b.launch(gr); err != nil {
+			log.Printf("❌ Restart %d of room %s failed: %v", attempt, gr.ID, err)
+			continue
+		}
+		log.Printf("♻️  Restarted game server for room %s (restart %d)", gr.ID, attempt)
+		b.rehello(gr)
+		b.broadcastToRoom(gr.ID, map[string]interface{}{"type": "server_restarted"})
+		return true
+	}
+}
+
+// rehello joins the room's connected players and spectators to a
+// restarted server
+func (b *Bridge) rehello(gr *GameRoom) {
+	b.mu.RLock()
+	var members []*BrowserClient
+	for _, client := range b.clients {
+		if client.room() == gr.ID {
+			members = append(members, client)
+		}
+	}
+	b.mu.RUnlock()
+
+	for _, client := range members {
+		if client.isSpectator() {
+			b.sendSpectatorHello(gr, client)
+		} else {
+			b.sendHello(gr, client)
+		}
+	}
+	if rm := b.rooms.Get(gr.ID); rm != nil {
+		b.sendTeams(rm)
+	}
+}
+
+// sendHello joins a browser's player to the room's game server with a
+// signed session token
+func (b *Bridge) sendHello(gr *GameRoom, client *BrowserClient) {
+	s, err := b.openSession(gr, client, false)
+	if err != nil {
+		log.Printf("❌ Failed to open a game server session for %s in room %s: %v", client.playerID, gr.ID, err)
+		return
+	}
+	// Browsers are shown the name they joined with this time
+	gr.Mu.Lock()
+	gr.names[client.playerID] = client.name()
+	gr.Mu.Unlock()
+
+	token := b.sessions.Mint(client.playerID, gr.ID, sessionTTL)
+	gr.sendAs(s, protocol.NewSessionHello(client.playerID, client.name(), "1.0", token))
+}
+
+// sendSpectatorHello joins a browser to the room's game server as a
+// spectator following the player they picked. Sending it again changes who
+// they follow.
+func (b *Bridge) sendSpectatorHello(gr *GameRoom, client *BrowserClient) {
+	s, err := b.openSession(gr, client, true)
+	if err != nil {
+		log.Printf("❌ Failed to open a game server session for spectator %s in room %s: %v", client.playerID, gr.ID, err)
+		return
+	}
+	token := b.sessions.MintRole(client.playerID, gr.ID, auth.RoleSpectator, sessionTTL)
+	gr.sendAs(s, protocol.NewSpectatorHello(client.playerID, client.name(), "1.0", token, client.following()))
+}
+
+// abandonGameRoom drops a server that keeps crashing; the next join
+// starts a fresh one
+func (b *Bridge) abandonGameRoom(gr *GameRoom) {
+	b.mu.Lock()
+	if b.gameRooms[gr.ID] == gr {
+		delete(b.gameRooms, gr.ID)
+	}
+	b.mu.Unlock()
+
+	if conn := gr.conn(); conn != nil {
+		conn.Close()
+	}
+	gr.closeSessions()
+	b.ports.Release(gr.Port)
+	log.Printf("☠️  Game server for room %s gave up after %d restarts", gr.ID, gr.Status().Restarts)
+	b.broadcastToRoom(gr.ID, map[string]interface{}{
+		"type":  "server_crashed",
+		"error": "game server keeps crashing",
+	})
+}
+
+// terminate stops the server gracefully, forcing it if it hasn't exited
+// within stopGrace
+func (b *Bridge) terminate(gr *GameRoom) {
+	gr.Mu.Lock()
+	gr.stopping = true
+	gr.status.State = ServerStopping
+	server := gr.Server
+	gr.Mu.Unlock()
+
+	if server != nil {
+		if err := server.Stop(stopGrace); err != nil {
+			log.Printf("⚠️  Failed to stop game server for room %s: %v", gr.ID, err)
+		}
+	}
+	if conn := gr.conn(); conn != nil {
+		conn.Close()
+	}
+	gr.closeSessions()
+	b.ports.Release(gr.Port)
+
+	gr.Mu.Lock()
+	gr.status.State = ServerStopped
+	gr.status.PID = 0
+	gr.Mu.Unlock()
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"log"
+
+	"github.com/LemmyAI/gameserver/internal/protocol"
+	"github.com/LemmyAI/gameserver/internal/room"
+)
+
+// handleTeamChange tells the room and its game server a player switched
+// teams
+func (b *Bridge) handleTeamChange(rm *room.Room, playerID string, team int) {
+	log.Printf("🏳️  Room %s: %s → team %d", rm.ID, playerID, team)
+
+	b.broadcastToRoom(rm.ID, map[string]interface{}{
+		"type":     "team_changed",
+		"playerId": playerID,
+		"team":     team,
+	})
+	b.sendToGameServer(rm.ID, protocol.NewTeamControl(b.controlToken(rm.ID), map[string]uint32{
+		playerID: uint32(team),
+	}))
+}
+
+// sendTeams pushes every team assignment to the room's game server
+func (b *Bridge) sendTeams(rm *room.Room) {
+	if rm.Teams == 0 {
+		return
+	}
+	teams := make(map[string]uint32)
+	for id, team := range rm.TeamAssignments() {
+		teams[id] = uint32(team)
+	}
+	b.sendToGameServer(rm.ID, protocol.NewTeamControl(b.controlToken(rm.ID), teams))
+}
+
+// handleTeamCommand applies a team command from a browser.
+// Commands: choose_team (any player), assign_team and balance_teams (host).
+func (b *Bridge) handleTeamCommand(client *BrowserClient, m *TeamMsg) {
+	rm := b.rooms.Get(client.room())
+	if rm == nil || client.isSpectator() {
+		return
+	}
+
+	var err error
+	switch m.Type {
+	case "choose_team":
+		err = rm.ChooseTeam(client.playerID, m.Team)
+	case "assign_team":
+		err = rm.AssignTeam(client.playerID, m.PlayerID, m.Team)
+	case "balance_teams":
+		err = rm.BalanceTeams(client.playerID)
+	}
+
+	if err != nil {
+		client.send(map[string]interface{}{
+			"type":  "error",
+			"error": err.Error(),
+		})
+	}
+}
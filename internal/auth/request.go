@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Request signing headers. The secret itself never crosses the wire.
+const (
+	SignatureTimeHeader = "X-Signature-Time" // Unix seconds the request was signed at
+	SignatureHeader     = "X-Signature"      // Hex HMAC-SHA256, see requestMAC
+)
+
+// MaxClockSkew is how far a signed request's time may be from the
+// receiver's clock.
+const MaxClockSkew = 30 * time.Second
+
+// SignRequest signs a request to a service sharing secret. path is the
+// request's path and query relative to the service's base URL, so
+// services mounted under a prefix verify what their handler sees.
+func SignRequest(req *http.Request, secret, path string, body []byte, now time.Time) {
+	unix := strconv.FormatInt(now.Unix(), 10)
+	req.Header.Set(SignatureTimeHeader, unix)
+	req.Header.Set(SignatureHeader, requestMAC(secret, unix, req.Method, path, body))
+}
+
+// VerifyRequest checks a request's signature and time, leaving its body
+// readable.
+func VerifyRequest(r *http.Request, secret string, now time.Time) bool {
+	unix := r.Header.Get(SignatureTimeHeader)
+	sec, err := strconv.ParseInt(unix, 10, 64)
+	if err != nil {
+		return false
+	}
+	if skew := now.Sub(time.Unix(sec, 0)); skew > MaxClockSkew || skew < -MaxClockSkew {
+		return false
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return false
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	want := requestMAC(secret, unix, r.Method, r.URL.RequestURI(), body)
+	return hmac.Equal([]byte(r.Header.Get(SignatureHeader)), []byte(want))
+}
+
+// RequireSignature serves only requests signed with secret, answering
+// the rest with 401.
+func RequireSignature(secret string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !VerifyRequest(r, secret, time.Now()) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requestMAC is an HMAC-SHA256 over a request's time, method, path (with
+// query) and body.
+func requestMAC(secret, unix, method, path string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%s\n%s\n%s\n", unix, method, path)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,40 @@
+package auth
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSignedRequests(t *testing.T) {
+	now := time.Now()
+	signed := func(secret, path string, at time.Time) *http.Request {
+		body := []byte(`{"roomId":"r1"}`)
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+		SignRequest(req, secret, path, body, at)
+		return req
+	}
+
+	if !VerifyRequest(signed("secret", "/servers", now), "secret", now) {
+		t.Error("expected a signed request to verify")
+	}
+	if VerifyRequest(signed("wrong", "/servers", now), "secret", now) {
+		t.Error("expected a wrong secret to be rejected")
+	}
+	if VerifyRequest(signed("secret", "/servers", now.Add(-time.Minute)), "secret", now) {
+		t.Error("expected a stale request to be rejected")
+	}
+
+	tampered := signed("secret", "/servers", now)
+	tampered.Body = http.NoBody
+	if VerifyRequest(tampered, "secret", now) {
+		t.Error("expected a changed body to be rejected")
+	}
+	moved := signed("secret", "/servers", now)
+	moved.URL.Path = "/servers/r1"
+	if VerifyRequest(moved, "secret", now) {
+		t.Error("expected a changed path to be rejected")
+	}
+}
@@ -0,0 +1,128 @@
+// Package auth provides signed, expiring tokens shared between the
+// webbridge/room layer and game servers.
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Token errors.
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrTokenExpired = errors.New("token expired")
+)
+
+// Session roles.
+const (
+	RolePlayer    = "player"
+	RoleSpectator = "spectator"
+	RoleControl   = "control"  // Room owner steering the game server
+	RoleInvite    = "invite"   // Anyone holding it may join the room
+	RoleIdentity  = "identity" // Proves a browser's player ID across connections
+)
+
+// Claims are the signed contents of a session token.
+type Claims struct {
+	PlayerID  string `json:"pid"`
+	RoomID    string `json:"rid"`
+	Role      string `json:"role,omitempty"` // RolePlayer if empty
+	ExpiresAt int64  `json:"exp"`            // Unix seconds
+}
+
+// IsSpectator returns true if the token only grants spectator access.
+func (c *Claims) IsSpectator() bool {
+	return c.Role == RoleSpectator
+}
+
+// IsControl returns true if the token grants room control.
+func (c *Claims) IsControl() bool {
+	return c.Role == RoleControl
+}
+
+// Signer mints and verifies HMAC-SHA256 signed tokens.
+type Signer struct {
+	secret []byte
+}
+
+// NewSigner creates a signer with the given secret.
+func NewSigner(secret []byte) *Signer {
+	return &Signer{secret: secret}
+}
+
+// GenerateSecret returns a random hex-encoded secret suitable for NewSigner.
+func GenerateSecret() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Mint creates a session token for a player in a room.
+func (s *Signer) Mint(playerID, roomID string, ttl time.Duration) string {
+	return s.MintRole(playerID, roomID, RolePlayer, ttl)
+}
+
+// MintRole creates a session token with an explicit role.
+func (s *Signer) MintRole(playerID, roomID, role string, ttl time.Duration) string {
+	claims := Claims{
+		PlayerID:  playerID,
+		RoomID:    roomID,
+		Role:      role,
+		ExpiresAt: time.Now().Add(ttl).Unix(),
+	}
+	payload, _ := json.Marshal(claims)
+	return s.sign(payload)
+}
+
+// Verify checks a token's signature and expiry and returns its claims.
+func (s *Signer) Verify(token string) (*Claims, error) {
+	payload, err := s.open(token)
+	if err != nil {
+		return nil, err
+	}
+
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+	if time.Now().Unix() > claims.ExpiresAt {
+		return nil, ErrTokenExpired
+	}
+	return &claims, nil
+}
+
+// sign encodes payload as "<payload>.<mac>".
+func (s *Signer) sign(payload []byte) string {
+	enc := base64.RawURLEncoding.EncodeToString(payload)
+	return enc + "." + base64.RawURLEncoding.EncodeToString(s.mac(enc))
+}
+
+// open checks the signature on a token and returns the decoded payload.
+func (s *Signer) open(token string) ([]byte, error) {
+	enc, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(mac, s.mac(enc)) {
+		return nil, ErrInvalidToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(enc)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	return payload, nil
+}
+
+func (s *Signer) mac(data string) []byte {
+	h := hmac.New(sha256.New, s.secret)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,43 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMintVerify(t *testing.T) {
+	s := NewSigner([]byte("secret"))
+	token := s.Mint("p1", "room1", time.Minute)
+
+	claims, err := s.Verify(token)
+	if err != nil {
+		t.Fatalf("verify failed: %v", err)
+	}
+	if claims.PlayerID != "p1" || claims.RoomID != "room1" {
+		t.Errorf("unexpected claims: %+v", claims)
+	}
+}
+
+func TestVerifyRejectsTampering(t *testing.T) {
+	s := NewSigner([]byte("secret"))
+	token := s.Mint("p1", "room1", time.Minute)
+
+	if _, err := NewSigner([]byte("other")).Verify(token); err != ErrInvalidToken {
+		t.Errorf("expected ErrInvalidToken for wrong secret, got %v", err)
+	}
+
+	forged := s.Mint("p2", "room1", time.Minute)
+	mixed := token[:len(token)/2] + forged[len(forged)/2:]
+	if _, err := s.Verify(mixed); err != ErrInvalidToken {
+		t.Errorf("expected ErrInvalidToken for spliced token, got %v", err)
+	}
+}
+
+func TestVerifyExpired(t *testing.T) {
+	s := NewSigner([]byte("secret"))
+	token := s.Mint("p1", "room1", -time.Minute)
+
+	if _, err := s.Verify(token); err != ErrTokenExpired {
+		t.Errorf("expected ErrTokenExpired, got %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+// Package cluster tracks which webbridge node hosts each room so several
+// nodes can share the load. Nodes heartbeat into a Directory with their
+// capacity, new rooms are placed on a node with room to spare, and joins
+// that reach the wrong node are sent to the room's owner.
+package cluster
+
+import (
+	"errors"
+	"time"
+)
+
+// Cluster errors
+var (
+	ErrInvalidNode = errors.New("invalid node")
+	ErrUnknownNode = errors.New("unknown or dead node")
+	ErrNoCapacity  = errors.New("no node has capacity")
+	ErrUnknownRoom = errors.New("room is not placed on any node")
+	ErrNodeDown    = errors.New("room's node is down")
+	ErrRoomTaken   = errors.New("room is placed on another node")
+)
+
+// Node is a webbridge that can host rooms
+type Node struct {
+	ID       string    `json:"id"`
+	URL      string    `json:"url"`      // Public base URL browsers reach the node on
+	Capacity int       `json:"capacity"` // Most rooms the node takes; 0 for no limit
+	Rooms    int       `json:"rooms"`    // Rooms the node hosts
+	LastSeen time.Time `json:"lastSeen"`
+}
+
+// HasCapacity reports whether the node can take another room
+func (n Node) HasCapacity() bool {
+	return n.Capacity == 0 || n.Rooms < n.Capacity
+}
+
+// Directory is the shared view of nodes and where rooms live.
+// MemoryDirectory serves a single process (or tests with several nodes
+// in one process); Client reaches a directory served over HTTP.
+type Directory interface {
+	// Heartbeat registers the node or refreshes it, including its load
+	Heartbeat(node Node) error
+
+	// Leave removes the node and forgets its rooms
+	Leave(nodeID string) error
+
+	// Nodes returns the live nodes, ordered by ID
+	Nodes() ([]Node, error)
+
+	// Pick chooses a node for a new room: preferred if it's live and has
+	// capacity, otherwise the least loaded node that does
+	Pick(preferred string) (Node, error)
+
+	// Assign places a room on a node
+	Assign(roomID, nodeID string) error
+
+	// Lookup returns the node hosting a room. A room whose node stopped
+	// heartbeating returns that node with ErrNodeDown.
+	Lookup(roomID string) (Node, error)
+
+	// Release forgets a room
+	Release(roomID string) error
+}
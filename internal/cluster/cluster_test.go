@@ -0,0 +1,141 @@
+package cluster
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newTestDirectory returns a directory with a clock the test moves
+func newTestDirectory() (*MemoryDirectory, *time.Time) {
+	d := NewMemoryDirectory(10 * time.Second)
+	now := time.Now()
+	d.now = func() time.Time { return now }
+	return d, &now
+}
+
+func TestPickPrefersSelfThenLeastLoaded(t *testing.T) {
+	d, _ := newTestDirectory()
+	d.Heartbeat(Node{ID: "a", Capacity: 2, Rooms: 2})
+	d.Heartbeat(Node{ID: "b", Capacity: 5, Rooms: 3})
+	d.Heartbeat(Node{ID: "c", Rooms: 1})
+
+	if n, _ := d.Pick("b"); n.ID != "b" {
+		t.Errorf("expected preferred node b, got %s", n.ID)
+	}
+	// a is full, c is the least loaded
+	if n, _ := d.Pick("a"); n.ID != "c" {
+		t.Errorf("expected least loaded node c, got %s", n.ID)
+	}
+}
+
+func TestAssignCountsTowardsCapacity(t *testing.T) {
+	d, _ := newTestDirectory()
+	d.Heartbeat(Node{ID: "a", Capacity: 1})
+
+	if err := d.Assign("r1", "a"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.Pick("a"); !errors.Is(err, ErrNoCapacity) {
+		t.Errorf("expected ErrNoCapacity once full, got %v", err)
+	}
+	d.Release("r1")
+	if _, err := d.Pick("a"); err != nil {
+		t.Errorf("expected capacity after release, got %v", err)
+	}
+}
+
+func TestLookupAndNodeExpiry(t *testing.T) {
+	d, now := newTestDirectory()
+	d.Heartbeat(Node{ID: "a", URL: "http://a"})
+	d.Heartbeat(Node{ID: "b", URL: "http://b"})
+	d.Assign("r1", "a")
+
+	if n, err := d.Lookup("r1"); err != nil || n.URL != "http://a" {
+		t.Fatalf("expected r1 on a, got %+v (%v)", n, err)
+	}
+	if err := d.Assign("r1", "b"); !errors.Is(err, ErrRoomTaken) {
+		t.Errorf("expected ErrRoomTaken, got %v", err)
+	}
+	if _, err := d.Lookup("nope"); !errors.Is(err, ErrUnknownRoom) {
+		t.Errorf("expected ErrUnknownRoom, got %v", err)
+	}
+
+	// a stops heartbeating
+	*now = now.Add(11 * time.Second)
+	d.Heartbeat(Node{ID: "b", URL: "http://b"})
+	if n, err := d.Lookup("r1"); !errors.Is(err, ErrNodeDown) || n.ID != "a" {
+		t.Errorf("expected ErrNodeDown for a, got %+v (%v)", n, err)
+	}
+	if nodes, _ := d.Nodes(); len(nodes) != 1 || nodes[0].ID != "b" {
+		t.Errorf("expected only b live, got %+v", nodes)
+	}
+	if err := d.Assign("r1", "b"); err != nil {
+		t.Errorf("expected a dead node's room to be reassignable, got %v", err)
+	}
+}
+
+func TestMembersShareDirectory(t *testing.T) {
+	d := NewMemoryDirectory(DefaultNodeTTL)
+	rooms := map[string]int{"a": 3, "b": 0}
+
+	a, err := Join(d, Node{ID: "a", URL: "http://a", Capacity: 3}, 10*time.Millisecond, func() int { return rooms["a"] })
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Join(d, Node{ID: "b", URL: "http://b"}, 10*time.Millisecond, func() int { return rooms["b"] })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+
+	// a is full, so new rooms go to b even when a asks first
+	if n, _ := d.Pick(a.ID()); n.ID != "b" {
+		t.Errorf("expected b, got %s", n.ID)
+	}
+
+	d.Assign("r1", "a")
+	a.Close()
+	if _, err := d.Lookup("r1"); !errors.Is(err, ErrUnknownRoom) {
+		t.Errorf("expected a's rooms forgotten when it leaves, got %v", err)
+	}
+	if nodes, _ := d.Nodes(); len(nodes) != 1 {
+		t.Errorf("expected one node after a left, got %d", len(nodes))
+	}
+}
+
+func TestClient(t *testing.T) {
+	d := NewMemoryDirectory(DefaultNodeTTL)
+	srv := httptest.NewServer(http.StripPrefix("/cluster", Handler(d, "secret")))
+	defer srv.Close()
+	c := NewClient(srv.URL+"/cluster", "secret")
+
+	if err := c.Heartbeat(Node{ID: "a", URL: "http://a"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Assign("r1", "a"); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := c.Lookup("r1"); err != nil || n.URL != "http://a" {
+		t.Errorf("expected r1 on a, got %+v (%v)", n, err)
+	}
+	if _, err := c.Lookup("r2"); !errors.Is(err, ErrUnknownRoom) {
+		t.Errorf("expected ErrUnknownRoom over the wire, got %v", err)
+	}
+	if n, err := c.Pick(""); err != nil || n.ID != "a" {
+		t.Errorf("expected to pick a, got %+v (%v)", n, err)
+	}
+	if err := c.Leave("a"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Pick(""); !errors.Is(err, ErrNoCapacity) {
+		t.Errorf("expected ErrNoCapacity with no nodes, got %v", err)
+	}
+
+	bad := NewClient(srv.URL+"/cluster", "wrong")
+	if err := bad.Heartbeat(Node{ID: "b"}); err == nil {
+		t.Error("expected a wrong secret to be rejected")
+	}
+}
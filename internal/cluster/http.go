@@ -0,0 +1,201 @@
+package cluster
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/LemmyAI/gameserver/internal/auth"
+)
+
+// Handler serves a directory over HTTP to Client. Requests must be signed
+// with the shared secret (see auth.SignRequest).
+//
+//	GET    /nodes         live nodes
+//	PUT    /nodes/{id}    heartbeat (body: Node)
+//	DELETE /nodes/{id}    leave
+//	GET    /pick?prefer=  choose a node for a new room
+//	GET    /rooms/{id}    look up a room's node
+//	PUT    /rooms/{id}    assign (body: {"nodeId": ...})
+//	DELETE /rooms/{id}    release
+func Handler(dir Directory, secret string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/nodes", func(w http.ResponseWriter, r *http.Request) {
+		nodes, err := dir.Nodes()
+		reply(w, nodes, err)
+	})
+	mux.HandleFunc("/nodes/", func(w http.ResponseWriter, r *http.Request) {
+		nodeID := strings.TrimPrefix(r.URL.Path, "/nodes/")
+		switch r.Method {
+		case http.MethodPut:
+			var node Node
+			if err := json.NewDecoder(r.Body).Decode(&node); err != nil || node.ID != nodeID {
+				reply(w, nil, ErrInvalidNode)
+				return
+			}
+			reply(w, nil, dir.Heartbeat(node))
+		case http.MethodDelete:
+			reply(w, nil, dir.Leave(nodeID))
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+	mux.HandleFunc("/pick", func(w http.ResponseWriter, r *http.Request) {
+		node, err := dir.Pick(r.URL.Query().Get("prefer"))
+		reply(w, node, err)
+	})
+	mux.HandleFunc("/rooms/", func(w http.ResponseWriter, r *http.Request) {
+		roomID := strings.TrimPrefix(r.URL.Path, "/rooms/")
+		switch r.Method {
+		case http.MethodGet:
+			node, err := dir.Lookup(roomID)
+			reply(w, node, err)
+		case http.MethodPut:
+			var req struct {
+				NodeID string `json:"nodeId"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				reply(w, nil, ErrInvalidNode)
+				return
+			}
+			reply(w, nil, dir.Assign(roomID, req.NodeID))
+		case http.MethodDelete:
+			reply(w, nil, dir.Release(roomID))
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
+	return auth.RequireSignature(secret, mux)
+}
+
+// wireErrors are the errors that cross the wire by message
+var wireErrors = []error{
+	ErrInvalidNode, ErrUnknownNode, ErrNoCapacity, ErrUnknownRoom, ErrNodeDown, ErrRoomTaken,
+}
+
+// response is the body of every directory reply. Lookups of rooms on dead
+// nodes carry both the node and the error.
+type response struct {
+	Error string          `json:"error,omitempty"`
+	Data  json.RawMessage `json:"data,omitempty"`
+}
+
+func reply(w http.ResponseWriter, data interface{}, err error) {
+	var resp response
+	status := http.StatusOK
+	if data != nil {
+		resp.Data, _ = json.Marshal(data)
+	}
+	if err != nil {
+		resp.Error = err.Error()
+		status = http.StatusConflict
+		if errors.Is(err, ErrInvalidNode) {
+			status = http.StatusBadRequest
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// Client is a Directory served by Handler on another node
+type Client struct {
+	URL    string       // Directory base URL, e.g. http://node-1:8081/cluster
+	Secret string       // Signs requests (CLUSTER_SECRET on the webbridge)
+	HTTP   *http.Client // Defaults to a client with a short timeout
+}
+
+// NewClient creates a client for the directory at baseURL
+func NewClient(baseURL, secret string) *Client {
+	return &Client{
+		URL:    strings.TrimSuffix(baseURL, "/"),
+		Secret: secret,
+		HTTP:   &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+func (c *Client) Heartbeat(node Node) error {
+	return c.do(http.MethodPut, "/nodes/"+url.PathEscape(node.ID), node, nil)
+}
+
+func (c *Client) Leave(nodeID string) error {
+	return c.do(http.MethodDelete, "/nodes/"+url.PathEscape(nodeID), nil, nil)
+}
+
+func (c *Client) Nodes() ([]Node, error) {
+	var nodes []Node
+	err := c.do(http.MethodGet, "/nodes", nil, &nodes)
+	return nodes, err
+}
+
+func (c *Client) Pick(preferred string) (Node, error) {
+	var node Node
+	err := c.do(http.MethodGet, "/pick?prefer="+url.QueryEscape(preferred), nil, &node)
+	return node, err
+}
+
+func (c *Client) Assign(roomID, nodeID string) error {
+	return c.do(http.MethodPut, "/rooms/"+url.PathEscape(roomID), map[string]string{"nodeId": nodeID}, nil)
+}
+
+func (c *Client) Lookup(roomID string) (Node, error) {
+	var node Node
+	err := c.do(http.MethodGet, "/rooms/"+url.PathEscape(roomID), nil, &node)
+	return node, err
+}
+
+func (c *Client) Release(roomID string) error {
+	return c.do(http.MethodDelete, "/rooms/"+url.PathEscape(roomID), nil, nil)
+}
+
+// do calls the directory, decoding any data into out even when the reply
+// is an error
+func (c *Client) do(method, path string, body, out interface{}) error {
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequest(method, c.URL+path, bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		return err
+	}
+	auth.SignRequest(req, c.Secret, path, buf.Bytes(), time.Now())
+	req.Header.Set("Content-Type", "application/json")
+
+	client := c.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("directory %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	var r response
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return fmt.Errorf("directory %s %s: %s", method, path, resp.Status)
+	}
+	if out != nil && len(r.Data) > 0 {
+		if err := json.Unmarshal(r.Data, out); err != nil {
+			return err
+		}
+	}
+	if r.Error == "" {
+		return nil
+	}
+	for _, e := range wireErrors {
+		if r.Error == e.Error() {
+			return e
+		}
+	}
+	return errors.New(r.Error)
+}
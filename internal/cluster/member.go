@@ -0,0 +1,87 @@
+package cluster
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// DefaultHeartbeatInterval is how often a member reports to the directory
+const DefaultHeartbeatInterval = 5 * time.Second
+
+// Member keeps this node registered in a directory, heartbeating its
+// current load until closed
+type Member struct {
+	dir      Directory
+	node     Node
+	load     func() int
+	interval time.Duration
+	stop     chan struct{}
+	done     chan struct{}
+	once     sync.Once
+}
+
+// Join registers the node and starts heartbeating. load reports how many
+// rooms the node hosts.
+func Join(dir Directory, node Node, interval time.Duration, load func() int) (*Member, error) {
+	node.Rooms = load()
+	if err := dir.Heartbeat(node); err != nil {
+		return nil, err
+	}
+	m := &Member{
+		dir:      dir,
+		node:     node,
+		load:     load,
+		interval: interval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go m.loop()
+	return m, nil
+}
+
+// ID returns the node's ID
+func (m *Member) ID() string {
+	return m.node.ID
+}
+
+// Node returns the node as registered
+func (m *Member) Node() Node {
+	return m.node
+}
+
+// Directory returns the directory the node belongs to
+func (m *Member) Directory() Directory {
+	return m.dir
+}
+
+// Close stops heartbeating and leaves the directory
+func (m *Member) Close() {
+	m.once.Do(func() {
+		close(m.stop)
+		<-m.done
+		if err := m.dir.Leave(m.node.ID); err != nil {
+			log.Printf("⚠️  Node %s failed to leave the directory: %v", m.node.ID, err)
+		}
+	})
+}
+
+func (m *Member) loop() {
+	defer close(m.done)
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.C:
+		}
+
+		node := m.node
+		node.Rooms = m.load()
+		if err := m.dir.Heartbeat(node); err != nil {
+			log.Printf("⚠️  Node %s heartbeat failed: %v", node.ID, err)
+		}
+	}
+}
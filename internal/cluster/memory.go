@@ -0,0 +1,161 @@
+package cluster
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultNodeTTL is how long a node stays live without a heartbeat
+const DefaultNodeTTL = 15 * time.Second
+
+// MemoryDirectory keeps the directory in memory. Several nodes in one
+// process (e.g. a multi-node test) can share one.
+type MemoryDirectory struct {
+	ttl   time.Duration
+	nodes map[string]*Node
+	rooms map[string]string // roomID -> nodeID
+	now   func() time.Time
+	mu    sync.Mutex
+}
+
+// NewMemoryDirectory creates a directory that drops nodes not heard from
+// within ttl
+func NewMemoryDirectory(ttl time.Duration) *MemoryDirectory {
+	return &MemoryDirectory{
+		ttl:   ttl,
+		nodes: make(map[string]*Node),
+		rooms: make(map[string]string),
+		now:   time.Now,
+	}
+}
+
+// Heartbeat registers or refreshes a node
+func (d *MemoryDirectory) Heartbeat(node Node) error {
+	if node.ID == "" {
+		return ErrInvalidNode
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	node.LastSeen = d.now()
+	d.nodes[node.ID] = &node
+	return nil
+}
+
+// Leave removes a node and its rooms
+func (d *MemoryDirectory) Leave(nodeID string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, exists := d.nodes[nodeID]; !exists {
+		return ErrUnknownNode
+	}
+	delete(d.nodes, nodeID)
+	for roomID, owner := range d.rooms {
+		if owner == nodeID {
+			delete(d.rooms, roomID)
+		}
+	}
+	return nil
+}
+
+// Nodes returns the live nodes, ordered by ID
+func (d *MemoryDirectory) Nodes() ([]Node, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	nodes := make([]Node, 0, len(d.nodes))
+	for _, n := range d.nodes {
+		if d.liveLocked(n) {
+			nodes = append(nodes, *n)
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+	return nodes, nil
+}
+
+// Pick chooses a node for a new room
+func (d *MemoryDirectory) Pick(preferred string) (Node, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if n, exists := d.nodes[preferred]; exists && d.liveLocked(n) && n.HasCapacity() {
+		return *n, nil
+	}
+
+	var best *Node
+	for _, n := range d.nodes {
+		if !d.liveLocked(n) || !n.HasCapacity() {
+			continue
+		}
+		if best == nil || n.Rooms < best.Rooms || (n.Rooms == best.Rooms && n.ID < best.ID) {
+			best = n
+		}
+	}
+	if best == nil {
+		return Node{}, ErrNoCapacity
+	}
+	return *best, nil
+}
+
+// Assign places a room on a node. The node's load counts the room right
+// away so placements between heartbeats see it.
+func (d *MemoryDirectory) Assign(roomID, nodeID string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	n, exists := d.nodes[nodeID]
+	if !exists || !d.liveLocked(n) {
+		return ErrUnknownNode
+	}
+	if owner, placed := d.rooms[roomID]; placed {
+		if owner == nodeID {
+			return nil
+		}
+		if o, exists := d.nodes[owner]; exists && d.liveLocked(o) {
+			return ErrRoomTaken
+		}
+	}
+	d.rooms[roomID] = nodeID
+	n.Rooms++
+	return nil
+}
+
+// Lookup returns the node hosting a room
+func (d *MemoryDirectory) Lookup(roomID string) (Node, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	owner, placed := d.rooms[roomID]
+	if !placed {
+		return Node{}, ErrUnknownRoom
+	}
+	n, exists := d.nodes[owner]
+	if !exists {
+		return Node{ID: owner}, ErrNodeDown
+	}
+	if !d.liveLocked(n) {
+		return *n, ErrNodeDown
+	}
+	return *n, nil
+}
+
+// Release forgets a room
+func (d *MemoryDirectory) Release(roomID string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	owner, placed := d.rooms[roomID]
+	if !placed {
+		return ErrUnknownRoom
+	}
+	delete(d.rooms, roomID)
+	if n, exists := d.nodes[owner]; exists && n.Rooms > 0 {
+		n.Rooms--
+	}
+	return nil
+}
+
+// liveLocked reports whether a node has heartbeated within the TTL
+func (d *MemoryDirectory) liveLocked(n *Node) bool {
+	return d.now().Sub(n.LastSeen) <= d.ttl
+}
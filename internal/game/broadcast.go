@@ -35,7 +35,7 @@ func (b *TransportBroadcaster) Broadcast(msg *gamepb.Message, excludeID string)
 
 	players := b.state.AllPlayers()
 	for _, p := range players {
-		if p.ID == excludeID {
+		if p.ID == excludeID || p.Disconnected {
 			continue
 		}
 		if err := b.send(p.Addr, data); err != nil {
@@ -52,4 +52,22 @@ func (b *TransportBroadcaster) SendTo(addr string, msg *gamepb.Message) error {
 		return err
 	}
 	return b.send(addr, data)
-}
\ No newline at end of file
+}
+
+// BroadcastTeam sends a message to all connected players on a team.
+func (b *TransportBroadcaster) BroadcastTeam(msg *gamepb.Message, team uint32, excludeID string) error {
+	data, err := protocol.Encode(msg)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range b.state.AllPlayers() {
+		if p.Team != team || p.ID == excludeID || p.Disconnected {
+			continue
+		}
+		if err := b.send(p.Addr, data); err != nil {
+			log.Printf("Broadcast error to %s: %v", p.Addr, err)
+		}
+	}
+	return nil
+}
@@ -12,7 +12,9 @@ type DeltaTracker struct {
 type playerSnapshot struct {
 	x, y     float32
 	vx, vy   float32
-	rotation float32
+	rotation  float32
+	team      uint32
+	lastInput uint64
 }
 
 // NewDeltaTracker creates a new delta tracker.
@@ -48,7 +50,9 @@ func (d *DeltaTracker) ComputeDelta(players []*Player, fullSync bool) (changed [
 			y:        p.Position.Y,
 			vx:       p.Velocity.X,
 			vy:       p.Velocity.Y,
-			rotation: 0,
+			rotation:  0,
+			team:      p.Team,
+			lastInput: p.LastInput,
 		}
 
 		last, exists := d.lastStates[p.ID]
@@ -60,6 +64,8 @@ func (d *DeltaTracker) ComputeDelta(players []*Player, fullSync bool) (changed [
 				Velocity:  p.Velocity,
 				Rotation:  0,
 				Timestamp: uint64(p.LastSeen.UnixMilli()),
+				Team:      p.Team,
+				LastInput: p.LastInput,
 			})
 			d.lastStates[p.ID] = snapshot
 		}
@@ -69,7 +75,8 @@ func (d *DeltaTracker) ComputeDelta(players []*Player, fullSync bool) (changed [
 }
 
 // hasChanged checks if player state has meaningfully changed.
-// Uses epsilon to avoid sending tiny movements.
+// Uses epsilon to avoid sending tiny movements. A newly processed input
+// always counts, so its sender hears it was applied.
 func (d *DeltaTracker) hasChanged(old, new *playerSnapshot) bool {
 	const epsilon = 0.1 // 0.1 units threshold
 
@@ -79,7 +86,7 @@ func (d *DeltaTracker) hasChanged(old, new *playerSnapshot) bool {
 	if abs(new.vx-old.vx) > epsilon || abs(new.vy-old.vy) > epsilon {
 		return true
 	}
-	return false
+	return new.team != old.team || new.lastInput != old.lastInput
 }
 
 func abs(x float32) float32 {
@@ -101,6 +108,8 @@ type PlayerState struct {
 	Velocity  Vec2
 	Rotation  float32
 	Timestamp uint64
+	Team      uint32
+	LastInput uint64 // Sequence of the last input applied
 }
 
 // ToProto converts PlayerState to protobuf.
@@ -111,5 +120,7 @@ func (p *PlayerState) ToProto() *gamepb.PlayerState {
 		Velocity: &gamepb.Vec2{X: p.Velocity.X, Y: p.Velocity.Y},
 		Rotation: p.Rotation,
 		Timestamp: p.Timestamp,
+		Team: p.Team,
+		LastInput: p.LastInput,
 	}
 }
\ No newline at end of file
@@ -24,6 +24,14 @@ type Engine struct {
 	stopCh       chan struct{}
 	wg           sync.WaitGroup
 	deltaTracker *DeltaTracker
+	validator    *InputValidator
+	onViolation  func(Violation)
+	reconnect    *ReconnectionManager
+	spectators   *SpectatorFeed
+	recorder     *Recorder
+	rules        TeamRules
+	visibleMu    sync.Mutex                 // Guards visible: the tick loop updates it, snapshots read it
+	visible      map[string]map[string]bool // Per viewer, when rules.CanSee is set
 }
 
 // NewEngine creates a new game engine.
@@ -35,6 +43,9 @@ func NewEngine(config Config, broadcaster Broadcaster) *Engine {
 		tickRate:     time.Second / time.Duration(config.TickRate),
 		stopCh:       make(chan struct{}),
 		deltaTracker: NewDeltaTracker(),
+		validator:    NewInputValidator(config.Validation),
+		reconnect:    NewReconnectionManager(config.ReconnectGrace),
+		spectators:   NewSpectatorFeed(config.SpectatorDelay),
 	}
 }
 
@@ -57,6 +68,9 @@ func (e *Engine) Stop() {
 	e.running = false
 	close(e.stopCh)
 	e.wg.Wait()
+	if e.recorder != nil {
+		e.recorder.keyframe(e.state.CurrentTick(), e.state.AllPlayers())
+	}
 	log.Println("🛑 Engine stopped")
 }
 
@@ -76,6 +90,7 @@ func (e *Engine) tickLoop() {
 			return
 		case <-ticker.C:
 			e.tick()
+			e.spectators.Flush(e.broadcaster, time.Now())
 		}
 
 		// Broadcast state periodically
@@ -91,7 +106,21 @@ func (e *Engine) tick() {
 	tick := e.state.Tick()
 
 	// Process all queued inputs
-	e.state.ProcessInputs()
+	if e.recorder != nil {
+		e.state.processInputs(func(playerID string, input Input) {
+			e.recorder.record(ReplayEvent{Tick: tick, Kind: EventInput, PlayerID: playerID, Input: input})
+		})
+		if tick%e.recorder.interval == 0 {
+			e.recorder.keyframe(tick, e.state.AllPlayers())
+		}
+	} else {
+		e.state.ProcessInputs()
+	}
+
+	// Drop players whose reconnect grace ran out (once per second)
+	if tick%uint64(e.config.TickRate) == 0 {
+		e.expireDisconnected()
+	}
 
 	// Future: Process AI, physics, collisions, etc.
 
@@ -100,7 +129,7 @@ func (e *Engine) tick() {
 
 // broadcastState sends state updates to all players using delta compression.
 func (e *Engine) broadcastState() {
-	players := e.state.AllPlayers()
+	players := e.state.PlayerCopies() // Inputs keep arriving while we work
 	if len(players) == 0 {
 		return
 	}
@@ -113,7 +142,24 @@ func (e *Engine) broadcastState() {
 		return
 	}
 
-	// Build delta message
+	// With a visibility rule each player gets their own view
+	if e.rules.CanSee != nil && e.broadcaster != nil {
+		views := e.broadcastVisible(e.state.CurrentTick(), players, changed, removed)
+		e.spectators.PushState(e.deltaMessage(changed, removed), views, time.Now())
+		return
+	}
+
+	msg := e.deltaMessage(changed, removed)
+
+	// Broadcast to all
+	if e.broadcaster != nil {
+		e.broadcaster.Broadcast(msg, "")
+	}
+	e.spectators.Push(msg, "", time.Now())
+}
+
+// deltaMessage builds the unfiltered state delta message.
+func (e *Engine) deltaMessage(changed []*PlayerState, removed []string) *gamepb.Message {
 	delta := &gamepb.GameStateDelta{
 		Tick:           e.state.CurrentTick(),
 		Timestamp:      uint64(time.Now().UnixMilli()),
@@ -125,16 +171,11 @@ func (e *Engine) broadcastState() {
 		delta.ChangedPlayers = append(delta.ChangedPlayers, p.ToProto())
 	}
 
-	msg := &gamepb.Message{
+	return &gamepb.Message{
 		Payload: &gamepb.Message_StateDelta{
 			StateDelta: delta,
 		},
 	}
-
-	// Broadcast to all
-	if e.broadcaster != nil {
-		e.broadcaster.Broadcast(msg, "")
-	}
 }
 
 // State returns the game state for external access.
@@ -163,12 +204,15 @@ func (e *Engine) AddPlayer(name, addr string) *Player {
 						PlayerId: player.ID,
 						Position: &gamepb.Vec2{X: player.Position.X, Y: player.Position.Y},
 						Velocity: &gamepb.Vec2{X: player.Velocity.X, Y: player.Velocity.Y},
+						Team:     player.Team,
 					},
 				},
 			},
 		}
 		e.broadcaster.Broadcast(msg, player.ID)
+		e.spectators.Push(msg, "", time.Now())
 	}
+	e.recordEvent(ReplayEvent{Kind: EventJoin, PlayerID: player.ID, Name: name})
 
 	log.Printf("✅ Player joined: %s (%s) at (%.1f, %.1f)", name, player.ID, player.Position.X, player.Position.Y)
 	return player
@@ -190,12 +234,15 @@ func (e *Engine) AddPlayerWithID(name, playerID, addr string) *Player {
 						PlayerId: player.ID,
 						Position: &gamepb.Vec2{X: player.Position.X, Y: player.Position.Y},
 						Velocity: &gamepb.Vec2{X: player.Velocity.X, Y: player.Velocity.Y},
+						Team:     player.Team,
 					},
 				},
 			},
 		}
 		e.broadcaster.Broadcast(msg, player.ID)
+		e.spectators.Push(msg, "", time.Now())
 	}
+	e.recordEvent(ReplayEvent{Kind: EventJoin, PlayerID: player.ID, Name: name})
 
 	log.Printf("✅ Player joined: %s (%s) at (%.1f, %.1f)", name, player.ID, player.Position.X, player.Position.Y)
 	return player
@@ -203,15 +250,27 @@ func (e *Engine) AddPlayerWithID(name, playerID, addr string) *Player {
 
 // RemovePlayer removes a player from the game.
 func (e *Engine) RemovePlayer(id string) {
+	e.RemovePlayerWithReason(id, "disconnect")
+}
+
+// RemovePlayerWithReason removes a player and tells others why.
+func (e *Engine) RemovePlayerWithReason(id, reason string) {
 	player := e.state.GetPlayer(id)
 	if player == nil {
 		return
 	}
 
 	e.state.RemovePlayer(id)
+	e.validator.Forget(id)
+	e.reconnect.Forget(id)
+	// Their followers go back to the full view
+	for _, spectatorID := range e.spectators.Unfollow(id) {
+		e.spectators.Push(e.snapshotMessage(), spectatorID, time.Now())
+	}
 
 	// Clear from delta tracker
 	delete(e.deltaTracker.lastStates, id)
+	e.recordEvent(ReplayEvent{Kind: EventLeave, PlayerID: id, Reason: reason})
 
 	// Notify others of leave
 	if e.broadcaster != nil {
@@ -219,19 +278,138 @@ func (e *Engine) RemovePlayer(id string) {
 			Payload: &gamepb.Message_PlayerLeave{
 				PlayerLeave: &gamepb.PlayerLeave{
 					PlayerId: id,
-					Reason:   "disconnect",
+					Reason:   reason,
 				},
 			},
 		}
 		e.broadcaster.Broadcast(msg, "")
+		e.spectators.Push(msg, "", time.Now())
+	}
+
+	log.Printf("❎ Player left: %s (%s): %s", player.Name, id, reason)
+}
+
+// ResumeToken issues (or rotates) the resume token for a player.
+// Send it in ServerWelcome so the client can reconnect later.
+func (e *Engine) ResumeToken(playerID string) string {
+	return e.reconnect.Issue(playerID)
+}
+
+// DisconnectPlayer freezes a player for the reconnect grace period.
+// If reconnection is disabled the player is removed immediately.
+func (e *Engine) DisconnectPlayer(id string) {
+	if !e.reconnect.Disconnect(id, time.Now()) {
+		e.RemovePlayer(id)
+		return
+	}
+	if e.state.FreezePlayer(id) {
+		e.recordEvent(ReplayEvent{Kind: EventFreeze, PlayerID: id})
+		log.Printf("⏸️  Player %s disconnected, holding for %v", id, e.reconnect.Grace())
+	}
+}
+
+// ResumePlayer rebinds the player owning token to addr.
+// Returns the player and a fresh resume token; callers should follow up
+// with SendFullSnapshot so the client catches up on missed state.
+func (e *Engine) ResumePlayer(token, addr string) (*Player, string, error) {
+	playerID, err := e.reconnect.Resume(token, time.Now())
+	if err != nil {
+		return nil, "", err
 	}
 
-	log.Printf("❎ Player left: %s (%s)", player.Name, id)
+	player := e.reattach(playerID, addr)
+	if player == nil {
+		return nil, "", ErrInvalidResumeToken
+	}
+	log.Printf("▶️  Player resumed: %s (%s) at %s", player.Name, player.ID, addr)
+	return player, e.reconnect.Issue(playerID), nil
 }
 
-// ApplyInput applies player input.
-func (e *Engine) ApplyInput(playerID string, input Input) {
-	e.state.ApplyInput(playerID, input)
+// ReattachPlayer unfreezes a disconnected player whose traffic resumed
+// from their known address. Returns nil if the player isn't frozen.
+func (e *Engine) ReattachPlayer(playerID, addr string) *Player {
+	if !e.reconnect.IsDisconnected(playerID) {
+		return nil
+	}
+	e.reconnect.Reattach(playerID)
+	return e.reattach(playerID, addr)
+}
+
+// reattach thaws a player and resets their validation history.
+func (e *Engine) reattach(playerID, addr string) *Player {
+	player := e.state.ThawPlayer(playerID, addr)
+	if player == nil {
+		return nil
+	}
+	e.validator.Forget(playerID)
+	e.recordEvent(ReplayEvent{Kind: EventThaw, PlayerID: playerID})
+	return player
+}
+
+// IsDisconnected returns true if the player is in their grace period.
+func (e *Engine) IsDisconnected(playerID string) bool {
+	return e.reconnect.IsDisconnected(playerID)
+}
+
+// expireDisconnected removes players whose grace period ran out.
+func (e *Engine) expireDisconnected() {
+	for _, id := range e.reconnect.Expired(time.Now()) {
+		e.RemovePlayerWithReason(id, "timeout")
+	}
+}
+
+// ApplyInput validates and queues player input.
+// Returns false if the input was rejected.
+func (e *Engine) ApplyInput(playerID string, input Input) bool {
+	if e.state.GetPlayer(playerID) == nil {
+		return false
+	}
+
+	violation, err := e.validator.Validate(playerID, &input, e.state.CurrentTick(), time.Now())
+	if violation != nil {
+		e.reportViolation(*violation)
+	}
+	if err != nil {
+		return false
+	}
+	return e.state.ApplyInput(playerID, input)
+}
+
+// OnViolation sets a callback for input validation violations.
+// The callback decides how to enforce the chosen Action (e.g. kick on ActionKick).
+func (e *Engine) OnViolation(callback func(Violation)) {
+	e.onViolation = callback
+}
+
+// Validator returns the engine's input validator.
+func (e *Engine) Validator() *InputValidator {
+	return e.validator
+}
+
+// reportViolation logs a violation and forwards it to the callback.
+func (e *Engine) reportViolation(v Violation) {
+	if v.Action >= ActionWarn {
+		log.Printf("🚨 Player %s violation: %s (%s) score=%.1f action=%s", v.PlayerID, v.Kind, v.Detail, v.Score, v.Action)
+	}
+	if e.onViolation != nil {
+		e.onViolation(v)
+	}
+}
+
+// Record starts writing the session to r, beginning with a keyframe of the
+// current state. Call before Start; Stop writes a closing keyframe.
+func (e *Engine) Record(r *Recorder) {
+	e.recorder = r
+	r.keyframe(e.state.CurrentTick(), e.state.AllPlayers())
+}
+
+// recordEvent stamps a lifecycle event with the current tick and records it.
+func (e *Engine) recordEvent(ev ReplayEvent) {
+	if e.recorder == nil {
+		return
+	}
+	ev.Tick = e.state.CurrentTick()
+	e.recorder.record(ev)
 }
 
 // GetPlayerByAddr finds a player by their UDP address.
@@ -247,7 +425,33 @@ func (e *Engine) PlayerCount() int {
 // SendFullSnapshot sends a complete state snapshot to a specific player.
 // Use when a player first joins.
 func (e *Engine) SendFullSnapshot(addr string) {
-	players := e.state.AllPlayers()
+	if e.broadcaster != nil {
+		e.broadcaster.SendTo(addr, e.snapshotMessage())
+	}
+}
+
+// SendViewSnapshot sends a player a snapshot of what they can see.
+// Use when a player joins or comes back.
+func (e *Engine) SendViewSnapshot(playerID, addr string) {
+	if e.broadcaster != nil {
+		e.broadcaster.SendTo(addr, e.viewSnapshot(playerID))
+	}
+}
+
+// snapshotMessage builds a full state snapshot message.
+func (e *Engine) snapshotMessage() *gamepb.Message {
+	return e.viewSnapshot("")
+}
+
+// viewSnapshot builds a snapshot of what a player currently sees, for
+// them or the spectators following them. An empty viewerID gives the full snapshot.
+// Safe to call from any goroutine.
+func (e *Engine) viewSnapshot(viewerID string) *gamepb.Message {
+	players := e.state.PlayerCopies()
+	var visible map[string]bool
+	if viewerID != "" && e.rules.CanSee != nil {
+		visible = e.visibleTo(viewerID, players)
+	}
 
 	snapshot := &gamepb.GameStateSnapshot{
 		Tick:      e.state.CurrentTick(),
@@ -256,22 +460,89 @@ func (e *Engine) SendFullSnapshot(addr string) {
 	}
 
 	for _, p := range players {
+		if visible != nil && !visible[p.ID] {
+			continue
+		}
 		snapshot.Players = append(snapshot.Players, &gamepb.PlayerState{
 			PlayerId: p.ID,
 			Position: &gamepb.Vec2{X: p.Position.X, Y: p.Position.Y},
 			Velocity: &gamepb.Vec2{X: p.Velocity.X, Y: p.Velocity.Y},
 			Rotation: 0,
 			Timestamp: uint64(p.LastSeen.UnixMilli()),
+			Team: p.Team,
+			LastInput: p.LastInput,
 		})
 	}
 
-	msg := &gamepb.Message{
+	return &gamepb.Message{
 		Payload: &gamepb.Message_StateSnapshot{
 			StateSnapshot: snapshot,
 		},
 	}
+}
 
-	if e.broadcaster != nil {
-		e.broadcaster.SendTo(addr, msg)
+// visibleTo returns the targets a viewer sees: what they were last sent,
+// or (before their first update) what the rules say
+func (e *Engine) visibleTo(viewerID string, players []*Player) map[string]bool {
+	e.visibleMu.Lock()
+	cached := e.visible[viewerID]
+	visible := make(map[string]bool, len(cached))
+	for id := range cached {
+		visible[id] = true
+	}
+	e.visibleMu.Unlock()
+	if cached != nil {
+		return visible
+	}
+
+	var viewer *Player
+	for _, p := range players {
+		if p.ID == viewerID {
+			viewer = p
+		}
+	}
+	for _, p := range players {
+		if p == viewer || (viewer != nil && e.rules.CanSee(viewer, p)) {
+			visible[p.ID] = true
+		}
+	}
+	return visible
+}
+
+// AddSpectator registers a spectator and queues their initial snapshot.
+// Returns nil if the ID belongs to a player.
+func (e *Engine) AddSpectator(id, addr string) *Spectator {
+	if e.state.GetPlayer(id) != nil {
+		return nil
 	}
-}
\ No newline at end of file
+
+	spectator := e.spectators.Add(id, addr)
+	e.spectators.Push(e.viewSnapshot(spectator.Following), id, time.Now())
+
+	log.Printf("👀 Spectator joined: %s (delay %v)", id, e.config.SpectatorDelay)
+	return spectator
+}
+
+// RemoveSpectator unregisters a spectator.
+func (e *Engine) RemoveSpectator(id string) {
+	e.spectators.Remove(id)
+}
+
+// FollowPlayer points a spectator at a player ("" to stop following).
+// From then on the spectator sees what that player sees, starting with
+// a snapshot of their view.
+func (e *Engine) FollowPlayer(spectatorID, playerID string) error {
+	if playerID != "" && e.state.GetPlayer(playerID) == nil {
+		return ErrPlayerNotFound
+	}
+	changed, err := e.spectators.follow(spectatorID, playerID)
+	if changed {
+		e.spectators.Push(e.viewSnapshot(playerID), spectatorID, time.Now())
+	}
+	return err
+}
+
+// Spectators returns the engine's spectator feed.
+func (e *Engine) Spectators() *SpectatorFeed {
+	return e.spectators
+}
@@ -173,6 +173,13 @@ func TestDeltaTracker(t *testing.T) {
 		t.Errorf("expected p1 to be changed, got %s", changed[0].ID)
 	}
 
+	// An applied input is news even when the player stands still
+	players[1].LastInput = 7
+	changed, removed = tracker.ComputeDelta(players, false)
+	if len(changed) != 1 || changed[0].ID != "p2" || changed[0].ToProto().LastInput != 7 {
+		t.Errorf("expected p2's input 7 in the delta, got %+v", changed)
+	}
+
 	// Remove player 2
 	players = players[:1]
 	changed, removed = tracker.ComputeDelta(players, false)
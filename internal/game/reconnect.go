@@ -0,0 +1,154 @@
+package game
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Reconnection errors.
+var (
+	ErrInvalidResumeToken = errors.New("invalid resume token")
+	ErrResumeExpired      = errors.New("resume grace period expired")
+)
+
+// Session tracks a player's resume token and disconnect state.
+type Session struct {
+	PlayerID       string
+	Token          string
+	Disconnected   bool
+	DisconnectedAt time.Time
+}
+
+// ReconnectionManager keeps disconnected players around for a grace period
+// so they can resume with a token instead of rejoining from scratch.
+type ReconnectionManager struct {
+	mu       sync.Mutex
+	grace    time.Duration
+	sessions map[string]*Session // playerID -> session
+	tokens   map[string]string   // token -> playerID
+}
+
+// NewReconnectionManager creates a reconnection manager.
+// A zero grace period disables resuming after disconnect.
+func NewReconnectionManager(grace time.Duration) *ReconnectionManager {
+	return &ReconnectionManager{
+		grace:    grace,
+		sessions: make(map[string]*Session),
+		tokens:   make(map[string]string),
+	}
+}
+
+// Grace returns the configured grace period.
+func (r *ReconnectionManager) Grace() time.Duration {
+	return r.grace
+}
+
+// Issue creates (or rotates) the resume token for a player.
+func (r *ReconnectionManager) Issue(playerID string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.sessions[playerID]
+	if !ok {
+		s = &Session{PlayerID: playerID}
+		r.sessions[playerID] = s
+	} else {
+		delete(r.tokens, s.Token)
+	}
+
+	s.Token = generateToken()
+	r.tokens[s.Token] = playerID
+	return s.Token
+}
+
+// Disconnect starts the grace period for a player.
+// Returns false if the player has no session or grace is disabled.
+func (r *ReconnectionManager) Disconnect(playerID string, now time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.sessions[playerID]
+	if !ok || r.grace <= 0 {
+		return false
+	}
+	if !s.Disconnected {
+		s.Disconnected = true
+		s.DisconnectedAt = now
+	}
+	return true
+}
+
+// Resume validates a token and returns the player it belongs to.
+// The player's session is marked connected again.
+func (r *ReconnectionManager) Resume(token string, now time.Time) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	playerID, ok := r.tokens[token]
+	if !ok {
+		return "", ErrInvalidResumeToken
+	}
+	s := r.sessions[playerID]
+	if s.Disconnected && now.Sub(s.DisconnectedAt) > r.grace {
+		return "", ErrResumeExpired
+	}
+	s.Disconnected = false
+	return playerID, nil
+}
+
+// Reattach marks a player connected again without a token
+// (e.g. traffic resumed from the same address).
+func (r *ReconnectionManager) Reattach(playerID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if s, ok := r.sessions[playerID]; ok {
+		s.Disconnected = false
+	}
+}
+
+// IsDisconnected returns true if the player is within their grace period.
+func (r *ReconnectionManager) IsDisconnected(playerID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.sessions[playerID]
+	return ok && s.Disconnected
+}
+
+// Expired returns and forgets all players whose grace period has run out.
+func (r *ReconnectionManager) Expired(now time.Time) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var expired []string
+	for id, s := range r.sessions {
+		if s.Disconnected && now.Sub(s.DisconnectedAt) > r.grace {
+			expired = append(expired, id)
+			delete(r.tokens, s.Token)
+			delete(r.sessions, id)
+		}
+	}
+	return expired
+}
+
+// Forget drops a player's session and token.
+func (r *ReconnectionManager) Forget(playerID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if s, ok := r.sessions[playerID]; ok {
+		delete(r.tokens, s.Token)
+		delete(r.sessions, playerID)
+	}
+}
+
+// generateToken creates a random URL-safe token.
+func generateToken() string {
+	b := make([]byte, 24)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
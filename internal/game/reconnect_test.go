@@ -0,0 +1,96 @@
+package game
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReconnectionManagerResume(t *testing.T) {
+	r := NewReconnectionManager(30 * time.Second)
+	now := time.Now()
+
+	token := r.Issue("p1")
+	if !r.Disconnect("p1", now) {
+		t.Fatal("expected disconnect to start grace period")
+	}
+
+	playerID, err := r.Resume(token, now.Add(10*time.Second))
+	if err != nil {
+		t.Fatalf("resume failed: %v", err)
+	}
+	if playerID != "p1" {
+		t.Errorf("expected p1, got %s", playerID)
+	}
+	if r.IsDisconnected("p1") {
+		t.Error("expected player connected after resume")
+	}
+
+	if _, err := r.Resume("bogus", now); err != ErrInvalidResumeToken {
+		t.Errorf("expected ErrInvalidResumeToken, got %v", err)
+	}
+}
+
+func TestReconnectionManagerExpiry(t *testing.T) {
+	r := NewReconnectionManager(30 * time.Second)
+	now := time.Now()
+
+	token := r.Issue("p1")
+	r.Disconnect("p1", now)
+
+	if _, err := r.Resume(token, now.Add(time.Minute)); err != ErrResumeExpired {
+		t.Errorf("expected ErrResumeExpired, got %v", err)
+	}
+
+	expired := r.Expired(now.Add(time.Minute))
+	if len(expired) != 1 || expired[0] != "p1" {
+		t.Errorf("expected [p1] expired, got %v", expired)
+	}
+	if _, err := r.Resume(token, now); err != ErrInvalidResumeToken {
+		t.Errorf("expected token forgotten after expiry, got %v", err)
+	}
+}
+
+func TestEngineDisconnectAndResume(t *testing.T) {
+	broadcaster := &mockBroadcaster{}
+	engine := NewEngine(DefaultConfig(), broadcaster)
+
+	player := engine.AddPlayer("P1", "127.0.0.1:1234")
+	player.Position.X = 123
+	token := engine.ResumeToken(player.ID)
+
+	engine.DisconnectPlayer(player.ID)
+	if engine.PlayerCount() != 1 {
+		t.Fatal("expected player kept during grace period")
+	}
+	if engine.ApplyInput(player.ID, Input{Sequence: 1, Movement: Vec2{X: 1}}) {
+		t.Error("expected input rejected while disconnected")
+	}
+
+	resumed, newToken, err := engine.ResumePlayer(token, "127.0.0.1:5678")
+	if err != nil {
+		t.Fatalf("resume failed: %v", err)
+	}
+	if resumed.Addr != "127.0.0.1:5678" {
+		t.Errorf("expected new address, got %s", resumed.Addr)
+	}
+	if resumed.Position.X != 123 {
+		t.Errorf("expected position restored, got %.1f", resumed.Position.X)
+	}
+	if newToken == "" || newToken == token {
+		t.Error("expected resume token to rotate")
+	}
+}
+
+func TestEngineDisconnectWithoutGrace(t *testing.T) {
+	config := DefaultConfig()
+	config.ReconnectGrace = 0
+	engine := NewEngine(config, &mockBroadcaster{})
+
+	player := engine.AddPlayer("P1", "127.0.0.1:1234")
+	engine.ResumeToken(player.ID)
+	engine.DisconnectPlayer(player.ID)
+
+	if engine.PlayerCount() != 0 {
+		t.Error("expected player removed immediately without grace period")
+	}
+}
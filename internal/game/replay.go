@@ -0,0 +1,513 @@
+package game
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/LemmyAI/gameserver/internal/protocol/gamepb"
+)
+
+// ReplayVersion is the current replay file format version.
+const ReplayVersion = 1
+
+// DefaultKeyframeSeconds is how often the recorder writes a full snapshot.
+const DefaultKeyframeSeconds = 5
+
+// Replay errors.
+var (
+	ErrReplayVersion = errors.New("unsupported replay version")
+	ErrReplayEmpty   = errors.New("replay has no keyframes")
+)
+
+// ReplayEventKind identifies a replay record.
+type ReplayEventKind uint8
+
+const (
+	EventJoin ReplayEventKind = iota + 1
+	EventLeave
+	EventFreeze
+	EventThaw
+	EventInput
+	EventKeyframe
+	EventTeam
+)
+
+// String returns the event kind name.
+func (k ReplayEventKind) String() string {
+	switch k {
+	case EventJoin:
+		return "join"
+	case EventLeave:
+		return "leave"
+	case EventFreeze:
+		return "freeze"
+	case EventThaw:
+		return "thaw"
+	case EventInput:
+		return "input"
+	case EventKeyframe:
+		return "keyframe"
+	case EventTeam:
+		return "team"
+	default:
+		return "unknown"
+	}
+}
+
+// ReplayConfig is the subset of Config that affects simulation.
+type ReplayConfig struct {
+	TickRate    int
+	MaxPlayers  int
+	PlayerSpeed float32
+	WorldWidth  float32
+	WorldHeight float32
+}
+
+// ReplayHeader starts every replay file.
+type ReplayHeader struct {
+	Version          int
+	RecordedAt       time.Time
+	Config           ReplayConfig
+	KeyframeInterval uint64 // Ticks between keyframes
+}
+
+// PlayerFrame is one player's state inside a keyframe.
+type PlayerFrame struct {
+	ID           string
+	Name         string
+	Position     Vec2
+	Velocity     Vec2
+	LastInput    uint64
+	Disconnected bool
+	Team         uint32
+}
+
+// ReplayEvent is a single replay record.
+//
+// Lifecycle events (join/leave/freeze/thaw/team) carry the tick they happened
+// after; inputs and keyframes carry the tick they were processed in.
+type ReplayEvent struct {
+	Tick     uint64
+	Kind     ReplayEventKind
+	PlayerID string
+	Name     string        // Join
+	Reason   string        // Leave
+	Input    Input         // Input
+	Team     uint32        // Team
+	Players  []PlayerFrame // Keyframe
+}
+
+// Recorder writes a replay of an engine's session as gzipped gob records.
+type Recorder struct {
+	mu       sync.Mutex
+	gz       *gzip.Writer
+	enc      *gob.Encoder
+	interval uint64
+	events   int
+	err      error
+}
+
+// NewRecorder writes a replay header for config to w.
+// Attach it with Engine.Record and call Close when done.
+func NewRecorder(w io.Writer, config Config) (*Recorder, error) {
+	gz := gzip.NewWriter(w)
+	r := &Recorder{
+		gz:       gz,
+		enc:      gob.NewEncoder(gz),
+		interval: uint64(config.TickRate * DefaultKeyframeSeconds),
+	}
+	if r.interval == 0 {
+		r.interval = 1
+	}
+
+	header := ReplayHeader{
+		Version:    ReplayVersion,
+		RecordedAt: time.Now(),
+		Config: ReplayConfig{
+			TickRate:    config.TickRate,
+			MaxPlayers:  config.MaxPlayers,
+			PlayerSpeed: config.PlayerSpeed,
+			WorldWidth:  config.WorldWidth,
+			WorldHeight: config.WorldHeight,
+		},
+		KeyframeInterval: r.interval,
+	}
+	if err := r.enc.Encode(&header); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Close flushes the replay. It does not close the underlying writer.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.gz.Close(); err != nil && r.err == nil {
+		r.err = err
+	}
+	return r.err
+}
+
+// Events returns how many records have been written.
+func (r *Recorder) Events() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.events
+}
+
+// Err returns the first write error, if any.
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// record writes an event. After the first error recording stops.
+func (r *Recorder) record(ev ReplayEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err != nil {
+		return
+	}
+	if err := r.enc.Encode(&ev); err != nil {
+		r.err = err
+		log.Printf("❌ Replay recording stopped: %v", err)
+		return
+	}
+	r.events++
+}
+
+// keyframe records a full snapshot of the players and flushes, so a
+// crashed server still leaves a replay up to the last keyframe.
+func (r *Recorder) keyframe(tick uint64, players []*Player) {
+	r.record(ReplayEvent{Tick: tick, Kind: EventKeyframe, Players: framePlayers(players)})
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err == nil {
+		r.err = r.gz.Flush()
+	}
+}
+
+// framePlayers copies the replay-relevant state of each player.
+func framePlayers(players []*Player) []PlayerFrame {
+	frames := make([]PlayerFrame, 0, len(players))
+	for _, p := range players {
+		frames = append(frames, PlayerFrame{
+			ID:           p.ID,
+			Name:         p.Name,
+			Position:     p.Position,
+			Velocity:     p.Velocity,
+			LastInput:    p.LastInput,
+			Disconnected: p.Disconnected,
+			Team:         p.Team,
+		})
+	}
+	// Stable order keeps replay files reproducible
+	sort.Slice(frames, func(i, j int) bool { return frames[i].ID < frames[j].ID })
+	return frames
+}
+
+// replayTick groups the records that apply to one tick.
+type replayTick struct {
+	lifecycle []ReplayEvent
+	inputs    []ReplayEvent
+}
+
+// Replay is a fully loaded replay file.
+type Replay struct {
+	Header    ReplayHeader
+	Events    []ReplayEvent
+	keyframes []int // Indexes into Events, in tick order
+	ticks     map[uint64]*replayTick
+	lastTick  uint64
+}
+
+// ReadReplay loads a replay written by a Recorder.
+func ReadReplay(r io.Reader) (*Replay, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	dec := gob.NewDecoder(gz)
+	replay := &Replay{ticks: make(map[uint64]*replayTick)}
+	if err := dec.Decode(&replay.Header); err != nil {
+		return nil, fmt.Errorf("read replay header: %w", err)
+	}
+	if replay.Header.Version != ReplayVersion {
+		return nil, fmt.Errorf("%w: %d", ErrReplayVersion, replay.Header.Version)
+	}
+
+	for {
+		var ev ReplayEvent
+		if err := dec.Decode(&ev); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				break // A crashed server leaves a truncated but usable replay
+			}
+			return nil, fmt.Errorf("read replay event %d: %w", len(replay.Events), err)
+		}
+		replay.add(ev)
+	}
+
+	if len(replay.keyframes) == 0 {
+		return nil, ErrReplayEmpty
+	}
+	return replay, nil
+}
+
+// add indexes an event.
+func (r *Replay) add(ev ReplayEvent) {
+	r.Events = append(r.Events, ev)
+	if ev.Tick > r.lastTick {
+		r.lastTick = ev.Tick
+	}
+
+	if ev.Kind == EventKeyframe {
+		r.keyframes = append(r.keyframes, len(r.Events)-1)
+		return
+	}
+
+	t, ok := r.ticks[ev.Tick]
+	if !ok {
+		t = &replayTick{}
+		r.ticks[ev.Tick] = t
+	}
+	if ev.Kind == EventInput {
+		t.inputs = append(t.inputs, ev)
+	} else {
+		t.lifecycle = append(t.lifecycle, ev)
+	}
+}
+
+// StartTick returns the first tick the replay can show.
+func (r *Replay) StartTick() uint64 {
+	return r.Events[r.keyframes[0]].Tick
+}
+
+// EndTick returns the last recorded tick.
+func (r *Replay) EndTick() uint64 {
+	return r.lastTick
+}
+
+// Duration returns the recorded game time.
+func (r *Replay) Duration() time.Duration {
+	ticks := r.EndTick() - r.StartTick()
+	return time.Duration(ticks) * time.Second / time.Duration(r.Header.Config.TickRate)
+}
+
+// KeyframeTicks returns the tick of every keyframe.
+func (r *Replay) KeyframeTicks() []uint64 {
+	ticks := make([]uint64, len(r.keyframes))
+	for i, idx := range r.keyframes {
+		ticks[i] = r.Events[idx].Tick
+	}
+	return ticks
+}
+
+// Config returns an engine config that reproduces the recorded simulation.
+func (r *Replay) Config() Config {
+	config := DefaultConfig()
+	config.TickRate = r.Header.Config.TickRate
+	config.MaxPlayers = r.Header.Config.MaxPlayers
+	config.PlayerSpeed = r.Header.Config.PlayerSpeed
+	config.WorldWidth = r.Header.Config.WorldWidth
+	config.WorldHeight = r.Header.Config.WorldHeight
+	config.ReconnectGrace = 0 // Leaves are recorded explicitly
+	return config
+}
+
+// keyframeAt returns the last keyframe at or before tick (the first if none).
+func (r *Replay) keyframeAt(tick uint64) *ReplayEvent {
+	i := sort.Search(len(r.keyframes), func(i int) bool {
+		return r.Events[r.keyframes[i]].Tick > tick
+	})
+	if i > 0 {
+		i--
+	}
+	return &r.Events[r.keyframes[i]]
+}
+
+// ghostBroadcaster only delivers targeted sends; replayed players have no
+// address, so broadcasts to them are dropped.
+type ghostBroadcaster struct {
+	b Broadcaster
+}
+
+func (g ghostBroadcaster) Broadcast(msg *gamepb.Message, excludeID string) error {
+	return nil
+}
+
+func (g ghostBroadcaster) SendTo(addr string, msg *gamepb.Message) error {
+	if g.b == nil {
+		return nil
+	}
+	return g.b.SendTo(addr, msg)
+}
+
+// ReplayPlayer re-simulates a replay through a game Engine.
+// Spectators added to Engine() receive the replayed state.
+type ReplayPlayer struct {
+	replay *Replay
+	engine *Engine
+}
+
+// NewReplayPlayer creates a player positioned at the replay's first keyframe.
+// broadcaster may be nil if nobody is watching.
+func NewReplayPlayer(replay *Replay, broadcaster Broadcaster) *ReplayPlayer {
+	p := &ReplayPlayer{
+		replay: replay,
+		engine: NewEngine(replay.Config(), ghostBroadcaster{broadcaster}),
+	}
+	p.Seek(replay.StartTick())
+	return p
+}
+
+// Engine returns the engine driving playback.
+func (p *ReplayPlayer) Engine() *Engine {
+	return p.engine
+}
+
+// Replay returns the replay being played.
+func (p *ReplayPlayer) Replay() *Replay {
+	return p.replay
+}
+
+// Tick returns the current playback tick.
+func (p *ReplayPlayer) Tick() uint64 {
+	return p.engine.CurrentTick()
+}
+
+// Done returns true once the last recorded tick has been simulated.
+func (p *ReplayPlayer) Done() bool {
+	return p.Tick() >= p.replay.EndTick()
+}
+
+// Seek jumps to tick by restoring the nearest earlier keyframe and
+// re-simulating forward. Spectators get a fresh snapshot.
+func (p *ReplayPlayer) Seek(tick uint64) {
+	if tick > p.replay.EndTick() {
+		tick = p.replay.EndTick()
+	}
+
+	kf := p.replay.keyframeAt(tick)
+	players := make([]*Player, 0, len(kf.Players))
+	for _, f := range kf.Players {
+		players = append(players, &Player{
+			ID:           f.ID,
+			Name:         f.Name,
+			Position:     f.Position,
+			Velocity:     f.Velocity,
+			LastInput:    f.LastInput,
+			Disconnected: f.Disconnected,
+			Team:         f.Team,
+			InputQueue:   make([]Input, 0, 16),
+		})
+	}
+	p.engine.state.restore(kf.Tick, players)
+
+	for p.Tick() < tick {
+		p.Step()
+	}
+
+	// Spectators can't apply deltas across a jump
+	p.engine.deltaTracker = NewDeltaTracker()
+	for _, s := range p.engine.spectators.All() {
+		p.engine.spectators.Push(p.engine.viewSnapshot(s.Following), s.ID, time.Now())
+	}
+}
+
+// Step simulates one tick. Returns false once the replay has ended.
+func (p *ReplayPlayer) Step() bool {
+	if p.Done() {
+		return false
+	}
+
+	state := p.engine.state
+	current := state.CurrentTick()
+
+	// Lifecycle events that happened after the current tick
+	if t := p.replay.ticks[current]; t != nil {
+		for _, ev := range t.lifecycle {
+			p.apply(ev)
+		}
+	}
+
+	next := state.Tick()
+	if t := p.replay.ticks[next]; t != nil {
+		for _, ev := range t.inputs {
+			state.queueInput(ev.PlayerID, ev.Input)
+		}
+	}
+	state.ProcessInputs()
+	return true
+}
+
+// apply replays a lifecycle event. Events are idempotent so replaying one
+// already captured by a keyframe is harmless.
+func (p *ReplayPlayer) apply(ev ReplayEvent) {
+	state := p.engine.state
+	switch ev.Kind {
+	case EventJoin:
+		state.AddPlayerWithID(ev.Name, ev.PlayerID, "")
+	case EventLeave:
+		state.RemovePlayer(ev.PlayerID)
+		for _, spectatorID := range p.engine.spectators.Unfollow(ev.PlayerID) {
+			p.engine.spectators.Push(p.engine.snapshotMessage(), spectatorID, time.Now())
+		}
+	case EventFreeze:
+		state.FreezePlayer(ev.PlayerID)
+	case EventThaw:
+		state.ThawPlayer(ev.PlayerID, "")
+	case EventTeam:
+		state.SetTeam(ev.PlayerID, ev.Team)
+	}
+}
+
+// Play streams the replay in real time (scaled by speed) until it ends or
+// ctx is cancelled. State goes out to spectators like a live game.
+func (p *ReplayPlayer) Play(ctx context.Context, speed float64) error {
+	if speed <= 0 {
+		speed = 1
+	}
+	tickRate := p.engine.config.TickRate
+	interval := time.Duration(float64(time.Second) / float64(tickRate) / speed)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// 20 Hz of game time, like the live engine
+	broadcastEvery := uint64(tickRate / 20)
+	if broadcastEvery == 0 {
+		broadcastEvery = 1
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		if !p.Step() {
+			p.engine.broadcastState()
+			p.engine.spectators.Flush(p.engine.broadcaster, time.Now())
+			return nil
+		}
+		if p.Tick()%broadcastEvery == 0 {
+			p.engine.broadcastState()
+		}
+		p.engine.spectators.Flush(p.engine.broadcaster, time.Now())
+	}
+}
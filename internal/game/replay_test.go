@@ -0,0 +1,107 @@
+package game
+
+import (
+	"bytes"
+	"testing"
+)
+
+// recordSession plays a short scripted game into buf and returns the
+// final player positions.
+func recordSession(t *testing.T, buf *bytes.Buffer) map[string]Vec2 {
+	t.Helper()
+
+	config := DefaultConfig()
+	engine := NewEngine(config, &mockBroadcaster{})
+
+	recorder, err := NewRecorder(buf, config)
+	if err != nil {
+		t.Fatalf("new recorder: %v", err)
+	}
+	engine.Record(recorder)
+
+	p1 := engine.AddPlayerWithID("P1", "p1", "127.0.0.1:1")
+	p2 := engine.AddPlayerWithID("P2", "p2", "127.0.0.1:2")
+
+	for i := 1; i <= 700; i++ {
+		engine.state.ApplyInput(p1.ID, Input{Sequence: uint64(i), Movement: Vec2{X: 1}})
+		if i <= 300 {
+			engine.state.ApplyInput(p2.ID, Input{Sequence: uint64(i), Movement: Vec2{Y: -1}})
+		}
+		if i == 300 {
+			engine.RemovePlayer(p2.ID)
+		}
+		engine.tick()
+	}
+
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("close recorder: %v", err)
+	}
+
+	final := make(map[string]Vec2)
+	for _, p := range engine.state.AllPlayers() {
+		final[p.ID] = p.Position
+	}
+	return final
+}
+
+func TestReplayReproducesSession(t *testing.T) {
+	var buf bytes.Buffer
+	want := recordSession(t, &buf)
+
+	replay, err := ReadReplay(&buf)
+	if err != nil {
+		t.Fatalf("read replay: %v", err)
+	}
+	if replay.EndTick() != 700 {
+		t.Errorf("expected end tick 700, got %d", replay.EndTick())
+	}
+	if len(replay.KeyframeTicks()) < 2 {
+		t.Errorf("expected periodic keyframes, got %v", replay.KeyframeTicks())
+	}
+
+	player := NewReplayPlayer(replay, nil)
+	for player.Step() {
+	}
+
+	players := player.Engine().State().AllPlayers()
+	if len(players) != len(want) {
+		t.Fatalf("expected %d players, got %d", len(want), len(players))
+	}
+	for _, p := range players {
+		if p.Position != want[p.ID] {
+			t.Errorf("player %s: expected %+v, got %+v", p.ID, want[p.ID], p.Position)
+		}
+	}
+}
+
+func TestReplaySeek(t *testing.T) {
+	var buf bytes.Buffer
+	recordSession(t, &buf)
+
+	replay, err := ReadReplay(&buf)
+	if err != nil {
+		t.Fatalf("read replay: %v", err)
+	}
+
+	// Reference: simulate straight through to tick 200
+	reference := NewReplayPlayer(replay, nil)
+	for reference.Tick() < 200 {
+		reference.Step()
+	}
+	want := reference.Engine().State().GetPlayer("p2").Position
+
+	player := NewReplayPlayer(replay, nil)
+	player.Seek(600)
+	if player.Engine().State().GetPlayer("p2") != nil {
+		t.Error("expected p2 gone at tick 600")
+	}
+
+	player.Seek(200)
+	if player.Tick() != 200 {
+		t.Fatalf("expected tick 200, got %d", player.Tick())
+	}
+	got := player.Engine().State().GetPlayer("p2")
+	if got == nil || got.Position != want {
+		t.Errorf("expected p2 at %+v after seek, got %+v", want, got)
+	}
+}
@@ -0,0 +1,211 @@
+package game
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/LemmyAI/gameserver/internal/protocol/gamepb"
+)
+
+// Spectator errors.
+var (
+	ErrSpectatorNotFound = errors.New("spectator not found")
+	ErrPlayerNotFound    = errors.New("player not found")
+)
+
+// Spectator receives game state but never sends inputs or takes a player slot.
+type Spectator struct {
+	ID        string
+	Addr      string
+	Following string // Player ID the spectator's camera follows
+	JoinedAt  time.Time
+}
+
+// spectatorFrame is a message waiting out the spectator delay.
+type spectatorFrame struct {
+	releaseAt time.Time
+	createdAt time.Time
+	to        string // Spectator ID, or "" for all spectators
+	msg       *gamepb.Message
+
+	// What each player saw of a state update, for spectators following
+	// them; nil when every player sees msg. A nil view means nothing
+	// changed for that player.
+	views map[string]*gamepb.Message
+}
+
+// SpectatorFeed fans state out to spectators, optionally delayed to
+// prevent ghosting (spectators relaying live positions to players).
+type SpectatorFeed struct {
+	mu         sync.Mutex
+	delay      time.Duration
+	spectators map[string]*Spectator
+	queue      []spectatorFrame
+}
+
+// NewSpectatorFeed creates a spectator feed with the given delay.
+func NewSpectatorFeed(delay time.Duration) *SpectatorFeed {
+	return &SpectatorFeed{
+		delay:      delay,
+		spectators: make(map[string]*Spectator),
+	}
+}
+
+// Add registers a spectator. Re-adding an existing ID updates its address.
+func (f *SpectatorFeed) Add(id, addr string) *Spectator {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if s, ok := f.spectators[id]; ok {
+		s.Addr = addr
+		return s
+	}
+	s := &Spectator{ID: id, Addr: addr, JoinedAt: time.Now()}
+	f.spectators[id] = s
+	return s
+}
+
+// Remove unregisters a spectator.
+func (f *SpectatorFeed) Remove(id string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.spectators, id)
+}
+
+// Get returns a spectator by ID.
+func (f *SpectatorFeed) Get(id string) *Spectator {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.spectators[id]
+}
+
+// Follow sets which player a spectator follows ("" to stop).
+func (f *SpectatorFeed) Follow(id, playerID string) error {
+	_, err := f.follow(id, playerID)
+	return err
+}
+
+// follow is Follow that also reports whether the target changed.
+func (f *SpectatorFeed) follow(id, playerID string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	s, ok := f.spectators[id]
+	if !ok {
+		return false, ErrSpectatorNotFound
+	}
+	changed := s.Following != playerID
+	s.Following = playerID
+	return changed, nil
+}
+
+// Unfollow clears every spectator following playerID and returns their IDs.
+func (f *SpectatorFeed) Unfollow(playerID string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var ids []string
+	for _, s := range f.spectators {
+		if s.Following == playerID {
+			s.Following = ""
+			ids = append(ids, s.ID)
+		}
+	}
+	return ids
+}
+
+// Count returns the number of spectators.
+func (f *SpectatorFeed) Count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.spectators)
+}
+
+// All returns a copy of all spectators.
+func (f *SpectatorFeed) All() []Spectator {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	all := make([]Spectator, 0, len(f.spectators))
+	for _, s := range f.spectators {
+		all = append(all, *s)
+	}
+	return all
+}
+
+// Push queues a message for all spectators (to == "") or one spectator.
+func (f *SpectatorFeed) Push(msg *gamepb.Message, to string, now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.spectators) == 0 {
+		return
+	}
+	f.queue = append(f.queue, spectatorFrame{
+		releaseAt: now.Add(f.delay),
+		createdAt: now,
+		to:        to,
+		msg:       msg,
+	})
+}
+
+// PushState queues a state update for all spectators. Spectators following
+// a player in views get that player's view instead of msg.
+func (f *SpectatorFeed) PushState(msg *gamepb.Message, views map[string]*gamepb.Message, now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.spectators) == 0 {
+		return
+	}
+	f.queue = append(f.queue, spectatorFrame{
+		releaseAt: now.Add(f.delay),
+		createdAt: now,
+		msg:       msg,
+		views:     views,
+	})
+}
+
+// Flush sends every message whose delay has elapsed.
+func (f *SpectatorFeed) Flush(b Broadcaster, now time.Time) {
+	f.mu.Lock()
+	n := 0
+	for n < len(f.queue) && !f.queue[n].releaseAt.After(now) {
+		n++
+	}
+	ready := append([]spectatorFrame(nil), f.queue[:n]...)
+	f.queue = f.queue[n:]
+
+	type delivery struct {
+		addr string
+		msg  *gamepb.Message
+	}
+	var out []delivery
+	for _, frame := range ready {
+		for _, s := range f.spectators {
+			// Broadcasts from before a spectator joined predate their snapshot
+			if frame.to == "" && frame.createdAt.Before(s.JoinedAt) {
+				continue
+			}
+			if frame.to != "" && frame.to != s.ID {
+				continue
+			}
+			msg := frame.msg
+			if view, ok := frame.views[s.Following]; ok && s.Following != "" {
+				msg = view
+			}
+			if msg != nil {
+				out = append(out, delivery{s.Addr, msg})
+			}
+		}
+	}
+	f.mu.Unlock()
+
+	if b == nil {
+		return
+	}
+	for _, d := range out {
+		b.SendTo(d.addr, d.msg)
+	}
+}
@@ -0,0 +1,121 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/LemmyAI/gameserver/internal/protocol/gamepb"
+)
+
+func TestSpectatorFeedDelay(t *testing.T) {
+	feed := NewSpectatorFeed(2 * time.Second)
+	broadcaster := &mockBroadcaster{}
+	feed.Add("s1", "127.0.0.1:9000")
+
+	now := time.Now()
+	feed.Push(&gamepb.Message{}, "", now)
+
+	feed.Flush(broadcaster, now.Add(time.Second))
+	if len(broadcaster.sent) != 0 {
+		t.Fatalf("expected no messages before delay, got %d", len(broadcaster.sent))
+	}
+
+	feed.Flush(broadcaster, now.Add(2*time.Second))
+	if len(broadcaster.sent) != 1 {
+		t.Fatalf("expected 1 message after delay, got %d", len(broadcaster.sent))
+	}
+}
+
+func TestSpectatorFeedSkipsEarlierBroadcasts(t *testing.T) {
+	feed := NewSpectatorFeed(time.Second)
+	broadcaster := &mockBroadcaster{}
+	feed.Add("s1", "127.0.0.1:9000")
+
+	before := time.Now().Add(-time.Second)
+	feed.Push(&gamepb.Message{}, "", before)
+	feed.Flush(broadcaster, before.Add(time.Minute))
+
+	if len(broadcaster.sent) != 0 {
+		t.Errorf("expected broadcast from before join skipped, got %d", len(broadcaster.sent))
+	}
+}
+
+func TestEngineSpectators(t *testing.T) {
+	engine := NewEngine(DefaultConfig(), &mockBroadcaster{})
+	player := engine.AddPlayer("P1", "127.0.0.1:1234")
+
+	if engine.AddSpectator(player.ID, "127.0.0.1:9000") != nil {
+		t.Error("expected player ID rejected as spectator")
+	}
+
+	spectator := engine.AddSpectator("s1", "127.0.0.1:9000")
+	if spectator == nil {
+		t.Fatal("expected spectator added")
+	}
+	if engine.PlayerCount() != 1 {
+		t.Errorf("expected spectator not to take a player slot, got %d players", engine.PlayerCount())
+	}
+
+	if err := engine.FollowPlayer("s1", "nobody"); err != ErrPlayerNotFound {
+		t.Errorf("expected ErrPlayerNotFound, got %v", err)
+	}
+	if err := engine.FollowPlayer("s1", player.ID); err != nil {
+		t.Fatalf("follow failed: %v", err)
+	}
+
+	engine.RemovePlayer(player.ID)
+	if got := engine.Spectators().Get("s1").Following; got != "" {
+		t.Errorf("expected follow cleared when player leaves, got %q", got)
+	}
+}
+
+func TestFollowingSpectatorSeesPlayersView(t *testing.T) {
+	broadcaster := &mockBroadcaster{}
+	engine := NewEngine(DefaultConfig(), broadcaster)
+	engine.SetTeamRules(TeamRules{CanSee: TeammatesOrWithin(50)})
+
+	a := engine.AddPlayer("A", "127.0.0.1:1")
+	b := engine.AddPlayer("B", "127.0.0.1:2")
+	a.Position = Vec2{X: 0, Y: 0}
+	b.Position = Vec2{X: 500, Y: 0}
+	engine.SetTeam(a.ID, 1)
+	engine.SetTeam(b.ID, 2)
+	engine.broadcastState()
+
+	engine.AddSpectator("free", "127.0.0.1:9000")
+	engine.AddSpectator("follower", "127.0.0.1:9001")
+	if err := engine.FollowPlayer("follower", a.ID); err != nil {
+		t.Fatalf("follow failed: %v", err)
+	}
+	engine.Spectators().Flush(broadcaster, time.Now())
+
+	var freeSnap, followSnap *gamepb.GameStateSnapshot
+	for _, s := range broadcaster.sent {
+		switch s.addr {
+		case "127.0.0.1:9000":
+			freeSnap = s.msg.GetStateSnapshot()
+		case "127.0.0.1:9001":
+			followSnap = s.msg.GetStateSnapshot()
+		}
+	}
+	if freeSnap == nil || len(freeSnap.Players) != 2 {
+		t.Fatalf("expected free spectator's snapshot to show both players, got %v", freeSnap)
+	}
+	if followSnap == nil || len(followSnap.Players) != 1 || followSnap.Players[0].PlayerId != a.ID {
+		t.Fatalf("expected follower's latest snapshot to show only A, got %v", followSnap)
+	}
+
+	// B moves out of A's sight: the free spectator sees it, the follower doesn't
+	broadcaster.sent = nil
+	b.Position.X = 600
+	engine.broadcastState()
+	engine.Spectators().Flush(broadcaster, time.Now())
+	if !sentPlayer(broadcaster, "127.0.0.1:9000", b.ID) {
+		t.Error("expected free spectator sent B's move")
+	}
+	for _, s := range broadcaster.sent {
+		if s.addr == "127.0.0.1:9001" {
+			t.Errorf("expected nothing for follower when A's view didn't change, got %v", s.msg)
+		}
+	}
+}
@@ -15,6 +15,9 @@ type Config struct {
 	PlayerSpeed    float32 // Units per second (default: 100)
 	WorldWidth     float32 // World bounds (default: 1000)
 	WorldHeight    float32 // World bounds (default: 1000)
+	Validation     ValidationConfig // Input validation / anti-cheat
+	ReconnectGrace time.Duration    // How long a disconnected player is kept (default: 30s)
+	SpectatorDelay time.Duration    // How far spectators lag behind live state (default: 0)
 }
 
 // DefaultConfig returns sensible defaults.
@@ -25,6 +28,8 @@ func DefaultConfig() Config {
 		PlayerSpeed: 100,
 		WorldWidth:  1000,
 		WorldHeight: 1000,
+		Validation:     DefaultValidationConfig(),
+		ReconnectGrace: 30 * time.Second,
 	}
 }
 
@@ -35,10 +40,15 @@ type Player struct {
 	Addr        string      // UDP address
 	Position    Vec2        // Current position
 	Velocity    Vec2        // Current velocity
+	Team        uint32      // 0 = no team
 	LastInput   uint64      // Last processed input sequence
 	LastSeen    time.Time   // Last message time
 	ConnectedAt time.Time
 
+	// Set while the player is in their reconnect grace period
+	Disconnected   bool
+	DisconnectedAt time.Time
+
 	// Input queue for deterministic processing
 	InputQueue []Input
 }
@@ -132,6 +142,19 @@ func (s *State) AddPlayerWithID(name, playerID, addr string) *Player {
 	return player
 }
 
+// SetTeam moves a player to a team. Returns false if the player is unknown.
+func (s *State) SetTeam(playerID string, team uint32) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	player, ok := s.players[playerID]
+	if !ok {
+		return false
+	}
+	player.Team = team
+	return true
+}
+
 // RemovePlayer removes a player by ID.
 func (s *State) RemovePlayer(id string) {
 	s.mu.Lock()
@@ -159,6 +182,20 @@ func (s *State) GetPlayer(id string) *Player {
 	return s.players[id]
 }
 
+// playerCopy returns a copy of a player (without their input queue), or
+// nil if there's no such player.
+func (s *State) playerCopy(id string) *Player {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	p, ok := s.players[id]
+	if !ok {
+		return nil
+	}
+	c := *p
+	c.InputQueue = nil
+	return &c
+}
+
 // GetPlayerByAddr returns a player by address.
 func (s *State) GetPlayerByAddr(addr string) *Player {
 	s.mu.RLock()
@@ -183,6 +220,21 @@ func (s *State) AllPlayers() []*Player {
 	return players
 }
 
+// PlayerCopies returns copies of all players (without their input
+// queues), safe to read while the tick loop moves them.
+func (s *State) PlayerCopies() []*Player {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	players := make([]*Player, 0, len(s.players))
+	for _, p := range s.players {
+		c := *p
+		c.InputQueue = nil
+		players = append(players, &c)
+	}
+	return players
+}
+
 // PlayerCount returns the current player count.
 func (s *State) PlayerCount() int {
 	s.mu.RLock()
@@ -217,7 +269,7 @@ func (s *State) ApplyInput(playerID string, input Input) bool {
 	defer s.mu.Unlock()
 
 	player, ok := s.players[playerID]
-	if !ok {
+	if !ok || player.Disconnected {
 		return false
 	}
 
@@ -226,6 +278,9 @@ func (s *State) ApplyInput(playerID string, input Input) bool {
 		return false
 	}
 
+	// Never let a movement vector exceed full speed
+	input.Movement = ClampMovement(input.Movement, 1)
+
 	// Add to input queue
 	player.InputQueue = append(player.InputQueue, input)
 	player.LastSeen = time.Now()
@@ -235,6 +290,12 @@ func (s *State) ApplyInput(playerID string, input Input) bool {
 // ProcessInputs processes all queued inputs for all players.
 // Call this once per tick.
 func (s *State) ProcessInputs() {
+	s.processInputs(nil)
+}
+
+// processInputs processes queued inputs, reporting each one to observe
+// (if set) as it takes effect. Used by the replay recorder.
+func (s *State) processInputs(observe func(playerID string, input Input)) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -266,6 +327,10 @@ func (s *State) ProcessInputs() {
 			}
 
 			player.LastInput = input.Sequence
+
+			if observe != nil {
+				observe(player.ID, input)
+			}
 		}
 
 		// Clear processed inputs
@@ -275,6 +340,62 @@ func (s *State) ProcessInputs() {
 	}
 }
 
+// FreezePlayer marks a player as disconnected. They stay in the world
+// but stop moving until thawed.
+func (s *State) FreezePlayer(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	player, ok := s.players[id]
+	if !ok {
+		return false
+	}
+	player.Disconnected = true
+	player.DisconnectedAt = time.Now()
+	player.Velocity = Vec2{}
+	player.InputQueue = player.InputQueue[:0]
+	return true
+}
+
+// ThawPlayer reattaches a frozen player at a (possibly new) address.
+func (s *State) ThawPlayer(id, addr string) *Player {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	player, ok := s.players[id]
+	if !ok {
+		return nil
+	}
+	player.Disconnected = false
+	player.DisconnectedAt = time.Time{}
+	player.Addr = addr
+	player.LastSeen = time.Now()
+	player.LastInput = 0 // Resumed clients may restart their sequence
+	return player
+}
+
+// queueInput appends input without sequence checks (replay playback).
+func (s *State) queueInput(playerID string, input Input) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if player, ok := s.players[playerID]; ok {
+		player.InputQueue = append(player.InputQueue, input)
+	}
+}
+
+// restore replaces all players and the tick counter (replay seeking).
+func (s *State) restore(tick uint64, players []*Player) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tick = tick
+	s.players = make(map[string]*Player, len(players))
+	for _, p := range players {
+		s.players[p.ID] = p
+	}
+}
+
 // UpdateLastSeen updates the last seen time for a player.
 func (s *State) UpdateLastSeen(playerID string) {
 	s.mu.Lock()
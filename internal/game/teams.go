@@ -0,0 +1,213 @@
+package game
+
+import (
+	"time"
+
+	"github.com/LemmyAI/gameserver/internal/protocol/gamepb"
+)
+
+// NoTeam is the team of players that haven't been assigned one.
+const NoTeam uint32 = 0
+
+// TeamBroadcaster is implemented by broadcasters that can address a team
+// directly. Engines fall back to SendTo per teammate otherwise.
+type TeamBroadcaster interface {
+	BroadcastTeam(msg *gamepb.Message, team uint32, excludeID string) error
+}
+
+// TeamRules are the hooks games use for team-aware rules.
+type TeamRules struct {
+	// FriendlyFire lets teammates damage each other.
+	FriendlyFire bool
+
+	// CanDamage overrides FriendlyFire when set.
+	CanDamage func(attacker, target *Player) bool
+
+	// CanSee filters state updates per player: a player only receives
+	// updates about targets they can see. Nil means everyone sees
+	// everyone. Spectators see everything unless they follow a player,
+	// in which case they see what that player sees.
+	CanSee func(viewer, target *Player) bool
+}
+
+// SameTeam returns true if both players are on the same (real) team.
+func SameTeam(a, b *Player) bool {
+	return a.Team != NoTeam && a.Team == b.Team
+}
+
+// TeammatesOrWithin is a CanSee rule: teammates always see each other,
+// everyone else only within radius.
+func TeammatesOrWithin(radius float32) func(viewer, target *Player) bool {
+	r2 := radius * radius
+	return func(viewer, target *Player) bool {
+		if SameTeam(viewer, target) {
+			return true
+		}
+		dx := viewer.Position.X - target.Position.X
+		dy := viewer.Position.Y - target.Position.Y
+		return dx*dx+dy*dy <= r2
+	}
+}
+
+// SetTeamRules installs the team rules. Call before Start. The hooks run
+// on copies of the players when called outside the tick loop.
+func (e *Engine) SetTeamRules(rules TeamRules) {
+	e.rules = rules
+	e.visible = make(map[string]map[string]bool)
+}
+
+// TeamRules returns the engine's team rules.
+func (e *Engine) TeamRules() TeamRules {
+	return e.rules
+}
+
+// SetTeam moves a player to a team (NoTeam to clear).
+func (e *Engine) SetTeam(playerID string, team uint32) error {
+	player := e.state.GetPlayer(playerID)
+	if player == nil {
+		return ErrPlayerNotFound
+	}
+	if player.Team == team {
+		return nil
+	}
+	e.state.SetTeam(playerID, team)
+	e.recordEvent(ReplayEvent{Kind: EventTeam, PlayerID: playerID, Team: team})
+	return nil
+}
+
+// CanDamage applies the team rules to an attack between two players.
+// Players can't damage themselves; unknown players can't be damaged.
+func (e *Engine) CanDamage(attackerID, targetID string) bool {
+	attacker := e.state.playerCopy(attackerID)
+	target := e.state.playerCopy(targetID)
+	if attacker == nil || target == nil || attackerID == targetID {
+		return false
+	}
+	if e.rules.CanDamage != nil {
+		return e.rules.CanDamage(attacker, target)
+	}
+	return e.rules.FriendlyFire || !SameTeam(attacker, target)
+}
+
+// CanSee applies the team rules' visibility hook.
+func (e *Engine) CanSee(viewerID, targetID string) bool {
+	if viewerID == targetID || e.rules.CanSee == nil {
+		return true
+	}
+	viewer := e.state.playerCopy(viewerID)
+	target := e.state.playerCopy(targetID)
+	if viewer == nil || target == nil {
+		return false
+	}
+	return e.rules.CanSee(viewer, target)
+}
+
+// BroadcastTeam sends a message to every connected player on a team.
+func (e *Engine) BroadcastTeam(msg *gamepb.Message, team uint32, excludeID string) error {
+	if e.broadcaster == nil {
+		return nil
+	}
+	if tb, ok := e.broadcaster.(TeamBroadcaster); ok {
+		return tb.BroadcastTeam(msg, team, excludeID)
+	}
+	for _, p := range e.state.AllPlayers() {
+		if p.Team != team || p.ID == excludeID || p.Disconnected {
+			continue
+		}
+		e.broadcaster.SendTo(p.Addr, msg)
+	}
+	return nil
+}
+
+// broadcastVisible sends each player the part of a delta they can see.
+// Targets that come into view are sent in full; targets that leave view
+// are reported as removed. Returns each player's view for the spectators
+// following them (nil where nothing changed).
+func (e *Engine) broadcastVisible(tick uint64, players []*Player, changed []*PlayerState, removed []string) map[string]*gamepb.Message {
+	views := e.computeViews(tick, players, changed, removed)
+	for _, viewer := range players {
+		if msg := views[viewer.ID]; msg != nil && !viewer.Disconnected {
+			e.broadcaster.SendTo(viewer.Addr, msg)
+		}
+	}
+	return views
+}
+
+// computeViews works out each player's delta and remembers what they now
+// see
+func (e *Engine) computeViews(tick uint64, players []*Player, changed []*PlayerState, removed []string) map[string]*gamepb.Message {
+	e.visibleMu.Lock()
+	defer e.visibleMu.Unlock()
+
+	byID := make(map[string]*Player, len(players))
+	for _, p := range players {
+		byID[p.ID] = p
+	}
+	for id := range e.visible {
+		if byID[id] == nil {
+			delete(e.visible, id)
+		}
+	}
+
+	views := make(map[string]*gamepb.Message, len(players))
+	now := uint64(time.Now().UnixMilli())
+	for _, viewer := range players {
+		before := e.visible[viewer.ID]
+		after := make(map[string]bool, len(players))
+
+		delta := &gamepb.GameStateDelta{
+			Tick:           tick,
+			Timestamp:      now,
+			RemovedPlayers: append([]string(nil), removed...),
+		}
+		for _, target := range players {
+			if target.ID == viewer.ID || e.rules.CanSee(viewer, target) {
+				after[target.ID] = true
+			}
+		}
+		for _, p := range changed {
+			if after[p.ID] {
+				delta.ChangedPlayers = append(delta.ChangedPlayers, p.ToProto())
+			}
+		}
+		for id := range after {
+			if before[id] || changedContains(changed, id) {
+				continue
+			}
+			// Came into view without moving: send it in full
+			t := byID[id]
+			delta.ChangedPlayers = append(delta.ChangedPlayers, (&PlayerState{
+				ID:        t.ID,
+				Position:  t.Position,
+				Velocity:  t.Velocity,
+				Timestamp: uint64(t.LastSeen.UnixMilli()),
+				Team:      t.Team,
+				LastInput: t.LastInput,
+			}).ToProto())
+		}
+		for id := range before {
+			if !after[id] && byID[id] != nil {
+				delta.RemovedPlayers = append(delta.RemovedPlayers, id)
+			}
+		}
+		e.visible[viewer.ID] = after
+
+		if len(delta.ChangedPlayers) == 0 && len(delta.RemovedPlayers) == 0 {
+			views[viewer.ID] = nil
+			continue
+		}
+		views[viewer.ID] = &gamepb.Message{
+			Payload: &gamepb.Message_StateDelta{StateDelta: delta},
+		}
+	}
+	return views
+}
+
+func changedContains(changed []*PlayerState, id string) bool {
+	for _, p := range changed {
+		if p.ID == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,180 @@
+package game
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/LemmyAI/gameserver/internal/protocol/gamepb"
+)
+
+func TestTeamDamageRules(t *testing.T) {
+	engine := NewEngine(DefaultConfig(), &mockBroadcaster{})
+	a := engine.AddPlayer("A", "127.0.0.1:1")
+	b := engine.AddPlayer("B", "127.0.0.1:2")
+	c := engine.AddPlayer("C", "127.0.0.1:3")
+	engine.SetTeam(a.ID, 1)
+	engine.SetTeam(b.ID, 1)
+	engine.SetTeam(c.ID, 2)
+
+	if engine.CanDamage(a.ID, b.ID) {
+		t.Error("expected no friendly fire by default")
+	}
+	if !engine.CanDamage(a.ID, c.ID) {
+		t.Error("expected enemies to take damage")
+	}
+	if engine.CanDamage(a.ID, a.ID) {
+		t.Error("expected players unable to damage themselves")
+	}
+
+	engine.SetTeamRules(TeamRules{FriendlyFire: true})
+	if !engine.CanDamage(a.ID, b.ID) {
+		t.Error("expected friendly fire when enabled")
+	}
+	if err := engine.SetTeam("nobody", 1); err != ErrPlayerNotFound {
+		t.Errorf("expected ErrPlayerNotFound, got %v", err)
+	}
+}
+
+func TestTeamBroadcastFallback(t *testing.T) {
+	broadcaster := &mockBroadcaster{}
+	engine := NewEngine(DefaultConfig(), broadcaster)
+	a := engine.AddPlayer("A", "127.0.0.1:1")
+	b := engine.AddPlayer("B", "127.0.0.1:2")
+	engine.AddPlayer("C", "127.0.0.1:3")
+	engine.SetTeam(a.ID, 1)
+	engine.SetTeam(b.ID, 1)
+	broadcaster.sent = nil
+
+	engine.BroadcastTeam(&gamepb.Message{}, 1, a.ID)
+	if len(broadcaster.sent) != 1 || broadcaster.sent[0].addr != b.Addr {
+		t.Errorf("expected only teammate B messaged, got %d sends", len(broadcaster.sent))
+	}
+}
+
+func TestVisibilityFiltersDeltas(t *testing.T) {
+	broadcaster := &mockBroadcaster{}
+	engine := NewEngine(DefaultConfig(), broadcaster)
+	engine.SetTeamRules(TeamRules{CanSee: TeammatesOrWithin(50)})
+
+	a := engine.AddPlayer("A", "127.0.0.1:1")
+	b := engine.AddPlayer("B", "127.0.0.1:2")
+	a.Position = Vec2{X: 0, Y: 0}
+	b.Position = Vec2{X: 500, Y: 0}
+	engine.SetTeam(a.ID, 1)
+	engine.SetTeam(b.ID, 2)
+	broadcaster.messages, broadcaster.sent = nil, nil
+
+	engine.broadcastState()
+	if len(broadcaster.messages) != 0 {
+		t.Fatal("expected no unfiltered broadcast with a visibility rule")
+	}
+	for _, s := range broadcaster.sent {
+		for _, p := range s.msg.GetStateDelta().ChangedPlayers {
+			if s.addr == a.Addr && p.PlayerId == b.ID {
+				t.Error("expected B hidden from A while far away")
+			}
+		}
+	}
+
+	// B walks into range: A gets it in full
+	broadcaster.sent = nil
+	b.Position.X = 20
+	engine.broadcastState()
+	if !sentPlayer(broadcaster, a.Addr, b.ID) {
+		t.Error("expected B sent to A once in range")
+	}
+
+	// And walks away again: A is told B is gone
+	broadcaster.sent = nil
+	b.Position.X = 500
+	engine.broadcastState()
+	removed := false
+	for _, s := range broadcaster.sent {
+		if s.addr != a.Addr {
+			continue
+		}
+		for _, id := range s.msg.GetStateDelta().RemovedPlayers {
+			removed = removed || id == b.ID
+		}
+	}
+	if !removed {
+		t.Error("expected B removed from A's view")
+	}
+}
+
+func sentPlayer(m *mockBroadcaster, addr, playerID string) bool {
+	for _, s := range m.sent {
+		if s.addr != addr {
+			continue
+		}
+		for _, p := range s.msg.GetStateDelta().ChangedPlayers {
+			if p.PlayerId == playerID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// syncBroadcaster is a mockBroadcaster the tick loop can share with the test
+type syncBroadcaster struct {
+	mu   sync.Mutex
+	sent int
+}
+
+func (b *syncBroadcaster) Broadcast(msg *gamepb.Message, excludeID string) error {
+	return b.SendTo("", msg)
+}
+
+func (b *syncBroadcaster) SendTo(addr string, msg *gamepb.Message) error {
+	b.mu.Lock()
+	b.sent++
+	b.mu.Unlock()
+	return nil
+}
+
+// Run with -race: spectators follow players from another goroutine while
+// the tick loop moves them and updates what each player sees
+func TestVisibilityWhileTicking(t *testing.T) {
+	config := DefaultConfig()
+	config.TickRate = 100
+	engine := NewEngine(config, &syncBroadcaster{})
+	engine.SetTeamRules(TeamRules{CanSee: TeammatesOrWithin(50)})
+
+	a := engine.AddPlayer("A", "127.0.0.1:1")
+	b := engine.AddPlayer("B", "127.0.0.1:2")
+	engine.AddSpectator("s1", "127.0.0.1:9000")
+	engine.Start()
+	defer engine.Stop()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for seq := uint64(1); seq <= 100; seq++ {
+			dx := float32(1)
+			if seq/20%2 == 1 {
+				dx = -1
+			}
+			engine.ApplyInput(a.ID, Input{Sequence: seq, Movement: Vec2{X: dx}})
+			engine.ApplyInput(b.ID, Input{Sequence: seq, Movement: Vec2{X: -dx}})
+			time.Sleep(5 * time.Millisecond)
+		}
+	}()
+
+	for i := 0; ; i++ {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		target := a.ID
+		if i%2 == 0 {
+			target = b.ID
+		}
+		if err := engine.FollowPlayer("s1", target); err != nil {
+			t.Fatalf("follow failed: %v", err)
+		}
+		engine.CanSee(a.ID, b.ID)
+	}
+}
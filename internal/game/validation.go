@@ -0,0 +1,350 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// Validation errors returned by InputValidator.Validate.
+var (
+	ErrInputSpam        = errors.New("too many inputs")
+	ErrSequenceAnomaly  = errors.New("input sequence anomaly")
+	ErrTimestampAnomaly = errors.New("input timestamp anomaly")
+	ErrThrottled        = errors.New("player is throttled")
+)
+
+// ViolationKind identifies which check an input failed.
+type ViolationKind int
+
+const (
+	ViolationSpeed ViolationKind = iota
+	ViolationInputSpam
+	ViolationSequence
+	ViolationTimestamp
+)
+
+// String returns a human-readable name for the violation kind.
+func (k ViolationKind) String() string {
+	switch k {
+	case ViolationSpeed:
+		return "speed"
+	case ViolationInputSpam:
+		return "input_spam"
+	case ViolationSequence:
+		return "sequence"
+	case ViolationTimestamp:
+		return "timestamp"
+	default:
+		return "unknown"
+	}
+}
+
+// Action is what the server should do about a player's violations.
+type Action int
+
+const (
+	ActionNone Action = iota
+	ActionWarn
+	ActionThrottle
+	ActionKick
+)
+
+// String returns a human-readable name for the action.
+func (a Action) String() string {
+	switch a {
+	case ActionNone:
+		return "none"
+	case ActionWarn:
+		return "warn"
+	case ActionThrottle:
+		return "throttle"
+	case ActionKick:
+		return "kick"
+	default:
+		return "unknown"
+	}
+}
+
+// Violation describes a single failed validation.
+type Violation struct {
+	PlayerID string
+	Kind     ViolationKind
+	Detail   string
+	Score    float64 // Player's score after this violation
+	Action   Action  // Action chosen by the policy
+}
+
+// Policy decides what to do with a player given their violation score.
+type Policy interface {
+	Decide(score float64) Action
+}
+
+// ThresholdPolicy escalates from warn to throttle to kick as the score grows.
+// A zero threshold disables that step.
+type ThresholdPolicy struct {
+	Warn     float64
+	Throttle float64
+	Kick     float64
+}
+
+// Decide implements Policy.
+func (p ThresholdPolicy) Decide(score float64) Action {
+	switch {
+	case p.Kick > 0 && score >= p.Kick:
+		return ActionKick
+	case p.Throttle > 0 && score >= p.Throttle:
+		return ActionThrottle
+	case p.Warn > 0 && score >= p.Warn:
+		return ActionWarn
+	default:
+		return ActionNone
+	}
+}
+
+// ValidationConfig configures the input validation pipeline.
+// Zero values disable the corresponding check.
+type ValidationConfig struct {
+	MaxMovement      float32       // Max movement vector magnitude (1.0 = full speed)
+	SpeedTolerance   float32       // Magnitude above MaxMovement*(1+tolerance) counts as a violation
+	MaxInputsPerTick int           // Inputs accepted per player per tick
+	MaxSequenceRate  uint64        // Max sequence numbers a client may advance per second
+	MaxClockDrift    time.Duration // Max amount client clock may run ahead of server clock
+	ScoreDecay       float64       // Score points forgiven per second
+	ThrottleDuration time.Duration // How long a throttled player stays throttled
+	Weights          map[ViolationKind]float64
+	Policy           Policy
+}
+
+// DefaultValidationConfig returns sensible defaults.
+func DefaultValidationConfig() ValidationConfig {
+	return ValidationConfig{
+		MaxMovement:      1.0,
+		SpeedTolerance:   0.05, // 5% tolerance
+		MaxInputsPerTick: 4,
+		MaxSequenceRate:  1000, // Allows millisecond-based sequences
+		MaxClockDrift:    2 * time.Second,
+		ScoreDecay:       1.0,
+		ThrottleDuration: 5 * time.Second,
+		Weights: map[ViolationKind]float64{
+			ViolationSpeed:     2,
+			ViolationInputSpam: 1,
+			ViolationSequence:  5,
+			ViolationTimestamp: 5,
+		},
+		Policy: ThresholdPolicy{Warn: 5, Throttle: 15, Kick: 40},
+	}
+}
+
+// inputHistory tracks per-player validation state.
+type inputHistory struct {
+	tick           uint64 // Tick the counter below belongs to
+	inputsThisTick int
+
+	lastSequence  uint64
+	lastTimestamp uint64
+	lastInputAt   time.Time
+
+	// Clock baseline from the first timestamped input
+	baseClientTS   uint64
+	baseServerTime time.Time
+
+	score          float64
+	scoreUpdatedAt time.Time
+	throttledUntil time.Time
+}
+
+// InputValidator checks player inputs before they reach the game state.
+type InputValidator struct {
+	mu      sync.Mutex
+	config  ValidationConfig
+	history map[string]*inputHistory
+}
+
+// NewInputValidator creates a new input validator.
+func NewInputValidator(config ValidationConfig) *InputValidator {
+	return &InputValidator{
+		config:  config,
+		history: make(map[string]*inputHistory),
+	}
+}
+
+// Config returns the validation configuration.
+func (v *InputValidator) Config() ValidationConfig {
+	return v.config
+}
+
+// Validate checks an input for a player at the given tick.
+// Movement is normalized in place. A non-nil error means the input should
+// be dropped; the returned Violation is non-nil whenever a check failed.
+func (v *InputValidator) Validate(playerID string, input *Input, tick uint64, now time.Time) (*Violation, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	h, ok := v.history[playerID]
+	if !ok {
+		h = &inputHistory{scoreUpdatedAt: now}
+		v.history[playerID] = h
+	}
+	v.decay(h, now)
+
+	// Rate limit per tick (throttled players get a single input per tick)
+	if h.tick != tick {
+		h.tick = tick
+		h.inputsThisTick = 0
+	}
+	h.inputsThisTick++
+	throttled := now.Before(h.throttledUntil)
+	if throttled && h.inputsThisTick > 1 {
+		return nil, ErrThrottled
+	}
+	if v.config.MaxInputsPerTick > 0 && h.inputsThisTick > v.config.MaxInputsPerTick {
+		return v.violate(playerID, h, ViolationInputSpam, fmt.Sprintf("%d inputs in tick %d", h.inputsThisTick, tick), now), ErrInputSpam
+	}
+
+	// Sequence anomalies (stale inputs are dropped by State.ApplyInput)
+	if v.config.MaxSequenceRate > 0 && h.lastSequence > 0 && input.Sequence > h.lastSequence+v.maxSequenceJump(h, now) {
+		last := h.lastSequence
+		h.lastSequence = input.Sequence // Re-baseline so one jump isn't punished forever
+		return v.violate(playerID, h, ViolationSequence, fmt.Sprintf("sequence jumped %d -> %d", last, input.Sequence), now), ErrSequenceAnomaly
+	}
+
+	// Timestamp anomalies: going backwards, or client clock running fast
+	if input.Timestamp > 0 {
+		if input.Timestamp < h.lastTimestamp && input.Sequence > h.lastSequence {
+			return v.violate(playerID, h, ViolationTimestamp, fmt.Sprintf("timestamp went backwards %d -> %d", h.lastTimestamp, input.Timestamp), now), ErrTimestampAnomaly
+		}
+		if h.baseClientTS == 0 {
+			h.baseClientTS = input.Timestamp
+			h.baseServerTime = now
+		} else if v.config.MaxClockDrift > 0 && input.Timestamp >= h.baseClientTS {
+			clientElapsed := time.Duration(input.Timestamp-h.baseClientTS) * time.Millisecond
+			serverElapsed := now.Sub(h.baseServerTime)
+			if drift := clientElapsed - serverElapsed; drift > v.config.MaxClockDrift {
+				return v.violate(playerID, h, ViolationTimestamp, fmt.Sprintf("client clock ahead by %v", drift), now), ErrTimestampAnomaly
+			}
+		}
+	}
+
+	// Speed: normalize movement, flag vectors well beyond the limit
+	var violation *Violation
+	mag := input.Movement.Length()
+	if v.config.MaxMovement > 0 && mag > v.config.MaxMovement*(1+v.config.SpeedTolerance) {
+		violation = v.violate(playerID, h, ViolationSpeed, fmt.Sprintf("movement magnitude %.2f", mag), now)
+	}
+	input.Movement = ClampMovement(input.Movement, v.config.MaxMovement)
+
+	if input.Sequence > h.lastSequence {
+		h.lastSequence = input.Sequence
+	}
+	if input.Timestamp > h.lastTimestamp {
+		h.lastTimestamp = input.Timestamp
+	}
+	h.lastInputAt = now
+	return violation, nil
+}
+
+// maxSequenceJump returns how far the sequence may have advanced since the
+// last accepted input, allowing at least one second's worth.
+func (v *InputValidator) maxSequenceJump(h *inputHistory, now time.Time) uint64 {
+	elapsed := now.Sub(h.lastInputAt).Seconds()
+	if elapsed < 1 {
+		elapsed = 1
+	}
+	return uint64(elapsed * float64(v.config.MaxSequenceRate))
+}
+
+// violate records a violation and applies the policy.
+// Must be called with v.mu held.
+func (v *InputValidator) violate(playerID string, h *inputHistory, kind ViolationKind, detail string, now time.Time) *Violation {
+	weight := 1.0
+	if w, ok := v.config.Weights[kind]; ok {
+		weight = w
+	}
+	h.score += weight
+
+	action := ActionNone
+	if v.config.Policy != nil {
+		action = v.config.Policy.Decide(h.score)
+	}
+	if action == ActionThrottle && v.config.ThrottleDuration > 0 {
+		h.throttledUntil = now.Add(v.config.ThrottleDuration)
+	}
+
+	return &Violation{
+		PlayerID: playerID,
+		Kind:     kind,
+		Detail:   detail,
+		Score:    h.score,
+		Action:   action,
+	}
+}
+
+// decay forgives score over time.
+// Must be called with v.mu held.
+func (v *InputValidator) decay(h *inputHistory, now time.Time) {
+	if v.config.ScoreDecay > 0 && h.score > 0 {
+		h.score -= now.Sub(h.scoreUpdatedAt).Seconds() * v.config.ScoreDecay
+		if h.score < 0 {
+			h.score = 0
+		}
+	}
+	h.scoreUpdatedAt = now
+}
+
+// Score returns a player's current violation score.
+func (v *InputValidator) Score(playerID string) float64 {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	h, ok := v.history[playerID]
+	if !ok {
+		return 0
+	}
+	v.decay(h, time.Now())
+	return h.score
+}
+
+// IsThrottled returns true if the player is currently throttled.
+func (v *InputValidator) IsThrottled(playerID string) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	h, ok := v.history[playerID]
+	return ok && time.Now().Before(h.throttledUntil)
+}
+
+// Forget drops all history for a player (call when they leave).
+func (v *InputValidator) Forget(playerID string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	delete(v.history, playerID)
+}
+
+// Length returns the magnitude of the vector.
+func (v Vec2) Length() float32 {
+	return float32(math.Sqrt(float64(v.X*v.X + v.Y*v.Y)))
+}
+
+// ClampMovement scales a movement vector down to at most max magnitude.
+// NaN or infinite components are treated as no movement.
+func ClampMovement(m Vec2, max float32) Vec2 {
+	if isBad(m.X) || isBad(m.Y) {
+		return Vec2{}
+	}
+	if max <= 0 {
+		return m
+	}
+	mag := m.Length()
+	if mag <= max {
+		return m
+	}
+	scale := max / mag
+	return Vec2{X: m.X * scale, Y: m.Y * scale}
+}
+
+func isBad(f float32) bool {
+	return math.IsNaN(float64(f)) || math.IsInf(float64(f), 0)
+}
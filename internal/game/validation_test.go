@@ -0,0 +1,124 @@
+package game
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClampMovement(t *testing.T) {
+	m := ClampMovement(Vec2{X: 3, Y: 4}, 1)
+	if l := m.Length(); l < 0.999 || l > 1.001 {
+		t.Errorf("expected magnitude 1, got %.3f", l)
+	}
+
+	m = ClampMovement(Vec2{X: 0.5, Y: 0}, 1)
+	if m.X != 0.5 {
+		t.Errorf("expected small vector unchanged, got %.2f", m.X)
+	}
+}
+
+func TestValidatorSpeedNormalizes(t *testing.T) {
+	v := NewInputValidator(DefaultValidationConfig())
+	input := Input{Sequence: 1, Movement: Vec2{X: 10, Y: 0}}
+
+	violation, err := v.Validate("p1", &input, 1, time.Now())
+	if err != nil {
+		t.Fatalf("speed violation should not drop input, got %v", err)
+	}
+	if violation == nil || violation.Kind != ViolationSpeed {
+		t.Fatalf("expected speed violation, got %+v", violation)
+	}
+	if input.Movement.X != 1 {
+		t.Errorf("expected movement clamped to 1, got %.2f", input.Movement.X)
+	}
+}
+
+func TestValidatorInputSpam(t *testing.T) {
+	config := DefaultValidationConfig()
+	config.MaxInputsPerTick = 2
+	v := NewInputValidator(config)
+	now := time.Now()
+
+	for seq := uint64(1); seq <= 2; seq++ {
+		input := Input{Sequence: seq}
+		if _, err := v.Validate("p1", &input, 1, now); err != nil {
+			t.Fatalf("input %d: unexpected error %v", seq, err)
+		}
+	}
+
+	input := Input{Sequence: 3}
+	if _, err := v.Validate("p1", &input, 1, now); err != ErrInputSpam {
+		t.Errorf("expected ErrInputSpam, got %v", err)
+	}
+
+	// Next tick resets the counter
+	input = Input{Sequence: 4}
+	if _, err := v.Validate("p1", &input, 2, now); err != nil {
+		t.Errorf("expected input accepted on next tick, got %v", err)
+	}
+}
+
+func TestValidatorSequenceAndTimestamp(t *testing.T) {
+	v := NewInputValidator(DefaultValidationConfig())
+	now := time.Now()
+
+	input := Input{Sequence: 1, Timestamp: 1000}
+	v.Validate("p1", &input, 1, now)
+
+	input = Input{Sequence: 10000, Timestamp: 1016}
+	if _, err := v.Validate("p1", &input, 2, now); err != ErrSequenceAnomaly {
+		t.Errorf("expected ErrSequenceAnomaly, got %v", err)
+	}
+
+	// Client claims 10s passed while server saw none
+	input = Input{Sequence: 10001, Timestamp: 11000}
+	if _, err := v.Validate("p1", &input, 3, now); err != ErrTimestampAnomaly {
+		t.Errorf("expected ErrTimestampAnomaly, got %v", err)
+	}
+}
+
+func TestValidatorPolicyEscalates(t *testing.T) {
+	config := DefaultValidationConfig()
+	config.ScoreDecay = 0
+	config.Policy = ThresholdPolicy{Warn: 2, Throttle: 4, Kick: 6}
+	v := NewInputValidator(config)
+	now := time.Now()
+
+	var actions []Action
+	for seq := uint64(1); seq <= 3; seq++ {
+		input := Input{Sequence: seq, Movement: Vec2{X: 5}}
+		violation, _ := v.Validate("p1", &input, seq, now)
+		actions = append(actions, violation.Action)
+	}
+
+	want := []Action{ActionWarn, ActionThrottle, ActionKick}
+	for i := range want {
+		if actions[i] != want[i] {
+			t.Errorf("violation %d: expected %s, got %s", i, want[i], actions[i])
+		}
+	}
+	if !v.IsThrottled("p1") {
+		t.Error("expected player to be throttled")
+	}
+}
+
+func TestEngineRejectsInvalidInput(t *testing.T) {
+	broadcaster := &mockBroadcaster{}
+	config := DefaultConfig()
+	config.Validation.Policy = ThresholdPolicy{Kick: 1}
+	engine := NewEngine(config, broadcaster)
+
+	var kicked string
+	engine.OnViolation(func(v Violation) {
+		if v.Action == ActionKick {
+			kicked = v.PlayerID
+		}
+	})
+
+	player := engine.AddPlayer("Cheater", "127.0.0.1:1234")
+	engine.ApplyInput(player.ID, Input{Sequence: 1, Movement: Vec2{X: 50}})
+
+	if kicked != player.ID {
+		t.Errorf("expected %s to be kicked, got %q", player.ID, kicked)
+	}
+}
@@ -0,0 +1,481 @@
+// Package matchmaker groups queued players into matches by game mode,
+// skill and latency, and hands each match a fresh room.
+package matchmaker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Matchmaker errors
+var (
+	ErrWaitingForMatch = errors.New("waiting for match")
+	ErrAlreadyQueued   = errors.New("player already queued")
+	ErrNotQueued       = errors.New("player not queued")
+	ErrPartyTooLarge   = errors.New("party larger than match size")
+	ErrNoPlayers       = errors.New("no players")
+	ErrNoMode          = errors.New("game mode required")
+	ErrClosed          = errors.New("matchmaker closed")
+	ErrTicketExpired   = errors.New("no match found in time")
+)
+
+// Config for matchmaking
+type Config struct {
+	MatchSize int `json:"match_size"` // Players per match
+
+	// Skill window: starts at SkillWindow, grows by SkillWiden per second
+	// of waiting, capped at MaxSkillWindow
+	SkillWindow    int `json:"skill_window"`
+	SkillWiden     int `json:"skill_widen"`
+	MaxSkillWindow int `json:"max_skill_window"`
+
+	// Latency window, widened the same way (ignored for tickets with no
+	// latency measurement)
+	LatencyWindow    time.Duration `json:"latency_window"`
+	LatencyWiden     time.Duration `json:"latency_widen"`
+	MaxLatencyWindow time.Duration `json:"max_latency_window"`
+
+	TickPeriod time.Duration `json:"tick_period"` // How often matches are formed
+	TicketTTL  time.Duration `json:"ticket_ttl"`  // Give up on tickets (and forget matches) after this
+}
+
+// DefaultConfig returns sensible defaults
+func DefaultConfig() Config {
+	return Config{
+		MatchSize:        2,
+		SkillWindow:      100,
+		SkillWiden:       25,
+		MaxSkillWindow:   1000,
+		LatencyWindow:    50 * time.Millisecond,
+		LatencyWiden:     10 * time.Millisecond,
+		MaxLatencyWindow: 250 * time.Millisecond,
+		TickPeriod:       250 * time.Millisecond,
+		TicketTTL:        5 * time.Minute,
+	}
+}
+
+// Request asks for a match for a solo player or a party
+type Request struct {
+	Mode    string        `json:"mode"`
+	Players []string      `json:"players"` // Party members, leader first
+	Skill   int           `json:"skill"`   // Party rating
+	Latency time.Duration `json:"latency"` // Round trip to the game servers (0 if unknown)
+}
+
+// Ticket is a queued request
+type Ticket struct {
+	ID         string        `json:"id"`
+	Mode       string        `json:"mode"`
+	Players    []string      `json:"players"`
+	Skill      int           `json:"skill"`
+	Latency    time.Duration `json:"latency"`
+	EnqueuedAt time.Time     `json:"enqueued_at"`
+
+	match *Match
+	err   error         // Why the ticket left the queue without a match
+	done  chan struct{} // Closed when the ticket leaves the queue
+}
+
+// Match is a group of players sent to one room
+type Match struct {
+	ID        string    `json:"id"`
+	Mode      string    `json:"mode"`
+	Players   []string  `json:"players"`
+	RoomID    string    `json:"room_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateRoomFunc creates (and starts) the room for a new match
+type CreateRoomFunc func(mode string, players []string) (roomID string, err error)
+
+// Matchmaker runs per-mode queues
+type Matchmaker struct {
+	config     Config
+	createRoom CreateRoomFunc
+
+	queues  map[string][]*Ticket // Mode → tickets, oldest first
+	tickets map[string]*Ticket   // Player ID → their ticket (queued or finished)
+	mu      sync.Mutex
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// New creates a matchmaker and starts forming matches
+func New(config Config, createRoom CreateRoomFunc) *Matchmaker {
+	m := &Matchmaker{
+		config:     config,
+		createRoom: createRoom,
+		queues:     make(map[string][]*Ticket),
+		tickets:    make(map[string]*Ticket),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	go m.loop()
+	return m
+}
+
+// Close stops forming matches and fails every queued ticket
+func (m *Matchmaker) Close() {
+	m.once.Do(func() {
+		close(m.stop)
+		<-m.done
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		for mode, queue := range m.queues {
+			for _, t := range queue {
+				m.finishLocked(t, nil, ErrClosed)
+			}
+			delete(m.queues, mode)
+		}
+	})
+}
+
+// Enqueue adds a player or party to a mode's queue
+func (m *Matchmaker) Enqueue(req Request) (*Ticket, error) {
+	if req.Mode == "" {
+		return nil, ErrNoMode
+	}
+	if len(req.Players) == 0 {
+		return nil, ErrNoPlayers
+	}
+	if len(req.Players) > m.config.MatchSize {
+		return nil, ErrPartyTooLarge
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	select {
+	case <-m.stop:
+		return nil, ErrClosed
+	default:
+	}
+	for _, id := range req.Players {
+		if t, ok := m.tickets[id]; ok && t.queued() {
+			return nil, ErrAlreadyQueued
+		}
+	}
+
+	t := &Ticket{
+		ID:         uuid.New().String(),
+		Mode:       req.Mode,
+		Players:    append([]string(nil), req.Players...),
+		Skill:      req.Skill,
+		Latency:    req.Latency,
+		EnqueuedAt: time.Now(),
+		done:       make(chan struct{}),
+	}
+	m.queues[req.Mode] = append(m.queues[req.Mode], t)
+	for _, id := range t.Players {
+		m.tickets[id] = t
+	}
+	return t, nil
+}
+
+// FindMatch returns the player's match, queueing them solo if they
+// aren't already waiting. Returns ErrWaitingForMatch until matched.
+func (m *Matchmaker) FindMatch(mode, playerID string, skill int) (*Match, error) {
+	status := m.Status(playerID)
+	switch {
+	case status.Match != nil:
+		return status.Match, nil
+	case status.State == StateQueued:
+		return nil, ErrWaitingForMatch
+	}
+	if _, err := m.Enqueue(Request{Mode: mode, Players: []string{playerID}, Skill: skill}); err != nil {
+		return nil, err
+	}
+	return nil, ErrWaitingForMatch
+}
+
+// Cancel removes the player's ticket (and their whole party) from the queue
+func (m *Matchmaker) Cancel(playerID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, ok := m.tickets[playerID]
+	if !ok || !t.queued() {
+		return ErrNotQueued
+	}
+	m.removeLocked(t)
+	m.finishLocked(t, nil, ErrNotQueued)
+	for _, id := range t.Players {
+		delete(m.tickets, id)
+	}
+	return nil
+}
+
+// State is where a player is in matchmaking
+type State string
+
+const (
+	StateIdle    State = "idle"    // Not queued
+	StateQueued  State = "queued"  // Waiting for a match
+	StateMatched State = "matched" // Match found, see Status.Match
+	StateFailed  State = "failed"  // Ticket expired or room creation failed
+)
+
+// Status reports a player's matchmaking state
+type Status struct {
+	State  State         `json:"state"`
+	Ticket *Ticket       `json:"ticket,omitempty"`
+	Match  *Match        `json:"match,omitempty"`
+	Waited time.Duration `json:"waited"`
+	Error  string        `json:"error,omitempty"`
+}
+
+// Status returns the player's matchmaking state
+func (m *Matchmaker) Status(playerID string) Status {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.statusLocked(playerID)
+}
+
+func (m *Matchmaker) statusLocked(playerID string) Status {
+	t, ok := m.tickets[playerID]
+	if !ok {
+		return Status{State: StateIdle}
+	}
+	status := Status{Ticket: t, Match: t.match}
+	switch {
+	case t.match != nil:
+		status.State = StateMatched
+		status.Waited = t.match.CreatedAt.Sub(t.EnqueuedAt)
+	case t.err != nil:
+		status.State = StateFailed
+		status.Error = t.err.Error()
+	default:
+		status.State = StateQueued
+		status.Waited = time.Since(t.EnqueuedAt)
+	}
+	return status
+}
+
+// Wait blocks until the player's ticket is matched or fails, or ctx is
+// done (long-poll)
+func (m *Matchmaker) Wait(ctx context.Context, playerID string) (Status, error) {
+	m.mu.Lock()
+	t, ok := m.tickets[playerID]
+	m.mu.Unlock()
+	if !ok {
+		return Status{State: StateIdle}, ErrNotQueued
+	}
+
+	select {
+	case <-t.done:
+	case <-ctx.Done():
+	}
+	return m.Status(playerID), nil
+}
+
+// QueueLength returns how many tickets are waiting in a mode
+func (m *Matchmaker) QueueLength(mode string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.queues[mode])
+}
+
+// loop forms matches every TickPeriod until Close
+func (m *Matchmaker) loop() {
+	defer close(m.done)
+	ticker := time.NewTicker(m.config.TickPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			m.tick(now)
+		case <-m.stop:
+			return
+		}
+	}
+}
+
+// tick expires stale tickets and forms as many matches as it can
+func (m *Matchmaker) tick(now time.Time) {
+	m.mu.Lock()
+	m.expireLocked(now)
+
+	var formed [][]*Ticket
+	for mode := range m.queues {
+		for {
+			group := m.formLocked(mode, now)
+			if group == nil {
+				break
+			}
+			for _, t := range group {
+				m.removeLocked(t)
+			}
+			formed = append(formed, group)
+		}
+	}
+	m.mu.Unlock()
+
+	// Room creation may spawn processes; don't hold the lock
+	for _, group := range formed {
+		m.start(group, now)
+	}
+}
+
+// start creates the room for a formed group and resolves its tickets
+func (m *Matchmaker) start(group []*Ticket, now time.Time) {
+	match := &Match{
+		ID:        uuid.New().String(),
+		Mode:      group[0].Mode,
+		CreatedAt: now,
+	}
+	for _, t := range group {
+		match.Players = append(match.Players, t.Players...)
+	}
+
+	roomID, err := m.createRoom(match.Mode, match.Players)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err != nil {
+		for _, t := range group {
+			m.finishLocked(t, nil, err)
+		}
+		return
+	}
+	match.RoomID = roomID
+	for _, t := range group {
+		m.finishLocked(t, match, nil)
+	}
+}
+
+// formLocked finds one full group in a mode's queue. The oldest ticket
+// anchors the group since its windows are the widest; others join if
+// they fall inside the anchor's windows and fit the remaining slots.
+func (m *Matchmaker) formLocked(mode string, now time.Time) []*Ticket {
+	queue := m.queues[mode]
+	for i, anchor := range queue {
+		skillWindow, latencyWindow := m.windows(anchor, now)
+		group := []*Ticket{anchor}
+		size := len(anchor.Players)
+
+		for _, t := range queue[i+1:] {
+			if size == m.config.MatchSize {
+				break
+			}
+			if size+len(t.Players) > m.config.MatchSize {
+				continue
+			}
+			if abs(t.Skill-anchor.Skill) > skillWindow {
+				continue
+			}
+			if t.Latency > 0 && anchor.Latency > 0 && absDuration(t.Latency-anchor.Latency) > latencyWindow {
+				continue
+			}
+			group = append(group, t)
+			size += len(t.Players)
+		}
+		if size == m.config.MatchSize {
+			return group
+		}
+	}
+	return nil
+}
+
+// windows returns how far a ticket's skill and latency windows have
+// widened after waiting
+func (m *Matchmaker) windows(t *Ticket, now time.Time) (int, time.Duration) {
+	waited := now.Sub(t.EnqueuedAt).Seconds()
+
+	skill := m.config.SkillWindow + int(waited*float64(m.config.SkillWiden))
+	if m.config.MaxSkillWindow > 0 && skill > m.config.MaxSkillWindow {
+		skill = m.config.MaxSkillWindow
+	}
+	latency := m.config.LatencyWindow + time.Duration(waited*float64(m.config.LatencyWiden))
+	if m.config.MaxLatencyWindow > 0 && latency > m.config.MaxLatencyWindow {
+		latency = m.config.MaxLatencyWindow
+	}
+	return skill, latency
+}
+
+// expireLocked fails tickets queued longer than TicketTTL and forgets
+// finished tickets older than that
+func (m *Matchmaker) expireLocked(now time.Time) {
+	if m.config.TicketTTL <= 0 {
+		return
+	}
+	for id, t := range m.tickets {
+		if now.Sub(t.EnqueuedAt) <= m.config.TicketTTL {
+			continue
+		}
+		if t.queued() {
+			m.removeLocked(t)
+			m.finishLocked(t, nil, ErrTicketExpired)
+			continue // Keep it around so Status reports the failure
+		}
+		if now.Sub(t.EnqueuedAt) > 2*m.config.TicketTTL {
+			delete(m.tickets, id)
+		}
+	}
+}
+
+// removeLocked takes a ticket out of its mode's queue
+func (m *Matchmaker) removeLocked(t *Ticket) {
+	queue := m.queues[t.Mode]
+	for i, q := range queue {
+		if q == t {
+			m.queues[t.Mode] = append(queue[:i:i], queue[i+1:]...)
+			break
+		}
+	}
+	if len(m.queues[t.Mode]) == 0 {
+		delete(m.queues, t.Mode)
+	}
+}
+
+// finishLocked resolves a ticket and wakes anyone waiting on it
+func (m *Matchmaker) finishLocked(t *Ticket, match *Match, err error) {
+	if !t.queued() {
+		return
+	}
+	t.match = match
+	t.err = err
+	close(t.done)
+}
+
+// queued returns true until the ticket is matched, cancelled or failed
+func (t *Ticket) queued() bool {
+	select {
+	case <-t.done:
+		return false
+	default:
+		return true
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// QueueLengths returns the number of waiting tickets per mode
+func (m *Matchmaker) QueueLengths() map[string]int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	lengths := make(map[string]int, len(m.queues))
+	for mode, queue := range m.queues {
+		lengths[mode] = len(queue)
+	}
+	return lengths
+}
@@ -0,0 +1,152 @@
+package matchmaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// newTestMatchmaker returns a matchmaker that never ticks on its own, so
+// tests drive match formation with explicit times
+func newTestMatchmaker(t *testing.T, createRoom CreateRoomFunc) *Matchmaker {
+	t.Helper()
+	config := DefaultConfig()
+	config.TickPeriod = time.Hour
+	if createRoom == nil {
+		createRoom = func(mode string, players []string) (string, error) {
+			return "room-" + players[0], nil
+		}
+	}
+	m := New(config, createRoom)
+	t.Cleanup(m.Close)
+	return m
+}
+
+func TestSkillWindowWidens(t *testing.T) {
+	m := newTestMatchmaker(t, nil)
+
+	a, _ := m.Enqueue(Request{Mode: "ffa", Players: []string{"a"}, Skill: 1000})
+	m.Enqueue(Request{Mode: "ffa", Players: []string{"b"}, Skill: 1300})
+	m.Enqueue(Request{Mode: "duel", Players: []string{"c"}, Skill: 1000})
+
+	m.tick(a.EnqueuedAt)
+	if m.Status("a").State != StateQueued {
+		t.Fatal("expected no match outside the skill window")
+	}
+
+	// 200 skill apart needs 100 + 25/s * 8s
+	m.tick(a.EnqueuedAt.Add(8 * time.Second))
+	status := m.Status("a")
+	if status.State != StateMatched {
+		t.Fatalf("expected match once the window widened, got %s", status.State)
+	}
+	if status.Match.RoomID != "room-a" || len(status.Match.Players) != 2 {
+		t.Errorf("unexpected match %+v", status.Match)
+	}
+	if m.Status("b").Match != status.Match {
+		t.Error("expected both players in the same match")
+	}
+	if m.Status("c").State != StateQueued {
+		t.Error("expected other modes untouched")
+	}
+}
+
+func TestLatencyWindow(t *testing.T) {
+	m := newTestMatchmaker(t, nil)
+
+	a, _ := m.Enqueue(Request{Mode: "ffa", Players: []string{"a"}, Latency: 20 * time.Millisecond})
+	m.Enqueue(Request{Mode: "ffa", Players: []string{"b"}, Latency: 180 * time.Millisecond})
+
+	m.tick(a.EnqueuedAt)
+	if m.Status("a").State != StateQueued {
+		t.Fatal("expected no match outside the latency window")
+	}
+	m.tick(a.EnqueuedAt.Add(20 * time.Second))
+	if m.Status("a").State != StateMatched {
+		t.Error("expected match once the latency window widened")
+	}
+}
+
+func TestParties(t *testing.T) {
+	m := newTestMatchmaker(t, nil)
+	m.config.MatchSize = 4
+
+	if _, err := m.Enqueue(Request{Mode: "ctf", Players: []string{"1", "2", "3", "4", "5"}}); err != ErrPartyTooLarge {
+		t.Errorf("expected ErrPartyTooLarge, got %v", err)
+	}
+
+	party, _ := m.Enqueue(Request{Mode: "ctf", Players: []string{"a", "b", "c"}})
+	if _, err := m.Enqueue(Request{Mode: "ctf", Players: []string{"b"}}); err != ErrAlreadyQueued {
+		t.Errorf("expected ErrAlreadyQueued, got %v", err)
+	}
+	m.Enqueue(Request{Mode: "ctf", Players: []string{"d", "e"}})
+	m.Enqueue(Request{Mode: "ctf", Players: []string{"f"}})
+
+	m.tick(party.EnqueuedAt)
+	match := m.Status("a").Match
+	if match == nil || len(match.Players) != 4 {
+		t.Fatalf("expected party matched with the solo player, got %+v", match)
+	}
+	if m.Status("f").Match != match {
+		t.Error("expected the pair skipped for the solo player that fits")
+	}
+	if m.Status("d").State != StateQueued {
+		t.Error("expected the pair still queued")
+	}
+}
+
+func TestWaitAndCancel(t *testing.T) {
+	m := newTestMatchmaker(t, nil)
+
+	a, _ := m.Enqueue(Request{Mode: "ffa", Players: []string{"a"}})
+	got := make(chan Status, 1)
+	go func() {
+		status, _ := m.Wait(context.Background(), "a")
+		got <- status
+	}()
+
+	m.Enqueue(Request{Mode: "ffa", Players: []string{"b"}})
+	m.tick(a.EnqueuedAt)
+	select {
+	case status := <-got:
+		if status.State != StateMatched {
+			t.Errorf("expected matched, got %s", status.State)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Wait never returned")
+	}
+
+	m.Enqueue(Request{Mode: "ffa", Players: []string{"c"}})
+	if err := m.Cancel("c"); err != nil {
+		t.Fatalf("cancel failed: %v", err)
+	}
+	if m.QueueLength("ffa") != 0 || m.Status("c").State != StateIdle {
+		t.Error("expected cancelled ticket gone")
+	}
+	if err := m.Cancel("c"); err != ErrNotQueued {
+		t.Errorf("expected ErrNotQueued, got %v", err)
+	}
+}
+
+func TestFailures(t *testing.T) {
+	errNoServer := errors.New("no server")
+	m := newTestMatchmaker(t, func(string, []string) (string, error) {
+		return "", errNoServer
+	})
+
+	a, _ := m.Enqueue(Request{Mode: "ffa", Players: []string{"a"}})
+	m.Enqueue(Request{Mode: "ffa", Players: []string{"b"}})
+	m.tick(a.EnqueuedAt)
+	if status := m.Status("a"); status.State != StateFailed || status.Error != errNoServer.Error() {
+		t.Errorf("expected room failure reported, got %+v", status)
+	}
+
+	if _, err := m.FindMatch("ffa", "c", 0); err != ErrWaitingForMatch {
+		t.Errorf("expected ErrWaitingForMatch, got %v", err)
+	}
+	m.tick(time.Now().Add(m.config.TicketTTL + time.Second))
+	if status := m.Status("c"); status.State != StateFailed || status.Error != ErrTicketExpired.Error() {
+		t.Errorf("expected expired ticket, got %+v", status)
+	}
+}
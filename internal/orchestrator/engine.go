@@ -0,0 +1,544 @@
+package orchestrator
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/LemmyAI/gameserver/internal/auth"
+	"github.com/LemmyAI/gameserver/internal/game"
+	"github.com/LemmyAI/gameserver/internal/protocol"
+	"github.com/LemmyAI/gameserver/internal/protocol/gamepb"
+	"github.com/LemmyAI/gameserver/internal/room"
+	"github.com/LemmyAI/gameserver/internal/transport"
+)
+
+// EngineServer runs a room's game engine and answers protocol messages
+// from whatever transport delivers them: hellos, inputs, leaves and room
+// control. It's the game server behind cmd/server, in-process launches
+// and embedded engines alike.
+type EngineServer struct {
+	engine      *game.Engine
+	broadcaster game.Broadcaster
+	sessions    *auth.Signer // Verifies session tokens (nil = open server)
+	roomID      string
+	phase       room.Phase           // Inputs are only accepted in room.PhaseInGame
+	players     map[string]string    // playerID -> addr (multiple players per addr OK)
+	spectators  map[string]string    // spectatorID -> addr
+	teams       map[string]uint32    // Assigned before the player's hello
+	kicked      map[string]time.Time // playerID -> when anti-cheat kicked them
+	mu          sync.Mutex
+}
+
+// KickCooldown is how long a player kicked by anti-cheat is refused
+// when they say hello again.
+const KickCooldown = 10 * time.Minute
+
+// NewEngineServer creates the engine for a spec's settings, phase and
+// spectator delay. A spec without a phase starts in game.
+func NewEngineServer(spec Spec, broadcaster game.Broadcaster) *EngineServer {
+	phase := spec.Phase
+	if phase == "" {
+		phase = room.PhaseInGame
+	}
+	config := GameConfig(spec.Settings)
+	config.SpectatorDelay = spec.SpectatorDelay
+	s := &EngineServer{
+		engine:      game.NewEngine(config, broadcaster),
+		broadcaster: broadcaster,
+		roomID:      spec.RoomID,
+		phase:       phase,
+		players:     make(map[string]string),
+		spectators:  make(map[string]string),
+		teams:       make(map[string]uint32),
+		kicked:      make(map[string]time.Time),
+	}
+	s.engine.OnViolation(s.handleViolation)
+	if r := spec.Settings.VisionRadius; r > 0 {
+		s.engine.SetTeamRules(game.TeamRules{CanSee: game.TeammatesOrWithin(r)})
+	}
+	return s
+}
+
+// GameConfig returns the default game config with the room's settings
+// applied
+func GameConfig(s room.Settings) game.Config {
+	config := game.DefaultConfig()
+	if s.TickRate > 0 {
+		config.TickRate = s.TickRate
+	}
+	if s.MaxPlayers > 0 {
+		config.MaxPlayers = s.MaxPlayers
+	}
+	if s.WorldWidth > 0 {
+		config.WorldWidth = s.WorldWidth
+	}
+	if s.WorldHeight > 0 {
+		config.WorldHeight = s.WorldHeight
+	}
+	if s.PlayerSpeed > 0 {
+		config.PlayerSpeed = s.PlayerSpeed
+	}
+	return config
+}
+
+// RequireSessions makes hellos and room control carry tokens signed by
+// signer. Call before the server handles any messages.
+func (s *EngineServer) RequireSessions(signer *auth.Signer) {
+	s.sessions = signer
+}
+
+// Attach routes a transport's traffic to the server
+func (s *EngineServer) Attach(t transport.Transport) {
+	t.OnMessage(func(addr string, data []byte, reliable bool) {
+		msg, err := protocol.Decode(data)
+		if err != nil {
+			log.Printf("⚠️  [%s] invalid protobuf: %v", addr, err)
+			return
+		}
+		s.Handle(addr, msg)
+	})
+	t.OnConnect(s.Connect)
+	t.OnDisconnect(s.Disconnect)
+}
+
+// Engine returns the game engine
+func (s *EngineServer) Engine() *game.Engine {
+	return s.engine
+}
+
+// Start starts the tick loop
+func (s *EngineServer) Start() {
+	s.engine.Start()
+}
+
+// Stop stops the tick loop
+func (s *EngineServer) Stop() {
+	s.engine.Stop()
+}
+
+// Phase returns the room phase the server is in
+func (s *EngineServer) Phase() room.Phase {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.phase
+}
+
+// Handle applies a message that arrived from addr
+func (s *EngineServer) Handle(addr string, msg *gamepb.Message) {
+	switch payload := msg.Payload.(type) {
+	case *gamepb.Message_ClientHello:
+		s.handleHello(addr, payload.ClientHello)
+	case *gamepb.Message_PlayerInput:
+		s.handleInput(addr, payload.PlayerInput)
+	case *gamepb.Message_PlayerLeave:
+		s.handleLeave(addr, payload.PlayerLeave)
+	case *gamepb.Message_RoomControl:
+		s.handleRoomControl(addr, payload.RoomControl)
+	case *gamepb.Message_Chat:
+		// Chat goes through the room, which moderates it and keeps the
+		// history; game servers don't relay it
+	case *gamepb.Message_Heartbeat:
+		// Arriving kept addr alive; nothing else to do
+	default:
+		log.Printf("❓ [%s] unknown message type: %s", addr, protocol.MessageTypeName(msg))
+	}
+}
+
+// Connect reattaches players in their reconnect grace period whose
+// address is heard from again
+func (s *EngineServer) Connect(addr string) {
+	s.mu.Lock()
+	var frozen []string
+	for playerID, playerAddr := range s.players {
+		if playerAddr == addr && s.engine.IsDisconnected(playerID) {
+			frozen = append(frozen, playerID)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, playerID := range frozen {
+		if s.engine.ReattachPlayer(playerID, addr) != nil {
+			s.engine.SendViewSnapshot(playerID, addr)
+		}
+	}
+}
+
+// Disconnect freezes every player bound to addr for the reconnect grace
+// period and drops its spectators
+func (s *EngineServer) Disconnect(addr string) {
+	s.mu.Lock()
+	var disconnected []string
+	for playerID, playerAddr := range s.players {
+		if playerAddr != addr {
+			continue
+		}
+		if s.engine.State().GetPlayer(playerID) == nil {
+			// Grace period already expired
+			delete(s.players, playerID)
+			continue
+		}
+		disconnected = append(disconnected, playerID)
+	}
+	var spectators []string
+	for spectatorID, spectatorAddr := range s.spectators {
+		if spectatorAddr == addr {
+			spectators = append(spectators, spectatorID)
+			delete(s.spectators, spectatorID)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, playerID := range disconnected {
+		s.engine.DisconnectPlayer(playerID)
+	}
+	for _, spectatorID := range spectators {
+		s.engine.RemoveSpectator(spectatorID)
+	}
+}
+
+func (s *EngineServer) handleHello(addr string, hello *gamepb.ClientHello) {
+	if hello.ResumeToken != "" {
+		if s.resumePlayer(addr, hello) {
+			return
+		}
+		// Fall through and treat as a fresh join
+	}
+
+	playerID, verified, spectatorOnly := s.authenticate(addr, hello)
+	if playerID == "" {
+		return
+	}
+
+	if hello.Spectator {
+		s.handleSpectatorHello(addr, playerID, hello)
+		return
+	}
+	if spectatorOnly {
+		log.Printf("❌ [%s] spectator token used to join as player %s", addr, playerID)
+		return
+	}
+	if s.recentlyKicked(playerID) {
+		log.Printf("❌ [%s] %s was kicked, not letting them back yet", addr, playerID)
+		return
+	}
+
+	s.mu.Lock()
+	boundAddr, exists := s.players[playerID]
+	s.mu.Unlock()
+
+	if exists && s.engine.State().GetPlayer(playerID) != nil {
+		// Only a verified token may move a player to a new address
+		if boundAddr != addr && !verified {
+			log.Printf("❌ [%s] hello for %s bound to %s rejected", addr, playerID, boundAddr)
+			return
+		}
+		s.mu.Lock()
+		s.players[playerID] = addr
+		s.mu.Unlock()
+		return
+	}
+
+	player := s.engine.AddPlayerWithID(hello.PlayerName, playerID, addr)
+	if player == nil {
+		log.Printf("❌ [%s] server full or ID conflict", addr)
+		return
+	}
+
+	s.mu.Lock()
+	s.players[playerID] = addr
+	team := s.teams[playerID]
+	s.mu.Unlock()
+
+	if team != game.NoTeam {
+		s.engine.SetTeam(playerID, team)
+	}
+
+	welcome := protocol.NewServerWelcome(
+		player.ID,
+		uint32(s.engine.State().Config().TickRate),
+		uint64(time.Now().UnixMilli()),
+		s.engine.ResumeToken(player.ID),
+		false,
+	)
+	if err := s.broadcaster.SendTo(addr, welcome); err != nil {
+		log.Printf("❌ send welcome: %v", err)
+		return
+	}
+	// Deltas only carry who changed; this is everyone they can see now
+	s.engine.SendViewSnapshot(player.ID, addr)
+
+	log.Printf("👋 [%s] Welcome to %s (id=%s)", addr, hello.PlayerName, player.ID)
+}
+
+// authenticate determines the player ID for a hello.
+// With a session signer configured the ID comes from a verified token;
+// otherwise the client-chosen ID is used. Returns "" if rejected.
+func (s *EngineServer) authenticate(addr string, hello *gamepb.ClientHello) (playerID string, verified, spectatorOnly bool) {
+	if s.sessions == nil {
+		if hello.PlayerId == "" {
+			log.Printf("❌ [%s] empty player ID", addr)
+		}
+		return hello.PlayerId, false, false
+	}
+
+	claims, err := s.sessions.Verify(hello.SessionToken)
+	if err != nil {
+		log.Printf("❌ [%s] session token rejected: %v", addr, err)
+		return "", false, false
+	}
+	if claims.IsControl() {
+		log.Printf("❌ [%s] control token used in a hello", addr)
+		return "", false, false
+	}
+	if s.roomID != "" && claims.RoomID != s.roomID {
+		log.Printf("❌ [%s] session token for room %s, not %s", addr, claims.RoomID, s.roomID)
+		return "", false, false
+	}
+	if hello.PlayerId != "" && hello.PlayerId != claims.PlayerID {
+		log.Printf("❌ [%s] session token for %s, hello claims %s", addr, claims.PlayerID, hello.PlayerId)
+		return "", false, false
+	}
+	return claims.PlayerID, true, claims.IsSpectator()
+}
+
+// handleSpectatorHello registers (or updates) a spectator.
+// Re-sending the hello changes which player the spectator follows.
+func (s *EngineServer) handleSpectatorHello(addr, spectatorID string, hello *gamepb.ClientHello) {
+	s.mu.Lock()
+	_, isPlayer := s.players[spectatorID]
+	boundAddr, exists := s.spectators[spectatorID]
+	s.mu.Unlock()
+
+	if isPlayer {
+		log.Printf("❌ [%s] %s is already playing", addr, spectatorID)
+		return
+	}
+	if exists && boundAddr != addr && s.sessions == nil {
+		log.Printf("❌ [%s] spectator %s bound to %s rejected", addr, spectatorID, boundAddr)
+		return
+	}
+
+	// A repeat hello from the same address only changes who they follow
+	if !exists || boundAddr != addr {
+		if s.engine.AddSpectator(spectatorID, addr) == nil {
+			log.Printf("❌ [%s] spectator ID conflict: %s", addr, spectatorID)
+			return
+		}
+	}
+	if err := s.engine.FollowPlayer(spectatorID, hello.FollowPlayerId); err != nil {
+		log.Printf("⚠️  [%s] cannot follow %s: %v", addr, hello.FollowPlayerId, err)
+	}
+
+	s.mu.Lock()
+	s.spectators[spectatorID] = addr
+	s.mu.Unlock()
+
+	if exists {
+		return
+	}
+
+	welcome := protocol.NewServerWelcome(
+		spectatorID,
+		uint32(s.engine.State().Config().TickRate),
+		uint64(time.Now().UnixMilli()),
+		"",
+		false,
+	)
+	welcome.GetServerWelcome().Spectator = true
+	if err := s.broadcaster.SendTo(addr, welcome); err != nil {
+		log.Printf("❌ send welcome: %v", err)
+		return
+	}
+
+	log.Printf("👀 [%s] Welcome spectator %s (id=%s)", addr, hello.PlayerName, spectatorID)
+}
+
+// resumePlayer rebinds a player using their resume token.
+// Returns false if the token was rejected; kicks forget a player's
+// tokens, so a kicked player falls through to the cooldown check.
+func (s *EngineServer) resumePlayer(addr string, hello *gamepb.ClientHello) bool {
+	player, token, err := s.engine.ResumePlayer(hello.ResumeToken, addr)
+	if err != nil {
+		log.Printf("⚠️  [%s] resume failed: %v", addr, err)
+		return false
+	}
+
+	s.mu.Lock()
+	s.players[player.ID] = addr
+	s.mu.Unlock()
+
+	welcome := protocol.NewServerWelcome(
+		player.ID,
+		uint32(s.engine.State().Config().TickRate),
+		uint64(time.Now().UnixMilli()),
+		token,
+		true,
+	)
+	if err := s.broadcaster.SendTo(addr, welcome); err != nil {
+		log.Printf("❌ send welcome: %v", err)
+	}
+	s.engine.SendViewSnapshot(player.ID, addr)
+
+	log.Printf("🔁 [%s] Resumed %s (id=%s)", addr, player.Name, player.ID)
+	return true
+}
+
+func (s *EngineServer) handleInput(addr string, input *gamepb.PlayerInput) {
+	s.mu.Lock()
+	boundAddr, exists := s.players[input.PlayerId]
+	phase := s.phase
+	s.mu.Unlock()
+	if !exists || boundAddr != addr {
+		return
+	}
+
+	// Players can't move outside of a running game
+	if phase != room.PhaseInGame {
+		return
+	}
+
+	s.engine.ApplyInput(input.PlayerId, game.Input{
+		Sequence:  input.Sequence,
+		Timestamp: input.Timestamp,
+		Movement: game.Vec2{
+			X: input.Movement.GetX(),
+			Y: input.Movement.GetY(),
+		},
+		Jump:    input.Jump,
+		Action1: input.GetAction_1(),
+		Action2: input.GetAction_2(),
+	})
+}
+
+// handleLeave removes a player who left for good, skipping the reconnect
+// grace period, or a spectator who stopped watching
+func (s *EngineServer) handleLeave(addr string, leave *gamepb.PlayerLeave) {
+	s.mu.Lock()
+	boundAddr, exists := s.players[leave.PlayerId]
+	if exists && boundAddr == addr {
+		delete(s.players, leave.PlayerId)
+	}
+	spectatorAddr, spectating := s.spectators[leave.PlayerId]
+	if spectating && spectatorAddr == addr {
+		delete(s.spectators, leave.PlayerId)
+	}
+	s.mu.Unlock()
+
+	if spectating && spectatorAddr == addr {
+		s.engine.RemoveSpectator(leave.PlayerId)
+		return
+	}
+	if !exists || boundAddr != addr {
+		return
+	}
+	reason := leave.Reason
+	if reason == "" {
+		reason = "left"
+	}
+	s.engine.RemovePlayerWithReason(leave.PlayerId, reason)
+}
+
+// handleRoomControl applies a control message from the room owner.
+// With session tokens required it must carry a control token for this room.
+func (s *EngineServer) handleRoomControl(addr string, ctrl *gamepb.RoomControl) {
+	if s.sessions != nil {
+		claims, err := s.sessions.Verify(ctrl.Token)
+		if err != nil || !claims.IsControl() || (s.roomID != "" && claims.RoomID != s.roomID) {
+			log.Printf("❌ [%s] room control rejected", addr)
+			return
+		}
+	}
+
+	if ctrl.Phase != "" {
+		phase, ok := room.ParsePhase(ctrl.Phase)
+		if !ok {
+			log.Printf("⚠️  [%s] unknown room phase: %s", addr, ctrl.Phase)
+			return
+		}
+
+		s.mu.Lock()
+		from := s.phase
+		s.phase = phase
+		s.mu.Unlock()
+
+		if from != phase {
+			log.Printf("🚦 Room phase: %s → %s", from, phase)
+		}
+	}
+
+	if ctrl.KickPlayerId != "" {
+		s.kick(ctrl.KickPlayerId, ctrl.KickReason)
+	}
+
+	for _, t := range ctrl.Teams {
+		s.mu.Lock()
+		s.teams[t.PlayerId] = t.Team
+		s.mu.Unlock()
+
+		// Players who haven't joined yet get their team on hello
+		if p := s.engine.State().GetPlayer(t.PlayerId); p != nil && p.Team != t.Team {
+			s.engine.SetTeam(t.PlayerId, t.Team)
+			log.Printf("🏳️  %s → team %d", t.PlayerId, t.Team)
+		}
+	}
+}
+
+// kick removes a player or spectator on the room owner's request
+func (s *EngineServer) kick(id, reason string) {
+	s.mu.Lock()
+	_, isPlayer := s.players[id]
+	_, isSpectator := s.spectators[id]
+	delete(s.players, id)
+	delete(s.spectators, id)
+	s.mu.Unlock()
+
+	if isSpectator {
+		s.engine.RemoveSpectator(id)
+	}
+	if isPlayer {
+		if reason == "" {
+			reason = "removed by host"
+		}
+		log.Printf("🥾 Kicking %s: %s", id, reason)
+		s.engine.RemovePlayerWithReason(id, "kicked: "+reason)
+	}
+}
+
+// handleViolation enforces the anti-cheat policy decision for a player
+func (s *EngineServer) handleViolation(v game.Violation) {
+	if v.Action != game.ActionKick {
+		return
+	}
+
+	s.mu.Lock()
+	addr, exists := s.players[v.PlayerID]
+	delete(s.players, v.PlayerID)
+	s.kicked[v.PlayerID] = time.Now()
+	s.mu.Unlock()
+
+	log.Printf("🥾 Kicking %s for %s violations (score %.1f)", v.PlayerID, v.Kind, v.Score)
+	reason := "kicked: " + v.Kind.String()
+	s.engine.RemovePlayerWithReason(v.PlayerID, reason)
+
+	// The leave only went to the players still in the game; tell the
+	// kicked player's client too, or it never learns why it went quiet
+	if exists {
+		if err := s.broadcaster.SendTo(addr, protocol.NewPlayerLeave(v.PlayerID, reason)); err != nil {
+			log.Printf("❌ send leave: %v", err)
+		}
+	}
+}
+
+// recentlyKicked reports whether anti-cheat kicked a player less than
+// KickCooldown ago, forgetting older kicks.
+func (s *EngineServer) recentlyKicked(playerID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	at, ok := s.kicked[playerID]
+	if ok && time.Since(at) >= KickCooldown {
+		delete(s.kicked, playerID)
+		return false
+	}
+	return ok
+}
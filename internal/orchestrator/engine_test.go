@@ -0,0 +1,327 @@
+package orchestrator
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/LemmyAI/gameserver/internal/auth"
+	"github.com/LemmyAI/gameserver/internal/game"
+	"github.com/LemmyAI/gameserver/internal/protocol"
+	"github.com/LemmyAI/gameserver/internal/protocol/gamepb"
+	"github.com/LemmyAI/gameserver/internal/room"
+	"github.com/LemmyAI/gameserver/internal/transport"
+)
+
+// recordingBroadcaster keeps every message the engine sends
+type recordingBroadcaster struct {
+	msgs []*gamepb.Message
+	mu   sync.Mutex
+}
+
+func (r *recordingBroadcaster) Broadcast(msg *gamepb.Message, excludeID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.msgs = append(r.msgs, msg)
+	return nil
+}
+
+func (r *recordingBroadcaster) SendTo(addr string, msg *gamepb.Message) error {
+	return r.Broadcast(msg, "")
+}
+
+func (r *recordingBroadcaster) welcomes() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for _, msg := range r.msgs {
+		if msg.GetServerWelcome() != nil {
+			n++
+		}
+	}
+	return n
+}
+
+func TestEngineServerHello(t *testing.T) {
+	b := &recordingBroadcaster{}
+	s := NewEngineServer(Spec{RoomID: "r1", Phase: room.PhaseLobby}, b)
+
+	s.Handle("bridge", protocol.NewClientHello("p1", "Alice", "1.0"))
+	if s.Engine().State().GetPlayer("p1") == nil {
+		t.Fatal("expected player added on hello")
+	}
+	if b.welcomes() != 1 {
+		t.Fatalf("expected a welcome, got %d", b.welcomes())
+	}
+	b.mu.Lock()
+	last := b.msgs[len(b.msgs)-1]
+	b.mu.Unlock()
+	if len(last.GetStateSnapshot().GetPlayers()) != 1 {
+		t.Fatalf("expected a snapshot after the welcome, got %v", last)
+	}
+
+	// A repeated hello (e.g. after a restart) doesn't add the player twice
+	s.Handle("bridge", protocol.NewClientHello("p1", "Alice", "1.0"))
+	if b.welcomes() != 1 || s.Engine().PlayerCount() != 1 {
+		t.Error("expected repeated hello to be a no-op")
+	}
+}
+
+func TestEngineServerLeave(t *testing.T) {
+	s := NewEngineServer(Spec{RoomID: "r1"}, &recordingBroadcaster{})
+	s.Handle("bridge/p1", protocol.NewClientHello("p1", "Alice", "1.0"))
+	s.Handle("bridge/p2", protocol.NewClientHello("p2", "Bob", "1.0"))
+
+	// Only the player's own address can make them leave
+	s.Handle("bridge/p2", protocol.NewPlayerLeave("p1", ""))
+	if s.Engine().State().GetPlayer("p1") == nil {
+		t.Fatal("expected leave from another address ignored")
+	}
+	s.Handle("bridge/p1", protocol.NewPlayerLeave("p1", ""))
+	if s.Engine().State().GetPlayer("p1") != nil {
+		t.Error("expected p1 removed on leave")
+	}
+	if s.Engine().State().GetPlayer("p2") == nil {
+		t.Error("expected p2 to stay")
+	}
+}
+
+func TestEngineServerIgnoresChat(t *testing.T) {
+	b := &recordingBroadcaster{}
+	s := NewEngineServer(Spec{RoomID: "r1"}, b)
+	s.Handle("bridge/p1", protocol.NewClientHello("p1", "Alice", "1.0"))
+
+	// Chat goes through the room's moderation, never around it
+	s.Handle("bridge/p1", protocol.NewChatMessage("p1", "", "hi"))
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, msg := range b.msgs {
+		if msg.GetChat() != nil {
+			t.Fatal("expected the server not to relay chat")
+		}
+	}
+}
+
+func TestEngineServerVisionRadius(t *testing.T) {
+	s := NewEngineServer(Spec{RoomID: "r1", Settings: room.Settings{VisionRadius: 100}}, &recordingBroadcaster{})
+	if s.Engine().TeamRules().CanSee == nil {
+		t.Fatal("expected a vision radius to install a CanSee rule")
+	}
+	if NewEngineServer(Spec{RoomID: "r1"}, &recordingBroadcaster{}).Engine().TeamRules().CanSee != nil {
+		t.Error("expected everyone to see everyone without a vision radius")
+	}
+}
+
+func TestEngineServerSpectators(t *testing.T) {
+	s := NewEngineServer(Spec{RoomID: "r1"}, &recordingBroadcaster{})
+	s.Handle("bridge/p1", protocol.NewClientHello("p1", "Alice", "1.0"))
+	s.Handle("bridge/s1", protocol.NewSpectatorHello("s1", "Sam", "1.0", "", ""))
+	if s.Engine().Spectators().Get("s1") == nil || s.Engine().PlayerCount() != 1 {
+		t.Fatal("expected s1 watching without a player slot")
+	}
+
+	// Hello again to follow a player
+	s.Handle("bridge/s1", protocol.NewSpectatorHello("s1", "Sam", "1.0", "", "p1"))
+	if got := s.Engine().Spectators().Get("s1").Following; got != "p1" {
+		t.Errorf("expected s1 following p1, got %q", got)
+	}
+
+	// Only the spectator's own address can make them leave
+	s.Handle("bridge/p1", protocol.NewPlayerLeave("s1", ""))
+	if s.Engine().Spectators().Count() != 1 {
+		t.Fatal("expected leave from another address ignored")
+	}
+	s.Handle("bridge/s1", protocol.NewPlayerLeave("s1", ""))
+	if s.Engine().Spectators().Count() != 0 {
+		t.Error("expected s1 removed on leave")
+	}
+	if s.Engine().State().GetPlayer("p1") == nil {
+		t.Error("expected p1 to stay")
+	}
+}
+
+func TestEngineServerPhaseGatesInput(t *testing.T) {
+	s := NewEngineServer(Spec{RoomID: "r1", Phase: room.PhaseLobby}, &recordingBroadcaster{})
+	s.Start()
+	defer s.Stop()
+
+	s.Handle("bridge", protocol.NewClientHello("p1", "Alice", "1.0"))
+	start := s.Engine().State().GetPlayer("p1").Position
+
+	move := func() {
+		ts := uint64(time.Now().UnixMilli())
+		s.Handle("bridge", protocol.NewPlayerInput("p1", ts, ts, 1, 0, false, false, false))
+	}
+	moved := func() bool {
+		return s.Engine().State().GetPlayer("p1").Position != start
+	}
+
+	move()
+	time.Sleep(100 * time.Millisecond)
+	if moved() {
+		t.Fatal("expected no movement in the lobby")
+	}
+
+	s.Handle("bridge", protocol.NewRoomControl("", string(room.PhaseInGame)))
+	if s.Phase() != room.PhaseInGame {
+		t.Fatalf("expected in-game phase, got %s", s.Phase())
+	}
+	deadline := time.Now().Add(time.Second)
+	for !moved() && time.Now().Before(deadline) {
+		move()
+		time.Sleep(20 * time.Millisecond)
+	}
+	if !moved() {
+		t.Error("expected movement once in game")
+	}
+
+	// The host can kick players through room control
+	s.Handle("elsewhere", protocol.NewKickControl("", "p1", "bye"))
+	if s.Engine().State().GetPlayer("p1") != nil {
+		t.Error("expected kicked player removed")
+	}
+}
+
+func TestEngineServerTeams(t *testing.T) {
+	s := NewEngineServer(Spec{RoomID: "r1"}, &recordingBroadcaster{})
+
+	// Teams assigned before the hello apply on join
+	s.Handle("bridge", protocol.NewTeamControl("", map[string]uint32{"p1": 2}))
+	s.Handle("bridge", protocol.NewClientHello("p1", "Alice", "1.0"))
+	if team := s.Engine().State().GetPlayer("p1").Team; team != 2 {
+		t.Errorf("expected team 2, got %d", team)
+	}
+}
+
+func TestEngineServerSessions(t *testing.T) {
+	signer := auth.NewSigner([]byte("secret"))
+	s := NewEngineServer(Spec{RoomID: "r1", Phase: room.PhaseLobby}, &recordingBroadcaster{})
+	s.RequireSessions(signer)
+
+	// Client-chosen IDs and tokens for other rooms are turned away
+	s.Handle("a", protocol.NewClientHello("p1", "Alice", "1.0"))
+	s.Handle("a", protocol.NewSessionHello("p1", "Alice", "1.0", signer.Mint("p1", "r2", time.Minute)))
+	if s.Engine().PlayerCount() != 0 {
+		t.Fatal("expected hellos without a valid token rejected")
+	}
+	s.Handle("a", protocol.NewSessionHello("", "Alice", "1.0", signer.Mint("p1", "r1", time.Minute)))
+	if s.Engine().State().GetPlayer("p1") == nil {
+		t.Fatal("expected token holder added")
+	}
+
+	// Spectator tokens only let their holder watch
+	watcher := signer.MintRole("w1", "r1", auth.RoleSpectator, time.Minute)
+	s.Handle("b", protocol.NewSessionHello("w1", "Wes", "1.0", watcher))
+	if s.Engine().State().GetPlayer("w1") != nil {
+		t.Fatal("expected spectator token refused a player slot")
+	}
+	s.Handle("b", protocol.NewSpectatorHello("w1", "Wes", "1.0", watcher, "p1"))
+	if sp := s.Engine().Spectators().Get("w1"); sp == nil || sp.Following != "p1" {
+		t.Fatalf("expected w1 watching p1, got %+v", sp)
+	}
+
+	// Room control needs a control token
+	s.Handle("c", protocol.NewRoomControl("", string(room.PhaseInGame)))
+	if s.Phase() != room.PhaseLobby {
+		t.Fatal("expected room control without a token ignored")
+	}
+	s.Handle("c", protocol.NewRoomControl(signer.MintRole("", "r1", auth.RoleControl, time.Minute), string(room.PhaseInGame)))
+	if s.Phase() != room.PhaseInGame {
+		t.Error("expected room control with a control token applied")
+	}
+}
+
+func TestEngineServerKickCooldown(t *testing.T) {
+	signer := auth.NewSigner([]byte("secret"))
+	s := NewEngineServer(Spec{RoomID: "r1"}, &recordingBroadcaster{})
+	s.RequireSessions(signer)
+	token := signer.Mint("p1", "r1", time.Minute)
+	s.Handle("a", protocol.NewSessionHello("p1", "Alice", "1.0", token))
+
+	s.handleViolation(game.Violation{PlayerID: "p1", Kind: game.ViolationSpeed, Action: game.ActionKick})
+	if s.Engine().State().GetPlayer("p1") != nil {
+		t.Fatal("expected p1 kicked")
+	}
+
+	// Their session token doesn't bring them straight back
+	s.Handle("a", protocol.NewSessionHello("p1", "Alice", "1.0", token))
+	if s.Engine().State().GetPlayer("p1") != nil {
+		t.Fatal("expected kicked player refused during the cooldown")
+	}
+
+	s.mu.Lock()
+	s.kicked["p1"] = time.Now().Add(-KickCooldown)
+	s.mu.Unlock()
+	s.Handle("a", protocol.NewSessionHello("p1", "Alice", "1.0", token))
+	if s.Engine().State().GetPlayer("p1") == nil {
+		t.Error("expected p1 let back in after the cooldown")
+	}
+}
+
+func TestEngineServerDisconnect(t *testing.T) {
+	s := NewEngineServer(Spec{RoomID: "r1"}, &recordingBroadcaster{})
+	s.Handle("a", protocol.NewClientHello("p1", "Alice", "1.0"))
+
+	// A silent address is held for the grace period, not removed
+	s.Disconnect("a")
+	if !s.Engine().IsDisconnected("p1") || s.Engine().State().GetPlayer("p1") == nil {
+		t.Fatal("expected p1 frozen")
+	}
+	s.Connect("a")
+	if s.Engine().IsDisconnected("p1") {
+		t.Error("expected p1 reattached when its address is heard from")
+	}
+}
+
+func TestEngineServerHeartbeat(t *testing.T) {
+	config := transport.DefaultConfig()
+	config.IdleTimeout = 200 * time.Millisecond
+	tr := transport.NewUDPTransport(config)
+	broadcaster := game.NewTransportBroadcaster(nil, tr.SendUnreliable)
+	s := NewEngineServer(Spec{RoomID: "r1"}, broadcaster)
+	broadcaster.SetState(s.Engine().State())
+	s.Attach(tr)
+	if err := tr.Listen("127.0.0.1:0"); err != nil {
+		t.Fatal(err)
+	}
+	defer tr.Close()
+
+	dial := func(playerID string) *net.UDPConn {
+		addr, _ := net.ResolveUDPAddr("udp", tr.LocalAddr())
+		conn, err := net.DialUDP("udp", nil, addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		send(t, conn, protocol.NewClientHello(playerID, playerID, "1.0"))
+		return conn
+	}
+	idle := dial("idle")
+	defer idle.Close()
+	silent := dial("silent")
+	defer silent.Close()
+
+	// The idle player sends nothing but heartbeats for well past IdleTimeout
+	for end := time.Now().Add(3 * config.IdleTimeout); time.Now().Before(end); {
+		send(t, idle, protocol.NewHeartbeat(uint64(time.Now().UnixMilli())))
+		time.Sleep(config.IdleTimeout / 4)
+	}
+
+	if s.Engine().State().GetPlayer("idle") == nil || s.Engine().IsDisconnected("idle") {
+		t.Error("expected the heartbeating player still in the game")
+	}
+	if !s.Engine().IsDisconnected("silent") {
+		t.Error("expected the silent player frozen")
+	}
+}
+
+func send(t *testing.T, conn *net.UDPConn, msg *gamepb.Message) {
+	t.Helper()
+	data, err := protocol.Encode(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Write(data); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,83 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/LemmyAI/gameserver/internal/auth"
+	"github.com/LemmyAI/gameserver/internal/game"
+	"github.com/LemmyAI/gameserver/internal/transport"
+)
+
+// InProcessLauncher runs each game server as an EngineServer in this
+// process, listening on the spec's UDP port. It's for tests and local
+// development. Session tokens are required if the spec's Env sets
+// SESSION_SECRET, as for a process.
+type InProcessLauncher struct{}
+
+// Launch starts the engine and its UDP listener
+func (l *InProcessLauncher) Launch(ctx context.Context, spec Spec) (Instance, error) {
+	if spec.RoomID == "" || spec.Port == 0 {
+		return nil, ErrInvalidSpec
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, ErrNotReady
+	}
+
+	t := transport.NewUDPTransport(transport.DefaultConfig())
+	broadcaster := game.NewTransportBroadcaster(nil, t.SendUnreliable)
+	s := &engineServer{
+		EngineServer: NewEngineServer(spec, broadcaster),
+		transport:    t,
+		addr:         fmt.Sprintf("127.0.0.1:%d", spec.Port),
+		done:         make(chan struct{}),
+	}
+	broadcaster.SetState(s.Engine().State())
+	if secret := spec.getenv("SESSION_SECRET"); secret != "" {
+		s.RequireSessions(auth.NewSigner([]byte(secret)))
+	}
+	s.Attach(t)
+
+	if err := t.Listen(fmt.Sprintf(":%d", spec.Port)); err != nil {
+		return nil, fmt.Errorf("failed to listen: %w", err)
+	}
+	s.Start()
+	log.Printf("🧪 In-process game server for room %s on UDP :%d", spec.RoomID, spec.Port)
+	return s, nil
+}
+
+// engineServer is an EngineServer behind a UDP listener
+type engineServer struct {
+	*EngineServer
+	transport *transport.UDPTransport
+	addr      string
+	done      chan struct{}
+	stopOnce  sync.Once
+}
+
+func (s *engineServer) Addr() string          { return s.addr }
+func (s *engineServer) PID() int              { return 0 }
+func (s *engineServer) Done() <-chan struct{} { return s.done }
+
+func (s *engineServer) ExitStatus() string {
+	select {
+	case <-s.done:
+		return "stopped"
+	default:
+		return ""
+	}
+}
+
+// Stop stops the engine and closes the listener; grace is unused since
+// nothing can ignore it
+func (s *engineServer) Stop(grace time.Duration) error {
+	s.stopOnce.Do(func() {
+		s.EngineServer.Stop()
+		s.transport.Close()
+		close(s.done)
+	})
+	return nil
+}
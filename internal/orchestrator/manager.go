@@ -0,0 +1,298 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/LemmyAI/gameserver/internal/auth"
+)
+
+// ManagerConfig configures a Manager
+type ManagerConfig struct {
+	Host         string        // Host advertised in server addresses
+	SpawnTimeout time.Duration // How long a server has to become ready
+	StopGrace    time.Duration // How long a server gets to exit before it's killed
+	Env          []string      // Added to every spec's Env, e.g. SESSION_SECRET
+}
+
+// DefaultManagerConfig returns sensible defaults
+func DefaultManagerConfig() ManagerConfig {
+	return ManagerConfig{
+		Host:         "127.0.0.1",
+		SpawnTimeout: 5 * time.Second,
+		StopGrace:    5 * time.Second,
+	}
+}
+
+// ServerInfo describes a game server held by a Manager
+type ServerInfo struct {
+	RoomID    string    `json:"roomId"`
+	Addr      string    `json:"addr"` // UDP address players connect to
+	Port      int       `json:"port"`
+	PID       int       `json:"pid,omitempty"`
+	StartedAt time.Time `json:"startedAt"`
+}
+
+// managed is a server the manager launched
+type managed struct {
+	info     ServerInfo
+	instance Instance
+}
+
+// Manager allocates game servers to rooms on a port pool and releases them
+// when asked or when they exit. It serves the server manager HTTP API that
+// RemoteLauncher talks to.
+type Manager struct {
+	config   ManagerConfig
+	launcher Launcher
+	ports    *PortPool
+	servers  map[string]*managed // roomID -> server
+	starting map[string]bool     // roomID -> launch in progress
+	mu       sync.Mutex
+}
+
+// NewManager creates a manager that launches servers with launcher on
+// ports from the pool
+func NewManager(config ManagerConfig, launcher Launcher, ports *PortPool) *Manager {
+	return &Manager{
+		config:   config,
+		launcher: launcher,
+		ports:    ports,
+		servers:  make(map[string]*managed),
+		starting: make(map[string]bool),
+	}
+}
+
+// Allocate launches a game server for the spec's room on a leased port,
+// ignoring any port in the spec
+func (m *Manager) Allocate(ctx context.Context, spec Spec) (ServerInfo, error) {
+	if spec.RoomID == "" {
+		return ServerInfo{}, ErrInvalidSpec
+	}
+
+	m.mu.Lock()
+	if _, exists := m.servers[spec.RoomID]; exists || m.starting[spec.RoomID] {
+		m.mu.Unlock()
+		return ServerInfo{}, ErrServerExists
+	}
+	m.starting[spec.RoomID] = true
+	m.mu.Unlock()
+	defer func() {
+		m.mu.Lock()
+		delete(m.starting, spec.RoomID)
+		m.mu.Unlock()
+	}()
+
+	port, err := m.ports.Acquire(spec.RoomID)
+	if err != nil {
+		return ServerInfo{}, err
+	}
+	spec.Port = port
+	spec.Env = append(spec.Env, m.config.Env...)
+
+	ctx, cancel := context.WithTimeout(ctx, m.config.SpawnTimeout)
+	defer cancel()
+	instance, err := m.launcher.Launch(ctx, spec)
+	if err != nil {
+		m.ports.Release(port)
+		return ServerInfo{}, err
+	}
+
+	s := &managed{
+		info: ServerInfo{
+			RoomID:    spec.RoomID,
+			Addr:      net.JoinHostPort(m.config.Host, strconv.Itoa(port)),
+			Port:      port,
+			PID:       instance.PID(),
+			StartedAt: time.Now(),
+		},
+		instance: instance,
+	}
+	m.mu.Lock()
+	m.servers[spec.RoomID] = s
+	m.mu.Unlock()
+	go m.reap(s)
+
+	log.Printf("🚀 Allocated game server for room %s on UDP :%d", spec.RoomID, port)
+	return s.info, nil
+}
+
+// reap forgets a server once it exits, whoever stopped it
+func (m *Manager) reap(s *managed) {
+	<-s.instance.Done()
+
+	m.mu.Lock()
+	if m.servers[s.info.RoomID] == s {
+		delete(m.servers, s.info.RoomID)
+		log.Printf("💥 Game server for room %s exited: %s", s.info.RoomID, s.instance.ExitStatus())
+	}
+	m.mu.Unlock()
+	m.ports.Release(s.info.Port)
+}
+
+// Release stops the room's game server
+func (m *Manager) Release(roomID string) error {
+	m.mu.Lock()
+	s, exists := m.servers[roomID]
+	delete(m.servers, roomID)
+	m.mu.Unlock()
+	if !exists {
+		return ErrNoServer
+	}
+
+	err := s.instance.Stop(m.config.StopGrace)
+	<-s.instance.Done()
+	log.Printf("🛑 Released game server for room %s", roomID)
+	return err
+}
+
+// Get returns the room's game server
+func (m *Manager) Get(roomID string) (ServerInfo, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, exists := m.servers[roomID]
+	if !exists {
+		return ServerInfo{}, false
+	}
+	return s.info, true
+}
+
+// List returns every game server, ordered by room ID
+func (m *Manager) List() []ServerInfo {
+	m.mu.Lock()
+	infos := make([]ServerInfo, 0, len(m.servers))
+	for _, s := range m.servers {
+		infos = append(infos, s.info)
+	}
+	m.mu.Unlock()
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].RoomID < infos[j].RoomID })
+	return infos
+}
+
+// Close stops every game server
+func (m *Manager) Close() {
+	var wg sync.WaitGroup
+	for _, info := range m.List() {
+		wg.Add(1)
+		go func(roomID string) {
+			defer wg.Done()
+			m.Release(roomID)
+		}(info.RoomID)
+	}
+	wg.Wait()
+}
+
+// Handler returns the server manager HTTP API. Everything but /health
+// must be signed with secret (see auth.SignRequest).
+//
+//	GET    /health           liveness
+//	GET    /servers          list game servers
+//	POST   /servers          allocate a server for a Spec
+//	GET    /servers/{roomId} one room's server
+//	DELETE /servers/{roomId} release a room's server
+func (m *Manager) Handler(secret string) http.Handler {
+	servers := http.NewServeMux()
+	servers.HandleFunc("/servers", m.handleServers)
+	servers.HandleFunc("/servers/", m.handleServer)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"status":  "ok",
+			"servers": len(m.List()),
+			"ports":   m.ports.Leases(),
+		})
+	})
+	mux.Handle("/servers", auth.RequireSignature(secret, servers))
+	mux.Handle("/servers/", auth.RequireSignature(secret, servers))
+	return mux
+}
+
+func (m *Manager) handleServers(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, m.List())
+
+	case http.MethodPost:
+		var spec Spec
+		if err := json.NewDecoder(r.Body).Decode(&spec); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		info, err := m.Allocate(r.Context(), spec)
+		if err != nil {
+			writeError(w, statusFor(err), err.Error())
+			return
+		}
+		writeJSON(w, http.StatusCreated, info)
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (m *Manager) handleServer(w http.ResponseWriter, r *http.Request) {
+	roomID := strings.TrimPrefix(r.URL.Path, "/servers/")
+	if roomID == "" || strings.Contains(roomID, "/") {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		info, exists := m.Get(roomID)
+		if !exists {
+			writeError(w, http.StatusNotFound, ErrNoServer.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, info)
+
+	case http.MethodDelete:
+		if err := m.Release(roomID); err != nil {
+			writeError(w, statusFor(err), err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"status": "released"})
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// statusFor maps orchestrator errors to HTTP statuses
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, ErrInvalidSpec):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrNoServer):
+		return http.StatusNotFound
+	case errors.Is(err, ErrServerExists):
+		return http.StatusConflict
+	case errors.Is(err, ErrNoFreePort):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, ErrNotReady), errors.Is(err, ErrExited):
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
@@ -0,0 +1,139 @@
+// Package orchestrator launches and stops game servers for rooms, as local
+// processes, in-process engines or through a remote server manager.
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/LemmyAI/gameserver/internal/room"
+)
+
+// Game servers serve HTTP (health, /ready) on their UDP port plus this
+const HTTPPortOffset = 1000
+
+// How often WaitReady polls /ready
+const readyPollInterval = 50 * time.Millisecond
+
+// Orchestrator errors
+var (
+	ErrNoFreePort    = errors.New("no free game server port")
+	ErrNotReady      = errors.New("game server did not become ready in time")
+	ErrExited        = errors.New("game server exited during startup")
+	ErrServerExists  = errors.New("room already has a game server")
+	ErrNoServer      = errors.New("room has no game server")
+	ErrInvalidSpec   = errors.New("invalid game server spec")
+	ErrRemoteRequest = errors.New("server manager request failed")
+)
+
+// Spec describes the game server a room needs
+type Spec struct {
+	RoomID   string        `json:"roomId"`
+	Port     int           `json:"port,omitempty"` // UDP port; remote managers pick their own
+	Phase    room.Phase    `json:"phase,omitempty"`
+	Settings room.Settings `json:"settings"`
+	Env      []string      `json:"-"` // Extra environment for local processes
+
+	// How far spectators lag behind players (anti-ghosting)
+	SpectatorDelay time.Duration `json:"spectatorDelay,omitempty"`
+}
+
+// Args returns the cmd/server flags for the spec
+func (s Spec) Args() []string {
+	args := []string{
+		"-udp", strconv.Itoa(s.Port),
+		"-http", strconv.Itoa(s.Port + HTTPPortOffset),
+		"-room", s.RoomID,
+	}
+	if s.Phase != "" {
+		args = append(args, "-phase", string(s.Phase))
+	}
+	if s.Settings.TickRate > 0 {
+		args = append(args, "-tick-rate", strconv.Itoa(s.Settings.TickRate))
+	}
+	if s.Settings.MaxPlayers > 0 {
+		args = append(args, "-max-players", strconv.Itoa(s.Settings.MaxPlayers))
+	}
+	if s.Settings.WorldWidth > 0 {
+		args = append(args, "-world-width", fmt.Sprint(s.Settings.WorldWidth))
+	}
+	if s.Settings.WorldHeight > 0 {
+		args = append(args, "-world-height", fmt.Sprint(s.Settings.WorldHeight))
+	}
+	if s.Settings.PlayerSpeed > 0 {
+		args = append(args, "-speed", fmt.Sprint(s.Settings.PlayerSpeed))
+	}
+	if s.Settings.VisionRadius > 0 {
+		args = append(args, "-vision-radius", fmt.Sprint(s.Settings.VisionRadius))
+	}
+	if s.SpectatorDelay > 0 {
+		args = append(args, "-spectator-delay", s.SpectatorDelay.String())
+	}
+	return args
+}
+
+// getenv returns a variable from the spec's Env
+func (s Spec) getenv(key string) string {
+	for _, kv := range s.Env {
+		if k, v, ok := strings.Cut(kv, "="); ok && k == key {
+			return v
+		}
+	}
+	return ""
+}
+
+// Instance is a launched game server
+type Instance interface {
+	// Addr is the server's UDP address (host:port)
+	Addr() string
+
+	// PID is the OS process ID, or 0 if the server isn't a local process
+	PID() int
+
+	// Done is closed when the server exits
+	Done() <-chan struct{}
+
+	// ExitStatus says why the server exited, once Done is closed
+	ExitStatus() string
+
+	// Stop asks the server to exit, forcing it after grace
+	Stop(grace time.Duration) error
+}
+
+// Launcher starts game servers. Launch returns once the server is ready
+// for players, or fails when ctx ends first.
+type Launcher interface {
+	Launch(ctx context.Context, spec Spec) (Instance, error)
+}
+
+// WaitReady polls a game server's /ready endpoint until it answers 200,
+// exited is closed, or ctx ends
+func WaitReady(ctx context.Context, httpAddr string, exited <-chan struct{}) error {
+	url := "http://" + httpAddr + "/ready"
+	client := &http.Client{Timeout: readyPollInterval * 4}
+	ticker := time.NewTicker(readyPollInterval)
+	defer ticker.Stop()
+
+	for {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if resp, err := client.Do(req); err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return nil
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-exited:
+			return ErrExited
+		case <-ctx.Done():
+			return ErrNotReady
+		}
+	}
+}
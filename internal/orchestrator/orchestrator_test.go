@@ -0,0 +1,206 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/LemmyAI/gameserver/internal/room"
+)
+
+// testPorts returns a small pool well away from the default range
+func testPorts() *PortPool {
+	return NewPortPool(19300, 50)
+}
+
+func TestSpecArgs(t *testing.T) {
+	spec := Spec{
+		RoomID:   "abc",
+		Port:     9100,
+		Phase:    room.PhaseLobby,
+		Settings: room.Settings{TickRate: 30, PlayerSpeed: 150, VisionRadius: 300},
+
+		SpectatorDelay: 2 * time.Second,
+	}
+	want := []string{
+		"-udp", "9100", "-http", "10100", "-room", "abc",
+		"-phase", "lobby", "-tick-rate", "30", "-speed", "150",
+		"-vision-radius", "300", "-spectator-delay", "2s",
+	}
+	if got := spec.Args(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestPortPoolLeases(t *testing.T) {
+	pool := NewPortPool(19200, 2)
+
+	a, err := pool.Acquire("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := pool.Acquire("b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a == b {
+		t.Fatal("expected distinct ports")
+	}
+	if _, err := pool.Acquire("c"); !errors.Is(err, ErrNoFreePort) {
+		t.Fatalf("expected ErrNoFreePort, got %v", err)
+	}
+
+	pool.Release(a)
+	if c, err := pool.Acquire("c"); err != nil || c != a {
+		t.Errorf("expected released port %d back, got %d (%v)", a, c, err)
+	}
+	if pool.Leases() != 2 {
+		t.Errorf("expected 2 leases, got %d", pool.Leases())
+	}
+}
+
+func TestInProcessLauncher(t *testing.T) {
+	ports := testPorts()
+	port, err := ports.Acquire("r1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var l InProcessLauncher
+	inst, err := l.Launch(context.Background(), Spec{RoomID: "r1", Port: port})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inst.PID() != 0 {
+		t.Error("expected no PID for an in-process server")
+	}
+
+	inst.Stop(time.Second)
+	select {
+	case <-inst.Done():
+	case <-time.After(time.Second):
+		t.Fatal("expected Done after Stop")
+	}
+	if inst.ExitStatus() == "" {
+		t.Error("expected an exit status")
+	}
+
+	if _, err := l.Launch(context.Background(), Spec{RoomID: "r1"}); !errors.Is(err, ErrInvalidSpec) {
+		t.Errorf("expected ErrInvalidSpec without a port, got %v", err)
+	}
+}
+
+func TestManagerAllocateRelease(t *testing.T) {
+	ports := testPorts()
+	m := NewManager(DefaultManagerConfig(), &InProcessLauncher{}, ports)
+	defer m.Close()
+
+	info, err := m.Allocate(context.Background(), Spec{RoomID: "r1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Port == 0 || info.Addr == "" {
+		t.Fatalf("unexpected server %+v", info)
+	}
+	if _, err := m.Allocate(context.Background(), Spec{RoomID: "r1"}); !errors.Is(err, ErrServerExists) {
+		t.Errorf("expected ErrServerExists, got %v", err)
+	}
+	if got, ok := m.Get("r1"); !ok || got.Port != info.Port {
+		t.Errorf("expected to get the server back, got %+v", got)
+	}
+
+	if err := m.Release("r1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m.Get("r1"); ok {
+		t.Error("expected server gone after release")
+	}
+	if err := m.Release("r1"); !errors.Is(err, ErrNoServer) {
+		t.Errorf("expected ErrNoServer, got %v", err)
+	}
+
+	// Reaping hands the port back
+	deadline := time.Now().Add(time.Second)
+	for ports.Leases() != 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if ports.Leases() != 0 {
+		t.Errorf("expected port released, %d still leased", ports.Leases())
+	}
+}
+
+// specLauncher runs servers in-process and remembers the last spec
+type specLauncher struct {
+	InProcessLauncher
+	spec Spec
+}
+
+func (l *specLauncher) Launch(ctx context.Context, spec Spec) (Instance, error) {
+	l.spec = spec
+	return l.InProcessLauncher.Launch(ctx, spec)
+}
+
+func TestManagerEnv(t *testing.T) {
+	config := DefaultManagerConfig()
+	config.Env = []string{"SESSION_SECRET=s3"}
+	launcher := &specLauncher{}
+	m := NewManager(config, launcher, testPorts())
+	defer m.Close()
+
+	if _, err := m.Allocate(context.Background(), Spec{RoomID: "r1"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := launcher.spec.getenv("SESSION_SECRET"); got != "s3" {
+		t.Errorf("expected the manager's SESSION_SECRET passed to the server, got %q", got)
+	}
+}
+
+func TestRemoteLauncher(t *testing.T) {
+	m := NewManager(DefaultManagerConfig(), &InProcessLauncher{}, testPorts())
+	defer m.Close()
+	api := httptest.NewServer(m.Handler("secret"))
+	defer api.Close()
+
+	l := &RemoteLauncher{URL: api.URL, Secret: "secret", PollInterval: 20 * time.Millisecond}
+	inst, err := l.Launch(context.Background(), Spec{RoomID: "r1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, _ := m.Get("r1")
+	if inst.Addr() != info.Addr {
+		t.Errorf("expected addr %s, got %s", info.Addr, inst.Addr())
+	}
+	if _, err := l.Launch(context.Background(), Spec{RoomID: "r1"}); !errors.Is(err, ErrServerExists) {
+		t.Errorf("expected ErrServerExists, got %v", err)
+	}
+
+	// Released behind the launcher's back: the poll notices
+	m.Release("r1")
+	select {
+	case <-inst.Done():
+	case <-time.After(time.Second):
+		t.Fatal("expected Done once the manager dropped the server")
+	}
+
+	inst, err = l.Launch(context.Background(), Spec{RoomID: "r2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := inst.Stop(time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m.Get("r2"); ok {
+		t.Error("expected Stop to release the server on the manager")
+	}
+
+	stranger := &RemoteLauncher{URL: api.URL, Secret: "wrong"}
+	if _, err := stranger.Launch(context.Background(), Spec{RoomID: "r3"}); !errors.Is(err, ErrRemoteRequest) {
+		t.Errorf("expected an unsigned allocation refused, got %v", err)
+	}
+	if _, ok := m.Get("r3"); ok {
+		t.Error("expected no server for an unsigned allocation")
+	}
+}
@@ -0,0 +1,78 @@
+package orchestrator
+
+import (
+	"fmt"
+	"net"
+	"sync"
+)
+
+// Default port range for game servers
+const (
+	DefaultBasePort  = 9100
+	DefaultPortCount = 1000
+)
+
+// PortPool leases UDP ports to game servers so no two rooms share one
+type PortPool struct {
+	base   int
+	count  int
+	next   int            // Offset to try first, so freed ports aren't reused right away
+	leases map[int]string // port -> roomID
+	mu     sync.Mutex
+}
+
+// NewPortPool creates a pool of count ports starting at base
+func NewPortPool(base, count int) *PortPool {
+	return &PortPool{
+		base:   base,
+		count:  count,
+		leases: make(map[int]string),
+	}
+}
+
+// Acquire leases a port whose UDP and HTTP ports are both free
+func (p *PortPool) Acquire(roomID string) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for i := 0; i < p.count; i++ {
+		port := p.base + (p.next+i)%p.count
+		if _, leased := p.leases[port]; leased || !portFree(port) {
+			continue
+		}
+		p.leases[port] = roomID
+		p.next = (p.next + i + 1) % p.count
+		return port, nil
+	}
+	return 0, ErrNoFreePort
+}
+
+// Release returns a port to the pool
+func (p *PortPool) Release(port int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.leases, port)
+}
+
+// Leases returns the number of ports in use
+func (p *PortPool) Leases() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.leases)
+}
+
+// portFree checks nothing else on the host holds the UDP port or its
+// HTTP port
+func portFree(port int) bool {
+	udp, err := net.ListenPacket("udp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return false
+	}
+	udp.Close()
+	tcp, err := net.Listen("tcp", fmt.Sprintf(":%d", port+HTTPPortOffset))
+	if err != nil {
+		return false
+	}
+	tcp.Close()
+	return true
+}
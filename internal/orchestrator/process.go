@@ -0,0 +1,108 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// DefaultServerBinary is the game server the process launcher runs
+const DefaultServerBinary = "./bin/server"
+
+// ProcessLauncher runs each game server as a child process on this host
+type ProcessLauncher struct {
+	Binary string    // Game server executable (DefaultServerBinary if empty)
+	Env    []string  // Added to this process's environment
+	Stdout io.Writer // Child output (os.Stdout/os.Stderr if nil)
+	Stderr io.Writer
+}
+
+// Launch starts the server and waits for /ready
+func (l *ProcessLauncher) Launch(ctx context.Context, spec Spec) (Instance, error) {
+	if spec.RoomID == "" || spec.Port == 0 {
+		return nil, ErrInvalidSpec
+	}
+	binary := l.Binary
+	if binary == "" {
+		binary = DefaultServerBinary
+	}
+
+	cmd := exec.Command(binary, spec.Args()...)
+	cmd.Stdout = l.Stdout
+	if cmd.Stdout == nil {
+		cmd.Stdout = os.Stdout
+	}
+	cmd.Stderr = l.Stderr
+	if cmd.Stderr == nil {
+		cmd.Stderr = os.Stderr
+	}
+	cmd.Env = append(append(os.Environ(), l.Env...), spec.Env...)
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to spawn server: %w", err)
+	}
+
+	// Reap the child whatever happens to it
+	p := &process{
+		cmd:  cmd,
+		addr: fmt.Sprintf("127.0.0.1:%d", spec.Port),
+		done: make(chan struct{}),
+	}
+	go func() {
+		cmd.Wait()
+		close(p.done)
+	}()
+
+	httpAddr := fmt.Sprintf("127.0.0.1:%d", spec.Port+HTTPPortOffset)
+	if err := WaitReady(ctx, httpAddr, p.done); err != nil {
+		cmd.Process.Kill()
+		<-p.done
+		return nil, err
+	}
+	return p, nil
+}
+
+// process is a game server child process
+type process struct {
+	cmd  *exec.Cmd
+	addr string
+	done chan struct{}
+}
+
+func (p *process) Addr() string          { return p.addr }
+func (p *process) PID() int              { return p.cmd.Process.Pid }
+func (p *process) Done() <-chan struct{} { return p.done }
+
+func (p *process) ExitStatus() string {
+	select {
+	case <-p.done:
+		return p.cmd.ProcessState.String()
+	default:
+		return ""
+	}
+}
+
+// Stop sends SIGTERM, then SIGKILL if the server hasn't exited within grace
+func (p *process) Stop(grace time.Duration) error {
+	select {
+	case <-p.done:
+		return nil
+	default:
+	}
+
+	p.cmd.Process.Signal(syscall.SIGTERM)
+	select {
+	case <-p.done:
+		return nil
+	case <-time.After(grace):
+	}
+	log.Printf("⚠️  Game server pid %d ignored SIGTERM, killing", p.PID())
+	p.cmd.Process.Kill()
+	<-p.done
+	return nil
+}
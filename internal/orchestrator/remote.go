@@ -0,0 +1,168 @@
+package orchestrator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/LemmyAI/gameserver/internal/auth"
+)
+
+// How often a remote instance checks its server still exists
+const defaultRemotePollInterval = time.Second
+
+// RemoteLauncher asks a server manager (cmd/servermanager) to run game
+// servers. The manager picks the port, so Spec.Port is ignored, and
+// Spec.Env stays on this side: the manager sets its own.
+type RemoteLauncher struct {
+	URL          string        // Server manager base URL, e.g. http://10.0.0.5:8090
+	Secret       string        // Signs requests (MANAGER_SECRET on both sides)
+	Client       *http.Client  // http.DefaultClient if nil
+	PollInterval time.Duration // How often to check the server is alive
+}
+
+// Launch allocates a server on the manager, which answers once it's ready
+func (l *RemoteLauncher) Launch(ctx context.Context, spec Spec) (Instance, error) {
+	if spec.RoomID == "" {
+		return nil, ErrInvalidSpec
+	}
+	body, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	var info ServerInfo
+	if err := l.do(ctx, http.MethodPost, "/servers", body, http.StatusCreated, &info); err != nil {
+		return nil, err
+	}
+
+	r := &remote{
+		launcher: l,
+		info:     info,
+		done:     make(chan struct{}),
+		stop:     make(chan struct{}),
+	}
+	go r.poll()
+	return r, nil
+}
+
+// do sends a request to the manager and decodes the reply into out
+func (l *RemoteLauncher) do(ctx context.Context, method, path string, body []byte, want int, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(l.URL, "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	auth.SignRequest(req, l.Secret, path, body, time.Now())
+	req.Header.Set("Content-Type", "application/json")
+
+	client := l.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrRemoteRequest, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != want {
+		var e struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&e)
+		switch resp.StatusCode {
+		case http.StatusNotFound:
+			return ErrNoServer
+		case http.StatusConflict:
+			return ErrServerExists
+		}
+		return fmt.Errorf("%w: %s %s: %d %s", ErrRemoteRequest, method, path, resp.StatusCode, e.Error)
+	}
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	return nil
+}
+
+// remote is a game server held by a server manager
+type remote struct {
+	launcher *RemoteLauncher
+	info     ServerInfo
+	done     chan struct{}
+	stop     chan struct{}
+	stopOnce sync.Once
+	status   string
+	mu       sync.Mutex
+}
+
+func (r *remote) Addr() string          { return r.info.Addr }
+func (r *remote) PID() int              { return 0 }
+func (r *remote) Done() <-chan struct{} { return r.done }
+
+func (r *remote) ExitStatus() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.status
+}
+
+// Stop releases the server on the manager, which handles the grace period
+func (r *remote) Stop(grace time.Duration) error {
+	var err error
+	r.stopOnce.Do(func() {
+		close(r.stop)
+		ctx, cancel := context.WithTimeout(context.Background(), grace+5*time.Second)
+		defer cancel()
+		err = r.launcher.do(ctx, http.MethodDelete, "/servers/"+url.PathEscape(r.info.RoomID), nil, http.StatusOK, nil)
+		if err == ErrNoServer {
+			err = nil // Already gone
+		}
+		r.exit("stopped")
+	})
+	return err
+}
+
+// poll watches the manager until the server disappears or is stopped. An
+// unreachable manager isn't an exit; only a 404 is.
+func (r *remote) poll() {
+	interval := r.launcher.PollInterval
+	if interval <= 0 {
+		interval = defaultRemotePollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), interval)
+		var info ServerInfo
+		err := r.launcher.do(ctx, http.MethodGet, "/servers/"+url.PathEscape(r.info.RoomID), nil, http.StatusOK, &info)
+		cancel()
+		if err == ErrNoServer || (err == nil && !info.StartedAt.Equal(r.info.StartedAt)) {
+			r.exit("exited on server manager")
+			return
+		}
+	}
+}
+
+// exit records why the server ended and closes Done once
+func (r *remote) exit(status string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	select {
+	case <-r.done:
+	default:
+		r.status = status
+		close(r.done)
+	}
+}
@@ -23,12 +23,16 @@ const (
 
 // ClientHello is sent when a client first connects
 type ClientHello struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	PlayerId      string                 `protobuf:"bytes,1,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
-	PlayerName    string                 `protobuf:"bytes,2,opt,name=player_name,json=playerName,proto3" json:"player_name,omitempty"`
-	Version       string                 `protobuf:"bytes,3,opt,name=version,proto3" json:"version,omitempty"` // Client version for compatibility
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	PlayerId       string                 `protobuf:"bytes,1,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	PlayerName     string                 `protobuf:"bytes,2,opt,name=player_name,json=playerName,proto3" json:"player_name,omitempty"`
+	Version        string                 `protobuf:"bytes,3,opt,name=version,proto3" json:"version,omitempty"`                                       // Client version for compatibility
+	ResumeToken    string                 `protobuf:"bytes,4,opt,name=resume_token,json=resumeToken,proto3" json:"resume_token,omitempty"`            // Token from a previous ServerWelcome to resume a session
+	SessionToken   string                 `protobuf:"bytes,5,opt,name=session_token,json=sessionToken,proto3" json:"session_token,omitempty"`         // Server-issued signed token binding player_id to a room
+	Spectator      bool                   `protobuf:"varint,6,opt,name=spectator,proto3" json:"spectator,omitempty"`                                  // Join as a spectator (no inputs, no player slot)
+	FollowPlayerId string                 `protobuf:"bytes,7,opt,name=follow_player_id,json=followPlayerId,proto3" json:"follow_player_id,omitempty"` // Spectators only: player to follow
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *ClientHello) Reset() {
@@ -82,12 +86,43 @@ func (x *ClientHello) GetVersion() string {
 	return ""
 }
 
+func (x *ClientHello) GetResumeToken() string {
+	if x != nil {
+		return x.ResumeToken
+	}
+	return ""
+}
+
+func (x *ClientHello) GetSessionToken() string {
+	if x != nil {
+		return x.SessionToken
+	}
+	return ""
+}
+
+func (x *ClientHello) GetSpectator() bool {
+	if x != nil {
+		return x.Spectator
+	}
+	return false
+}
+
+func (x *ClientHello) GetFollowPlayerId() string {
+	if x != nil {
+		return x.FollowPlayerId
+	}
+	return ""
+}
+
 // ServerWelcome is the server's response to ClientHello
 type ServerWelcome struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	PlayerId      string                 `protobuf:"bytes,1,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
-	TickRate      uint32                 `protobuf:"varint,2,opt,name=tick_rate,json=tickRate,proto3" json:"tick_rate,omitempty"`       // Server tick rate (e.g., 60)
-	ServerTime    uint64                 `protobuf:"varint,3,opt,name=server_time,json=serverTime,proto3" json:"server_time,omitempty"` // Server timestamp in ms
+	TickRate      uint32                 `protobuf:"varint,2,opt,name=tick_rate,json=tickRate,proto3" json:"tick_rate,omitempty"`         // Server tick rate (e.g., 60)
+	ServerTime    uint64                 `protobuf:"varint,3,opt,name=server_time,json=serverTime,proto3" json:"server_time,omitempty"`   // Server timestamp in ms
+	ResumeToken   string                 `protobuf:"bytes,4,opt,name=resume_token,json=resumeToken,proto3" json:"resume_token,omitempty"` // Present in ClientHello to reconnect within the grace period
+	Resumed       bool                   `protobuf:"varint,5,opt,name=resumed,proto3" json:"resumed,omitempty"`                           // True if this welcome resumed an existing session
+	Spectator     bool                   `protobuf:"varint,6,opt,name=spectator,proto3" json:"spectator,omitempty"`                       // True if the client joined as a spectator
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -143,6 +178,74 @@ func (x *ServerWelcome) GetServerTime() uint64 {
 	return 0
 }
 
+func (x *ServerWelcome) GetResumeToken() string {
+	if x != nil {
+		return x.ResumeToken
+	}
+	return ""
+}
+
+func (x *ServerWelcome) GetResumed() bool {
+	if x != nil {
+		return x.Resumed
+	}
+	return false
+}
+
+func (x *ServerWelcome) GetSpectator() bool {
+	if x != nil {
+		return x.Spectator
+	}
+	return false
+}
+
+// Heartbeat keeps a quiet client's address alive. Clients with nothing
+// else to send send one every few seconds, or the server takes them for
+// gone after its idle timeout.
+type Heartbeat struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Timestamp     uint64                 `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"` // Client timestamp in ms
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Heartbeat) Reset() {
+	*x = Heartbeat{}
+	mi := &file_proto_game_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Heartbeat) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Heartbeat) ProtoMessage() {}
+
+func (x *Heartbeat) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_game_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Heartbeat.ProtoReflect.Descriptor instead.
+func (*Heartbeat) Descriptor() ([]byte, []int) {
+	return file_proto_game_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Heartbeat) GetTimestamp() uint64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
 // Vec2 is a 2D vector for positions and velocities
 type Vec2 struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -154,7 +257,7 @@ type Vec2 struct {
 
 func (x *Vec2) Reset() {
 	*x = Vec2{}
-	mi := &file_proto_game_proto_msgTypes[2]
+	mi := &file_proto_game_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -166,7 +269,7 @@ func (x *Vec2) String() string {
 func (*Vec2) ProtoMessage() {}
 
 func (x *Vec2) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_game_proto_msgTypes[2]
+	mi := &file_proto_game_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -179,7 +282,7 @@ func (x *Vec2) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Vec2.ProtoReflect.Descriptor instead.
 func (*Vec2) Descriptor() ([]byte, []int) {
-	return file_proto_game_proto_rawDescGZIP(), []int{2}
+	return file_proto_game_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *Vec2) GetX() float32 {
@@ -212,7 +315,7 @@ type PlayerInput struct {
 
 func (x *PlayerInput) Reset() {
 	*x = PlayerInput{}
-	mi := &file_proto_game_proto_msgTypes[3]
+	mi := &file_proto_game_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -224,7 +327,7 @@ func (x *PlayerInput) String() string {
 func (*PlayerInput) ProtoMessage() {}
 
 func (x *PlayerInput) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_game_proto_msgTypes[3]
+	mi := &file_proto_game_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -237,7 +340,7 @@ func (x *PlayerInput) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PlayerInput.ProtoReflect.Descriptor instead.
 func (*PlayerInput) Descriptor() ([]byte, []int) {
-	return file_proto_game_proto_rawDescGZIP(), []int{3}
+	return file_proto_game_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *PlayerInput) GetPlayerId() string {
@@ -297,13 +400,15 @@ type PlayerState struct {
 	Velocity      *Vec2                  `protobuf:"bytes,3,opt,name=velocity,proto3" json:"velocity,omitempty"`
 	Rotation      float32                `protobuf:"fixed32,4,opt,name=rotation,proto3" json:"rotation,omitempty"`
 	Timestamp     uint64                 `protobuf:"varint,5,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Team          uint32                 `protobuf:"varint,6,opt,name=team,proto3" json:"team,omitempty"`                            // 0 = no team
+	LastInput     uint64                 `protobuf:"varint,7,opt,name=last_input,json=lastInput,proto3" json:"last_input,omitempty"` // Sequence of the player's last processed input
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *PlayerState) Reset() {
 	*x = PlayerState{}
-	mi := &file_proto_game_proto_msgTypes[4]
+	mi := &file_proto_game_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -315,7 +420,7 @@ func (x *PlayerState) String() string {
 func (*PlayerState) ProtoMessage() {}
 
 func (x *PlayerState) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_game_proto_msgTypes[4]
+	mi := &file_proto_game_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -328,7 +433,7 @@ func (x *PlayerState) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PlayerState.ProtoReflect.Descriptor instead.
 func (*PlayerState) Descriptor() ([]byte, []int) {
-	return file_proto_game_proto_rawDescGZIP(), []int{4}
+	return file_proto_game_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *PlayerState) GetPlayerId() string {
@@ -366,6 +471,20 @@ func (x *PlayerState) GetTimestamp() uint64 {
 	return 0
 }
 
+func (x *PlayerState) GetTeam() uint32 {
+	if x != nil {
+		return x.Team
+	}
+	return 0
+}
+
+func (x *PlayerState) GetLastInput() uint64 {
+	if x != nil {
+		return x.LastInput
+	}
+	return 0
+}
+
 // GameStateSnapshot is the full game state (sent on join/reconnect)
 type GameStateSnapshot struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -378,7 +497,7 @@ type GameStateSnapshot struct {
 
 func (x *GameStateSnapshot) Reset() {
 	*x = GameStateSnapshot{}
-	mi := &file_proto_game_proto_msgTypes[5]
+	mi := &file_proto_game_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -390,7 +509,7 @@ func (x *GameStateSnapshot) String() string {
 func (*GameStateSnapshot) ProtoMessage() {}
 
 func (x *GameStateSnapshot) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_game_proto_msgTypes[5]
+	mi := &file_proto_game_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -403,7 +522,7 @@ func (x *GameStateSnapshot) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GameStateSnapshot.ProtoReflect.Descriptor instead.
 func (*GameStateSnapshot) Descriptor() ([]byte, []int) {
-	return file_proto_game_proto_rawDescGZIP(), []int{5}
+	return file_proto_game_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *GameStateSnapshot) GetTick() uint64 {
@@ -440,7 +559,7 @@ type GameStateDelta struct {
 
 func (x *GameStateDelta) Reset() {
 	*x = GameStateDelta{}
-	mi := &file_proto_game_proto_msgTypes[6]
+	mi := &file_proto_game_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -452,7 +571,7 @@ func (x *GameStateDelta) String() string {
 func (*GameStateDelta) ProtoMessage() {}
 
 func (x *GameStateDelta) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_game_proto_msgTypes[6]
+	mi := &file_proto_game_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -465,7 +584,7 @@ func (x *GameStateDelta) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GameStateDelta.ProtoReflect.Descriptor instead.
 func (*GameStateDelta) Descriptor() ([]byte, []int) {
-	return file_proto_game_proto_rawDescGZIP(), []int{6}
+	return file_proto_game_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *GameStateDelta) GetTick() uint64 {
@@ -506,7 +625,7 @@ type PlayerJoin struct {
 
 func (x *PlayerJoin) Reset() {
 	*x = PlayerJoin{}
-	mi := &file_proto_game_proto_msgTypes[7]
+	mi := &file_proto_game_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -518,7 +637,7 @@ func (x *PlayerJoin) String() string {
 func (*PlayerJoin) ProtoMessage() {}
 
 func (x *PlayerJoin) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_game_proto_msgTypes[7]
+	mi := &file_proto_game_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -531,7 +650,7 @@ func (x *PlayerJoin) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PlayerJoin.ProtoReflect.Descriptor instead.
 func (*PlayerJoin) Descriptor() ([]byte, []int) {
-	return file_proto_game_proto_rawDescGZIP(), []int{7}
+	return file_proto_game_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *PlayerJoin) GetPlayer() *PlayerState {
@@ -541,7 +660,8 @@ func (x *PlayerJoin) GetPlayer() *PlayerState {
 	return nil
 }
 
-// PlayerLeave broadcast when a player leaves
+// PlayerLeave broadcast when a player leaves; a client sends it to leave
+// for good instead of waiting out the reconnect grace period
 type PlayerLeave struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	PlayerId      string                 `protobuf:"bytes,1,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
@@ -552,7 +672,7 @@ type PlayerLeave struct {
 
 func (x *PlayerLeave) Reset() {
 	*x = PlayerLeave{}
-	mi := &file_proto_game_proto_msgTypes[8]
+	mi := &file_proto_game_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -564,7 +684,7 @@ func (x *PlayerLeave) String() string {
 func (*PlayerLeave) ProtoMessage() {}
 
 func (x *PlayerLeave) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_game_proto_msgTypes[8]
+	mi := &file_proto_game_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -577,7 +697,7 @@ func (x *PlayerLeave) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PlayerLeave.ProtoReflect.Descriptor instead.
 func (*PlayerLeave) Descriptor() ([]byte, []int) {
-	return file_proto_game_proto_rawDescGZIP(), []int{8}
+	return file_proto_game_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *PlayerLeave) GetPlayerId() string {
@@ -594,6 +714,223 @@ func (x *PlayerLeave) GetReason() string {
 	return ""
 }
 
+// RoomControl is sent by the room owner (webbridge) to steer the game server
+type RoomControl struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`                                     // Session token with the control role
+	Phase         string                 `protobuf:"bytes,2,opt,name=phase,proto3" json:"phase,omitempty"`                                     // Room phase: lobby, countdown, in_game, results, closed
+	KickPlayerId  string                 `protobuf:"bytes,3,opt,name=kick_player_id,json=kickPlayerId,proto3" json:"kick_player_id,omitempty"` // Remove this player or spectator from the game
+	KickReason    string                 `protobuf:"bytes,4,opt,name=kick_reason,json=kickReason,proto3" json:"kick_reason,omitempty"`
+	Teams         []*TeamAssignment      `protobuf:"bytes,5,rep,name=teams,proto3" json:"teams,omitempty"` // Team updates; unlisted players keep their team
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RoomControl) Reset() {
+	*x = RoomControl{}
+	mi := &file_proto_game_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RoomControl) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RoomControl) ProtoMessage() {}
+
+func (x *RoomControl) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_game_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RoomControl.ProtoReflect.Descriptor instead.
+func (*RoomControl) Descriptor() ([]byte, []int) {
+	return file_proto_game_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *RoomControl) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *RoomControl) GetPhase() string {
+	if x != nil {
+		return x.Phase
+	}
+	return ""
+}
+
+func (x *RoomControl) GetKickPlayerId() string {
+	if x != nil {
+		return x.KickPlayerId
+	}
+	return ""
+}
+
+func (x *RoomControl) GetKickReason() string {
+	if x != nil {
+		return x.KickReason
+	}
+	return ""
+}
+
+func (x *RoomControl) GetTeams() []*TeamAssignment {
+	if x != nil {
+		return x.Teams
+	}
+	return nil
+}
+
+// TeamAssignment puts a player on a team
+type TeamAssignment struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PlayerId      string                 `protobuf:"bytes,1,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	Team          uint32                 `protobuf:"varint,2,opt,name=team,proto3" json:"team,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TeamAssignment) Reset() {
+	*x = TeamAssignment{}
+	mi := &file_proto_game_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TeamAssignment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TeamAssignment) ProtoMessage() {}
+
+func (x *TeamAssignment) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_game_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TeamAssignment.ProtoReflect.Descriptor instead.
+func (*TeamAssignment) Descriptor() ([]byte, []int) {
+	return file_proto_game_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *TeamAssignment) GetPlayerId() string {
+	if x != nil {
+		return x.PlayerId
+	}
+	return ""
+}
+
+func (x *TeamAssignment) GetTeam() uint32 {
+	if x != nil {
+		return x.Team
+	}
+	return 0
+}
+
+// ChatMessage is a line of room chat. The webbridge moderates it, keeps
+// the history and fills in from_id; a non-empty to_id makes it a whisper.
+// Game servers don't relay chat.
+type ChatMessage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	FromId        string                 `protobuf:"bytes,2,opt,name=from_id,json=fromId,proto3" json:"from_id,omitempty"`
+	FromName      string                 `protobuf:"bytes,3,opt,name=from_name,json=fromName,proto3" json:"from_name,omitempty"`
+	ToId          string                 `protobuf:"bytes,4,opt,name=to_id,json=toId,proto3" json:"to_id,omitempty"`
+	Text          string                 `protobuf:"bytes,5,opt,name=text,proto3" json:"text,omitempty"`
+	Timestamp     uint64                 `protobuf:"varint,6,opt,name=timestamp,proto3" json:"timestamp,omitempty"` // Server time (ms since epoch)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChatMessage) Reset() {
+	*x = ChatMessage{}
+	mi := &file_proto_game_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChatMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChatMessage) ProtoMessage() {}
+
+func (x *ChatMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_game_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChatMessage.ProtoReflect.Descriptor instead.
+func (*ChatMessage) Descriptor() ([]byte, []int) {
+	return file_proto_game_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ChatMessage) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ChatMessage) GetFromId() string {
+	if x != nil {
+		return x.FromId
+	}
+	return ""
+}
+
+func (x *ChatMessage) GetFromName() string {
+	if x != nil {
+		return x.FromName
+	}
+	return ""
+}
+
+func (x *ChatMessage) GetToId() string {
+	if x != nil {
+		return x.ToId
+	}
+	return ""
+}
+
+func (x *ChatMessage) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *ChatMessage) GetTimestamp() uint64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
 // Message is the top-level envelope for all messages
 type Message struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -601,11 +938,14 @@ type Message struct {
 	//
 	//	*Message_ClientHello
 	//	*Message_ServerWelcome
+	//	*Message_Heartbeat
 	//	*Message_PlayerInput
 	//	*Message_StateSnapshot
 	//	*Message_StateDelta
 	//	*Message_PlayerJoin
 	//	*Message_PlayerLeave
+	//	*Message_RoomControl
+	//	*Message_Chat
 	Payload       isMessage_Payload `protobuf_oneof:"payload"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
@@ -613,7 +953,7 @@ type Message struct {
 
 func (x *Message) Reset() {
 	*x = Message{}
-	mi := &file_proto_game_proto_msgTypes[9]
+	mi := &file_proto_game_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -625,7 +965,7 @@ func (x *Message) String() string {
 func (*Message) ProtoMessage() {}
 
 func (x *Message) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_game_proto_msgTypes[9]
+	mi := &file_proto_game_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -638,7 +978,7 @@ func (x *Message) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Message.ProtoReflect.Descriptor instead.
 func (*Message) Descriptor() ([]byte, []int) {
-	return file_proto_game_proto_rawDescGZIP(), []int{9}
+	return file_proto_game_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *Message) GetPayload() isMessage_Payload {
@@ -666,6 +1006,15 @@ func (x *Message) GetServerWelcome() *ServerWelcome {
 	return nil
 }
 
+func (x *Message) GetHeartbeat() *Heartbeat {
+	if x != nil {
+		if x, ok := x.Payload.(*Message_Heartbeat); ok {
+			return x.Heartbeat
+		}
+	}
+	return nil
+}
+
 func (x *Message) GetPlayerInput() *PlayerInput {
 	if x != nil {
 		if x, ok := x.Payload.(*Message_PlayerInput); ok {
@@ -711,6 +1060,24 @@ func (x *Message) GetPlayerLeave() *PlayerLeave {
 	return nil
 }
 
+func (x *Message) GetRoomControl() *RoomControl {
+	if x != nil {
+		if x, ok := x.Payload.(*Message_RoomControl); ok {
+			return x.RoomControl
+		}
+	}
+	return nil
+}
+
+func (x *Message) GetChat() *ChatMessage {
+	if x != nil {
+		if x, ok := x.Payload.(*Message_Chat); ok {
+			return x.Chat
+		}
+	}
+	return nil
+}
+
 type isMessage_Payload interface {
 	isMessage_Payload()
 }
@@ -724,6 +1091,10 @@ type Message_ServerWelcome struct {
 	ServerWelcome *ServerWelcome `protobuf:"bytes,2,opt,name=server_welcome,json=serverWelcome,proto3,oneof"`
 }
 
+type Message_Heartbeat struct {
+	Heartbeat *Heartbeat `protobuf:"bytes,3,opt,name=heartbeat,proto3,oneof"`
+}
+
 type Message_PlayerInput struct {
 	// Input
 	PlayerInput *PlayerInput `protobuf:"bytes,10,opt,name=player_input,json=playerInput,proto3,oneof"`
@@ -747,10 +1118,22 @@ type Message_PlayerLeave struct {
 	PlayerLeave *PlayerLeave `protobuf:"bytes,31,opt,name=player_leave,json=playerLeave,proto3,oneof"`
 }
 
+type Message_RoomControl struct {
+	// Control
+	RoomControl *RoomControl `protobuf:"bytes,40,opt,name=room_control,json=roomControl,proto3,oneof"`
+}
+
+type Message_Chat struct {
+	// Chat
+	Chat *ChatMessage `protobuf:"bytes,50,opt,name=chat,proto3,oneof"`
+}
+
 func (*Message_ClientHello) isMessage_Payload() {}
 
 func (*Message_ServerWelcome) isMessage_Payload() {}
 
+func (*Message_Heartbeat) isMessage_Payload() {}
+
 func (*Message_PlayerInput) isMessage_Payload() {}
 
 func (*Message_StateSnapshot) isMessage_Payload() {}
@@ -761,21 +1144,34 @@ func (*Message_PlayerJoin) isMessage_Payload() {}
 
 func (*Message_PlayerLeave) isMessage_Payload() {}
 
+func (*Message_RoomControl) isMessage_Payload() {}
+
+func (*Message_Chat) isMessage_Payload() {}
+
 var File_proto_game_proto protoreflect.FileDescriptor
 
 const file_proto_game_proto_rawDesc = "" +
 	"\n" +
-	"\x10proto/game.proto\x12\x04game\"e\n" +
+	"\x10proto/game.proto\x12\x04game\"\xf5\x01\n" +
 	"\vClientHello\x12\x1b\n" +
 	"\tplayer_id\x18\x01 \x01(\tR\bplayerId\x12\x1f\n" +
 	"\vplayer_name\x18\x02 \x01(\tR\n" +
 	"playerName\x12\x18\n" +
-	"\aversion\x18\x03 \x01(\tR\aversion\"j\n" +
+	"\aversion\x18\x03 \x01(\tR\aversion\x12!\n" +
+	"\fresume_token\x18\x04 \x01(\tR\vresumeToken\x12#\n" +
+	"\rsession_token\x18\x05 \x01(\tR\fsessionToken\x12\x1c\n" +
+	"\tspectator\x18\x06 \x01(\bR\tspectator\x12(\n" +
+	"\x10follow_player_id\x18\a \x01(\tR\x0efollowPlayerId\"\xc5\x01\n" +
 	"\rServerWelcome\x12\x1b\n" +
 	"\tplayer_id\x18\x01 \x01(\tR\bplayerId\x12\x1b\n" +
 	"\ttick_rate\x18\x02 \x01(\rR\btickRate\x12\x1f\n" +
 	"\vserver_time\x18\x03 \x01(\x04R\n" +
-	"serverTime\"\"\n" +
+	"serverTime\x12!\n" +
+	"\fresume_token\x18\x04 \x01(\tR\vresumeToken\x12\x18\n" +
+	"\aresumed\x18\x05 \x01(\bR\aresumed\x12\x1c\n" +
+	"\tspectator\x18\x06 \x01(\bR\tspectator\")\n" +
+	"\tHeartbeat\x12\x1c\n" +
+	"\ttimestamp\x18\x01 \x01(\x04R\ttimestamp\"\"\n" +
 	"\x04Vec2\x12\f\n" +
 	"\x01x\x18\x01 \x01(\x02R\x01x\x12\f\n" +
 	"\x01y\x18\x02 \x01(\x02R\x01y\"\xd6\x01\n" +
@@ -787,7 +1183,7 @@ const file_proto_game_proto_rawDesc = "" +
 	".game.Vec2R\bmovement\x12\x12\n" +
 	"\x04jump\x18\x04 \x01(\bR\x04jump\x12\x19\n" +
 	"\baction_1\x18\x05 \x01(\bR\aaction1\x12\x19\n" +
-	"\baction_2\x18\x06 \x01(\bR\aaction2\"\xb4\x01\n" +
+	"\baction_2\x18\x06 \x01(\bR\aaction2\"\xe7\x01\n" +
 	"\vPlayerState\x12\x1b\n" +
 	"\tplayer_id\x18\x01 \x01(\tR\bplayerId\x12&\n" +
 	"\bposition\x18\x02 \x01(\v2\n" +
@@ -795,7 +1191,10 @@ const file_proto_game_proto_rawDesc = "" +
 	"\bvelocity\x18\x03 \x01(\v2\n" +
 	".game.Vec2R\bvelocity\x12\x1a\n" +
 	"\brotation\x18\x04 \x01(\x02R\brotation\x12\x1c\n" +
-	"\ttimestamp\x18\x05 \x01(\x04R\ttimestamp\"r\n" +
+	"\ttimestamp\x18\x05 \x01(\x04R\ttimestamp\x12\x12\n" +
+	"\x04team\x18\x06 \x01(\rR\x04team\x12\x1d\n" +
+	"\n" +
+	"last_input\x18\a \x01(\x04R\tlastInput\"r\n" +
 	"\x11GameStateSnapshot\x12\x12\n" +
 	"\x04tick\x18\x01 \x01(\x04R\x04tick\x12\x1c\n" +
 	"\ttimestamp\x18\x02 \x01(\x04R\ttimestamp\x12+\n" +
@@ -810,10 +1209,28 @@ const file_proto_game_proto_rawDesc = "" +
 	"\x06player\x18\x01 \x01(\v2\x11.game.PlayerStateR\x06player\"B\n" +
 	"\vPlayerLeave\x12\x1b\n" +
 	"\tplayer_id\x18\x01 \x01(\tR\bplayerId\x12\x16\n" +
-	"\x06reason\x18\x02 \x01(\tR\x06reason\"\xaa\x03\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\"\xac\x01\n" +
+	"\vRoomControl\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x14\n" +
+	"\x05phase\x18\x02 \x01(\tR\x05phase\x12$\n" +
+	"\x0ekick_player_id\x18\x03 \x01(\tR\fkickPlayerId\x12\x1f\n" +
+	"\vkick_reason\x18\x04 \x01(\tR\n" +
+	"kickReason\x12*\n" +
+	"\x05teams\x18\x05 \x03(\v2\x14.game.TeamAssignmentR\x05teams\"A\n" +
+	"\x0eTeamAssignment\x12\x1b\n" +
+	"\tplayer_id\x18\x01 \x01(\tR\bplayerId\x12\x12\n" +
+	"\x04team\x18\x02 \x01(\rR\x04team\"\x9a\x01\n" +
+	"\vChatMessage\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
+	"\afrom_id\x18\x02 \x01(\tR\x06fromId\x12\x1b\n" +
+	"\tfrom_name\x18\x03 \x01(\tR\bfromName\x12\x13\n" +
+	"\x05to_id\x18\x04 \x01(\tR\x04toId\x12\x12\n" +
+	"\x04text\x18\x05 \x01(\tR\x04text\x12\x1c\n" +
+	"\ttimestamp\x18\x06 \x01(\x04R\ttimestamp\"\xbc\x04\n" +
 	"\aMessage\x126\n" +
 	"\fclient_hello\x18\x01 \x01(\v2\x11.game.ClientHelloH\x00R\vclientHello\x12<\n" +
-	"\x0eserver_welcome\x18\x02 \x01(\v2\x13.game.ServerWelcomeH\x00R\rserverWelcome\x126\n" +
+	"\x0eserver_welcome\x18\x02 \x01(\v2\x13.game.ServerWelcomeH\x00R\rserverWelcome\x12/\n" +
+	"\theartbeat\x18\x03 \x01(\v2\x0f.game.HeartbeatH\x00R\theartbeat\x126\n" +
 	"\fplayer_input\x18\n" +
 	" \x01(\v2\x11.game.PlayerInputH\x00R\vplayerInput\x12@\n" +
 	"\x0estate_snapshot\x18\x14 \x01(\v2\x17.game.GameStateSnapshotH\x00R\rstateSnapshot\x127\n" +
@@ -821,7 +1238,9 @@ const file_proto_game_proto_rawDesc = "" +
 	"stateDelta\x123\n" +
 	"\vplayer_join\x18\x1e \x01(\v2\x10.game.PlayerJoinH\x00R\n" +
 	"playerJoin\x126\n" +
-	"\fplayer_leave\x18\x1f \x01(\v2\x11.game.PlayerLeaveH\x00R\vplayerLeaveB\t\n" +
+	"\fplayer_leave\x18\x1f \x01(\v2\x11.game.PlayerLeaveH\x00R\vplayerLeave\x126\n" +
+	"\froom_control\x18( \x01(\v2\x11.game.RoomControlH\x00R\vroomControl\x12'\n" +
+	"\x04chat\x182 \x01(\v2\x11.game.ChatMessageH\x00R\x04chatB\t\n" +
 	"\apayloadB8Z6github.com/LemmyAI/gameserver/internal/protocol/gamepbb\x06proto3"
 
 var (
@@ -836,38 +1255,46 @@ func file_proto_game_proto_rawDescGZIP() []byte {
 	return file_proto_game_proto_rawDescData
 }
 
-var file_proto_game_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_proto_game_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
 var file_proto_game_proto_goTypes = []any{
 	(*ClientHello)(nil),       // 0: game.ClientHello
 	(*ServerWelcome)(nil),     // 1: game.ServerWelcome
-	(*Vec2)(nil),              // 2: game.Vec2
-	(*PlayerInput)(nil),       // 3: game.PlayerInput
-	(*PlayerState)(nil),       // 4: game.PlayerState
-	(*GameStateSnapshot)(nil), // 5: game.GameStateSnapshot
-	(*GameStateDelta)(nil),    // 6: game.GameStateDelta
-	(*PlayerJoin)(nil),        // 7: game.PlayerJoin
-	(*PlayerLeave)(nil),       // 8: game.PlayerLeave
-	(*Message)(nil),           // 9: game.Message
+	(*Heartbeat)(nil),         // 2: game.Heartbeat
+	(*Vec2)(nil),              // 3: game.Vec2
+	(*PlayerInput)(nil),       // 4: game.PlayerInput
+	(*PlayerState)(nil),       // 5: game.PlayerState
+	(*GameStateSnapshot)(nil), // 6: game.GameStateSnapshot
+	(*GameStateDelta)(nil),    // 7: game.GameStateDelta
+	(*PlayerJoin)(nil),        // 8: game.PlayerJoin
+	(*PlayerLeave)(nil),       // 9: game.PlayerLeave
+	(*RoomControl)(nil),       // 10: game.RoomControl
+	(*TeamAssignment)(nil),    // 11: game.TeamAssignment
+	(*ChatMessage)(nil),       // 12: game.ChatMessage
+	(*Message)(nil),           // 13: game.Message
 }
 var file_proto_game_proto_depIdxs = []int32{
-	2,  // 0: game.PlayerInput.movement:type_name -> game.Vec2
-	2,  // 1: game.PlayerState.position:type_name -> game.Vec2
-	2,  // 2: game.PlayerState.velocity:type_name -> game.Vec2
-	4,  // 3: game.GameStateSnapshot.players:type_name -> game.PlayerState
-	4,  // 4: game.GameStateDelta.changed_players:type_name -> game.PlayerState
-	4,  // 5: game.PlayerJoin.player:type_name -> game.PlayerState
-	0,  // 6: game.Message.client_hello:type_name -> game.ClientHello
-	1,  // 7: game.Message.server_welcome:type_name -> game.ServerWelcome
-	3,  // 8: game.Message.player_input:type_name -> game.PlayerInput
-	5,  // 9: game.Message.state_snapshot:type_name -> game.GameStateSnapshot
-	6,  // 10: game.Message.state_delta:type_name -> game.GameStateDelta
-	7,  // 11: game.Message.player_join:type_name -> game.PlayerJoin
-	8,  // 12: game.Message.player_leave:type_name -> game.PlayerLeave
-	13, // [13:13] is the sub-list for method output_type
-	13, // [13:13] is the sub-list for method input_type
-	13, // [13:13] is the sub-list for extension type_name
-	13, // [13:13] is the sub-list for extension extendee
-	0,  // [0:13] is the sub-list for field type_name
+	3,  // 0: game.PlayerInput.movement:type_name -> game.Vec2
+	3,  // 1: game.PlayerState.position:type_name -> game.Vec2
+	3,  // 2: game.PlayerState.velocity:type_name -> game.Vec2
+	5,  // 3: game.GameStateSnapshot.players:type_name -> game.PlayerState
+	5,  // 4: game.GameStateDelta.changed_players:type_name -> game.PlayerState
+	5,  // 5: game.PlayerJoin.player:type_name -> game.PlayerState
+	11, // 6: game.RoomControl.teams:type_name -> game.TeamAssignment
+	0,  // 7: game.Message.client_hello:type_name -> game.ClientHello
+	1,  // 8: game.Message.server_welcome:type_name -> game.ServerWelcome
+	2,  // 9: game.Message.heartbeat:type_name -> game.Heartbeat
+	4,  // 10: game.Message.player_input:type_name -> game.PlayerInput
+	6,  // 11: game.Message.state_snapshot:type_name -> game.GameStateSnapshot
+	7,  // 12: game.Message.state_delta:type_name -> game.GameStateDelta
+	8,  // 13: game.Message.player_join:type_name -> game.PlayerJoin
+	9,  // 14: game.Message.player_leave:type_name -> game.PlayerLeave
+	10, // 15: game.Message.room_control:type_name -> game.RoomControl
+	12, // 16: game.Message.chat:type_name -> game.ChatMessage
+	17, // [17:17] is the sub-list for method output_type
+	17, // [17:17] is the sub-list for method input_type
+	17, // [17:17] is the sub-list for extension type_name
+	17, // [17:17] is the sub-list for extension extendee
+	0,  // [0:17] is the sub-list for field type_name
 }
 
 func init() { file_proto_game_proto_init() }
@@ -875,14 +1302,17 @@ func file_proto_game_proto_init() {
 	if File_proto_game_proto != nil {
 		return
 	}
-	file_proto_game_proto_msgTypes[9].OneofWrappers = []any{
+	file_proto_game_proto_msgTypes[13].OneofWrappers = []any{
 		(*Message_ClientHello)(nil),
 		(*Message_ServerWelcome)(nil),
+		(*Message_Heartbeat)(nil),
 		(*Message_PlayerInput)(nil),
 		(*Message_StateSnapshot)(nil),
 		(*Message_StateDelta)(nil),
 		(*Message_PlayerJoin)(nil),
 		(*Message_PlayerLeave)(nil),
+		(*Message_RoomControl)(nil),
+		(*Message_Chat)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -890,7 +1320,7 @@ func file_proto_game_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_game_proto_rawDesc), len(file_proto_game_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   10,
+			NumMessages:   14,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
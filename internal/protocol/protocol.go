@@ -3,11 +3,16 @@ package protocol
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/LemmyAI/gameserver/internal/protocol/gamepb"
 	"google.golang.org/protobuf/proto"
 )
 
+// HeartbeatInterval is how often a client with nothing else to send sends a
+// Heartbeat. It must stay well under the transport's IdleTimeout.
+const HeartbeatInterval = 2 * time.Second
+
 // Encode serializes a Message to bytes.
 func Encode(msg *gamepb.Message) ([]byte, error) {
 	return proto.Marshal(msg)
@@ -35,14 +40,39 @@ func NewClientHello(playerID, playerName, version string) *gamepb.Message {
 	}
 }
 
+// NewSessionHello creates a ClientHello carrying a signed session token.
+func NewSessionHello(playerID, playerName, version, sessionToken string) *gamepb.Message {
+	msg := NewClientHello(playerID, playerName, version)
+	msg.GetClientHello().SessionToken = sessionToken
+	return msg
+}
+
+// NewSpectatorHello creates a ClientHello that joins as a spectator,
+// optionally following a player.
+func NewSpectatorHello(playerID, playerName, version, sessionToken, followPlayerID string) *gamepb.Message {
+	msg := NewSessionHello(playerID, playerName, version, sessionToken)
+	msg.GetClientHello().Spectator = true
+	msg.GetClientHello().FollowPlayerId = followPlayerID
+	return msg
+}
+
+// NewResumeHello creates a ClientHello that resumes a previous session.
+func NewResumeHello(playerID, playerName, version, resumeToken string) *gamepb.Message {
+	msg := NewClientHello(playerID, playerName, version)
+	msg.GetClientHello().ResumeToken = resumeToken
+	return msg
+}
+
 // NewServerWelcome creates a ServerWelcome message wrapped in Message.
-func NewServerWelcome(playerID string, tickRate uint32, serverTime uint64) *gamepb.Message {
+func NewServerWelcome(playerID string, tickRate uint32, serverTime uint64, resumeToken string, resumed bool) *gamepb.Message {
 	return &gamepb.Message{
 		Payload: &gamepb.Message_ServerWelcome{
 			ServerWelcome: &gamepb.ServerWelcome{
-				PlayerId:   playerID,
-				TickRate:   tickRate,
-				ServerTime: serverTime,
+				PlayerId:    playerID,
+				TickRate:    tickRate,
+				ServerTime:  serverTime,
+				ResumeToken: resumeToken,
+				Resumed:     resumed,
 			},
 		},
 	}
@@ -68,6 +98,77 @@ func NewPlayerInput(playerID string, sequence, timestamp uint64, x, y float32, j
 	}
 }
 
+// NewHeartbeat creates a Heartbeat message wrapped in Message.
+func NewHeartbeat(timestamp uint64) *gamepb.Message {
+	return &gamepb.Message{
+		Payload: &gamepb.Message_Heartbeat{
+			Heartbeat: &gamepb.Heartbeat{Timestamp: timestamp},
+		},
+	}
+}
+
+// NewPlayerLeave creates a PlayerLeave message wrapped in Message.
+func NewPlayerLeave(playerID, reason string) *gamepb.Message {
+	return &gamepb.Message{
+		Payload: &gamepb.Message_PlayerLeave{
+			PlayerLeave: &gamepb.PlayerLeave{
+				PlayerId: playerID,
+				Reason:   reason,
+			},
+		},
+	}
+}
+
+// NewRoomControl creates a RoomControl message wrapped in Message.
+func NewRoomControl(token, phase string) *gamepb.Message {
+	return &gamepb.Message{
+		Payload: &gamepb.Message_RoomControl{
+			RoomControl: &gamepb.RoomControl{
+				Token: token,
+				Phase: phase,
+			},
+		},
+	}
+}
+
+// NewKickControl creates a RoomControl that removes a player from the game.
+func NewKickControl(token, playerID, reason string) *gamepb.Message {
+	return &gamepb.Message{
+		Payload: &gamepb.Message_RoomControl{
+			RoomControl: &gamepb.RoomControl{
+				Token:        token,
+				KickPlayerId: playerID,
+				KickReason:   reason,
+			},
+		},
+	}
+}
+
+// NewTeamControl creates a RoomControl that assigns players to teams.
+func NewTeamControl(token string, teams map[string]uint32) *gamepb.Message {
+	ctrl := &gamepb.RoomControl{Token: token}
+	for playerID, team := range teams {
+		ctrl.Teams = append(ctrl.Teams, &gamepb.TeamAssignment{PlayerId: playerID, Team: team})
+	}
+	return &gamepb.Message{
+		Payload: &gamepb.Message_RoomControl{RoomControl: ctrl},
+	}
+}
+
+// NewChatMessage creates a ChatMessage wrapped in Message.
+// An empty toID sends it to the whole room.
+func NewChatMessage(fromID, toID, text string) *gamepb.Message {
+	return &gamepb.Message{
+		Payload: &gamepb.Message_Chat{
+			Chat: &gamepb.ChatMessage{
+				FromId: fromID,
+				ToId:   toID,
+				Text:   text,
+			},
+		},
+	}
+}
+
 // NewPlayerState creates a PlayerState.
 func NewPlayerState(playerID string, x, y, vx, vy, rotation float32, timestamp uint64) *gamepb.PlayerState {
 	return &gamepb.PlayerState{
@@ -86,6 +187,8 @@ func MessageTypeName(msg *gamepb.Message) string {
 		return "ClientHello"
 	case *gamepb.Message_ServerWelcome:
 		return "ServerWelcome"
+	case *gamepb.Message_Heartbeat:
+		return "Heartbeat"
 	case *gamepb.Message_PlayerInput:
 		return "PlayerInput"
 	case *gamepb.Message_StateSnapshot:
@@ -96,6 +199,10 @@ func MessageTypeName(msg *gamepb.Message) string {
 		return "PlayerJoin"
 	case *gamepb.Message_PlayerLeave:
 		return "PlayerLeave"
+	case *gamepb.Message_RoomControl:
+		return "RoomControl"
+	case *gamepb.Message_Chat:
+		return "Chat"
 	default:
 		return "Unknown"
 	}
@@ -35,7 +35,7 @@ func TestEncodeDecodeClientHello(t *testing.T) {
 }
 
 func TestEncodeDecodePlayerInput(t *testing.T) {
-	original := NewPlayerInput(1, 1708444800000, 0.5, -1.0, true, false, true)
+	original := NewPlayerInput("player-123", 1, 1708444800000, 0.5, -1.0, true, false, true)
 
 	data, err := Encode(original)
 	if err != nil {
@@ -62,14 +62,55 @@ func TestEncodeDecodePlayerInput(t *testing.T) {
 	}
 }
 
+func TestEncodeDecodePlayerLeave(t *testing.T) {
+	data, err := Encode(NewPlayerLeave("player-123", "left"))
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	decoded, err := Decode(data)
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+
+	leave := decoded.GetPlayerLeave()
+	if leave == nil {
+		t.Fatal("Expected PlayerLeave payload")
+	}
+	if leave.PlayerId != "player-123" || leave.Reason != "left" {
+		t.Errorf("expected player-123 left, got %s %s", leave.PlayerId, leave.Reason)
+	}
+}
+
+func TestEncodeDecodeResume(t *testing.T) {
+	original := NewResumeHello("player-123", "TestPlayer", "1.0.0", "tok")
+
+	data, err := Encode(original)
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+
+	decoded, err := Decode(data)
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+
+	if token := decoded.GetClientHello().GetResumeToken(); token != "tok" {
+		t.Errorf("expected resume token tok, got %q", token)
+	}
+}
+
 func TestMessageTypeName(t *testing.T) {
 	tests := []struct {
 		msg      *gamepb.Message
 		expected string
 	}{
 		{NewClientHello("x", "y", "z"), "ClientHello"},
-		{NewServerWelcome("x", 60, 0), "ServerWelcome"},
-		{NewPlayerInput(0, 0, 0, 0, false, false, false), "PlayerInput"},
+		{NewServerWelcome("x", 60, 0, "", false), "ServerWelcome"},
+		{NewHeartbeat(1), "Heartbeat"},
+		{NewPlayerInput("x", 0, 0, 0, 0, false, false, false), "PlayerInput"},
+		{NewRoomControl("t", "lobby"), "RoomControl"},
+		{NewTeamControl("t", map[string]uint32{"p1": 1}), "RoomControl"},
+		{NewChatMessage("p1", "", "hi"), "Chat"},
 	}
 
 	for _, tt := range tests {
@@ -81,7 +122,7 @@ func TestMessageTypeName(t *testing.T) {
 }
 
 func BenchmarkEncodePlayerInput(b *testing.B) {
-	msg := NewPlayerInput(1, 1708444800000, 0.5, -1.0, true, false, false)
+	msg := NewPlayerInput("player-123", 1, 1708444800000, 0.5, -1.0, true, false, false)
 	
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
@@ -90,7 +131,7 @@ func BenchmarkEncodePlayerInput(b *testing.B) {
 }
 
 func BenchmarkDecodePlayerInput(b *testing.B) {
-	msg := NewPlayerInput(1, 1708444800000, 0.5, -1.0, true, false, false)
+	msg := NewPlayerInput("player-123", 1, 1708444800000, 0.5, -1.0, true, false, false)
 	data, _ := Encode(msg)
 	
 	b.ResetTimer()
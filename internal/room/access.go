@@ -0,0 +1,123 @@
+package room
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"time"
+
+	"github.com/LemmyAI/gameserver/internal/auth"
+)
+
+// DefaultInviteTTL is how long an invite token stays valid
+const DefaultInviteTTL = 24 * time.Hour
+
+// Options configure a room at creation
+type Options struct {
+	Password   string     // Required to join unless the player has an invite
+	InviteOnly bool       // Only players with an invite token may join (never listed)
+	Name       string     // Shown in the room browser
+	Mode       string     // Game mode, for browser filtering
+	Tags       []string   // Free-form labels, for browser filtering
+	Visibility Visibility // VisibilityPublic if empty
+	Teams      int        // Number of teams players are split into (0 = none)
+	Settings   Settings   // Game server settings (validate first)
+	Seats      []string   // Only these players may join (matchmade rooms); nil admits anyone
+}
+
+// Credentials prove a player may enter a private room
+type Credentials struct {
+	Password string
+	Invite   string
+}
+
+// IsPrivate returns true if joining needs a password or invite
+func (room *Room) IsPrivate() bool {
+	room.mu.RLock()
+	defer room.mu.RUnlock()
+	return room.InviteOnly || room.HasPassword
+}
+
+// CreateInvite mints an expiring invite token for the room (host only)
+func (room *Room) CreateInvite(hostID string, ttl time.Duration) (string, error) {
+	room.mu.RLock()
+	defer room.mu.RUnlock()
+
+	if !room.isHostLocked(hostID) {
+		return "", ErrNotHost
+	}
+	return room.mintInviteLocked(ttl), nil
+}
+
+// mintInviteLocked signs an invite for this room
+func (room *Room) mintInviteLocked(ttl time.Duration) string {
+	if ttl <= 0 {
+		ttl = DefaultInviteTTL
+	}
+	return room.registry.invites.MintRole("", room.ID, auth.RoleInvite, ttl)
+}
+
+// setPasswordLocked stores a salted hash of the password ("" clears it)
+func (room *Room) setPasswordLocked(password string) {
+	room.HasPassword = password != ""
+	room.passwordSalt = nil
+	room.passwordHash = nil
+	if password == "" {
+		return
+	}
+	room.passwordSalt = make([]byte, 16)
+	rand.Read(room.passwordSalt)
+	room.passwordHash = hashPassword(room.passwordSalt, password)
+}
+
+// checkSeatLocked keeps a matchmade room's player slots for the players
+// they were reserved for
+func (room *Room) checkSeatLocked(id string) error {
+	if room.seats == nil {
+		return nil
+	}
+	if _, seated := room.seats[id]; !seated {
+		return ErrSeatReserved
+	}
+	return nil
+}
+
+// checkCredentialsLocked admits members, invite holders and (for
+// password rooms) players with the right password
+func (room *Room) checkCredentialsLocked(id string, creds Credentials) error {
+	if room.hasMemberLocked(id) {
+		return nil
+	}
+
+	if creds.Invite != "" {
+		claims, err := room.registry.invites.Verify(creds.Invite)
+		if err == auth.ErrTokenExpired {
+			return ErrInviteExpired
+		}
+		if err != nil || claims.Role != auth.RoleInvite || claims.RoomID != room.ID {
+			return ErrInvalidInvite
+		}
+		return nil
+	}
+
+	if room.InviteOnly {
+		return ErrInviteRequired
+	}
+	if room.HasPassword {
+		if creds.Password == "" {
+			return ErrPasswordRequired
+		}
+		got := hashPassword(room.passwordSalt, creds.Password)
+		if subtle.ConstantTimeCompare(got, room.passwordHash) != 1 {
+			return ErrWrongPassword
+		}
+	}
+	return nil
+}
+
+func hashPassword(salt []byte, password string) []byte {
+	h := sha256.New()
+	h.Write(salt)
+	h.Write([]byte(password))
+	return h.Sum(nil)
+}
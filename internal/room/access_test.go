@@ -0,0 +1,84 @@
+package room
+
+import (
+	"testing"
+	"time"
+
+	"github.com/LemmyAI/gameserver/internal/auth"
+)
+
+func TestRoomPassword(t *testing.T) {
+	r := NewRegistry(DefaultConfig())
+	rm, _ := r.CreateWithOptions(Options{Password: "hunter2"})
+
+	if !rm.IsPrivate() {
+		t.Fatal("expected password room to be private")
+	}
+	if _, _, err := r.Join(rm.ID, "p1", "One", Credentials{}); err != ErrPasswordRequired {
+		t.Errorf("expected ErrPasswordRequired, got %v", err)
+	}
+	if _, _, err := r.Join(rm.ID, "p1", "One", Credentials{Password: "nope"}); err != ErrWrongPassword {
+		t.Errorf("expected ErrWrongPassword, got %v", err)
+	}
+	if _, _, err := r.Join(rm.ID, "p1", "One", Credentials{Password: "hunter2"}); err != nil {
+		t.Fatalf("join with password failed: %v", err)
+	}
+
+	// Members rejoin without the password
+	if _, err := rm.Join("p1", "One"); err != nil {
+		t.Errorf("expected member rejoin, got %v", err)
+	}
+	if _, _, err := r.Spectate(rm.ID, "s1", "Watcher", Credentials{}); err != ErrPasswordRequired {
+		t.Errorf("expected spectators to need the password, got %v", err)
+	}
+}
+
+func TestRoomInvites(t *testing.T) {
+	r := NewRegistry(DefaultConfig())
+	rm, invite := r.CreateWithOptions(Options{InviteOnly: true})
+
+	if _, err := rm.Join("p1", "One"); err != ErrInviteRequired {
+		t.Errorf("expected ErrInviteRequired, got %v", err)
+	}
+	if _, err := rm.JoinWith("p1", "One", Credentials{Invite: "garbage"}); err != ErrInvalidInvite {
+		t.Errorf("expected ErrInvalidInvite, got %v", err)
+	}
+	if _, err := rm.JoinWith("p1", "One", Credentials{Invite: invite}); err != nil {
+		t.Fatalf("join with invite failed: %v", err)
+	}
+
+	// Invites only work for the room they were minted for
+	_, otherInvite := r.CreateWithOptions(Options{InviteOnly: true})
+	if _, err := rm.JoinWith("p2", "Two", Credentials{Invite: otherInvite}); err != ErrInvalidInvite {
+		t.Errorf("expected ErrInvalidInvite for other room's invite, got %v", err)
+	}
+
+	if _, err := rm.CreateInvite("p2", time.Minute); err != ErrNotHost {
+		t.Errorf("expected ErrNotHost, got %v", err)
+	}
+	if _, err := rm.CreateInvite("p1", time.Minute); err != nil {
+		t.Fatalf("create invite failed: %v", err)
+	}
+	expired := r.invites.MintRole("", rm.ID, auth.RoleInvite, -time.Minute)
+	if _, err := rm.JoinWith("p2", "Two", Credentials{Invite: expired}); err != ErrInviteExpired {
+		t.Errorf("expected ErrInviteExpired, got %v", err)
+	}
+}
+
+func TestRoomSeats(t *testing.T) {
+	r := NewRegistry(DefaultConfig())
+	rm, _ := r.CreateWithOptions(Options{Seats: []string{"p1", "p2"}})
+
+	if _, err := rm.Join("p3", "Three"); err != ErrSeatReserved {
+		t.Errorf("expected ErrSeatReserved, got %v", err)
+	}
+	if _, err := rm.Join("p1", "One"); err != nil {
+		t.Fatalf("seated join failed: %v", err)
+	}
+
+	// Seats survive a restart
+	back := FromRecord(rm.Record())
+	if _, err := back.Join("p3", "Three"); err != ErrSeatReserved {
+		t.Errorf("expected seats restored from the record, got %v", err)
+	}
+}
@@ -0,0 +1,220 @@
+package room
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Chat defaults
+const (
+	DefaultChatHistory    = 50
+	DefaultChatRate       = 5 // Messages per ChatRateWindow
+	DefaultChatRateWindow = 5 * time.Second
+	DefaultMaxChatLength  = 280
+)
+
+// ChatMessage is one line of room chat
+type ChatMessage struct {
+	ID       string    `json:"id"`
+	From     string    `json:"from"`
+	FromName string    `json:"from_name"`
+	To       string    `json:"to,omitempty"` // Whisper target; empty for the whole room
+	Text     string    `json:"text"`
+	Time     time.Time `json:"time"`
+}
+
+// IsWhisper returns true if the message is for a single player
+func (m ChatMessage) IsWhisper() bool {
+	return m.To != ""
+}
+
+// ChatFilter cleans chat text before it's sent. Returning false drops the
+// message.
+type ChatFilter interface {
+	Filter(text string) (string, bool)
+}
+
+// WordFilter masks listed words (whole words, any case) with asterisks
+type WordFilter struct {
+	words map[string]struct{}
+}
+
+// NewWordFilter creates a filter for the given words
+func NewWordFilter(words ...string) *WordFilter {
+	f := &WordFilter{words: make(map[string]struct{}, len(words))}
+	for _, w := range words {
+		f.words[strings.ToLower(w)] = struct{}{}
+	}
+	return f
+}
+
+// Filter masks every listed word in text
+func (f *WordFilter) Filter(text string) (string, bool) {
+	runes := []rune(text)
+	for start := 0; start < len(runes); {
+		if !isWordRune(runes[start]) {
+			start++
+			continue
+		}
+		end := start
+		for end < len(runes) && isWordRune(runes[end]) {
+			end++
+		}
+		if _, bad := f.words[strings.ToLower(string(runes[start:end]))]; bad {
+			for i := start; i < end; i++ {
+				runes[i] = '*'
+			}
+		}
+		start = end
+	}
+	return string(runes), true
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// chatLog is a fixed-size ring of recent messages
+type chatLog struct {
+	msgs []ChatMessage
+	next int
+	full bool
+}
+
+func (l *chatLog) add(m ChatMessage) {
+	if len(l.msgs) == 0 {
+		return
+	}
+	l.msgs[l.next] = m
+	l.next = (l.next + 1) % len(l.msgs)
+	if l.next == 0 {
+		l.full = true
+	}
+}
+
+// all returns the messages oldest first
+func (l *chatLog) all() []ChatMessage {
+	if !l.full {
+		return append([]ChatMessage(nil), l.msgs[:l.next]...)
+	}
+	return append(append([]ChatMessage(nil), l.msgs[l.next:]...), l.msgs[:l.next]...)
+}
+
+// Chat sends a message to the room, or to one member if toID is set.
+// Whispers aren't kept in the history.
+func (room *Room) Chat(fromID, toID, text string) (*ChatMessage, error) {
+	room.mu.Lock()
+	defer room.mu.Unlock()
+
+	if !room.hasMemberLocked(fromID) {
+		return nil, ErrNotInRoom
+	}
+	if _, muted := room.muted[fromID]; muted {
+		return nil, ErrMuted
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, ErrEmptyMessage
+	}
+	if len([]rune(text)) > room.config.MaxChatLength {
+		return nil, ErrMessageTooLong
+	}
+	if toID != "" && !room.hasMemberLocked(toID) {
+		return nil, ErrNotInRoom
+	}
+	now := time.Now()
+	if !room.allowChatLocked(fromID, now) {
+		return nil, ErrChatRateLimited
+	}
+	if room.config.ChatFilter != nil {
+		filtered, ok := room.config.ChatFilter.Filter(text)
+		if !ok {
+			return nil, ErrMessageFiltered
+		}
+		text = filtered
+	}
+
+	room.chatSeq++
+	msg := ChatMessage{
+		ID:       strconv.FormatUint(room.chatSeq, 10),
+		From:     fromID,
+		FromName: room.memberNameLocked(fromID),
+		To:       toID,
+		Text:     text,
+		Time:     now,
+	}
+	if !msg.IsWhisper() {
+		room.chatLogLocked().add(msg)
+	}
+	room.lastActivity = now
+	room.publish(Event{Type: EventChat, PlayerID: fromID, Chat: &msg})
+	return &msg, nil
+}
+
+// ChatHistory returns recent room messages, oldest first
+func (room *Room) ChatHistory() []ChatMessage {
+	room.mu.Lock()
+	defer room.mu.Unlock()
+	return room.chatLogLocked().all()
+}
+
+// Mute stops a player or spectator from chatting (host only)
+func (room *Room) Mute(hostID, targetID string, muted bool) error {
+	room.mu.Lock()
+	defer room.mu.Unlock()
+
+	if err := room.checkKickLocked(hostID, targetID); err != nil {
+		return err
+	}
+	if room.muted == nil {
+		room.muted = make(map[string]struct{})
+	}
+	if muted {
+		room.muted[targetID] = struct{}{}
+	} else {
+		delete(room.muted, targetID)
+	}
+	return nil
+}
+
+// IsMuted returns true if the member may not chat
+func (room *Room) IsMuted(id string) bool {
+	room.mu.RLock()
+	defer room.mu.RUnlock()
+	_, muted := room.muted[id]
+	return muted
+}
+
+// allowChatLocked applies the per-member sliding window rate limit
+func (room *Room) allowChatLocked(id string, now time.Time) bool {
+	if room.chatSent == nil {
+		room.chatSent = make(map[string][]time.Time)
+	}
+	cutoff := now.Add(-room.config.ChatRateWindow)
+	sent := room.chatSent[id]
+	for len(sent) > 0 && !sent[0].After(cutoff) {
+		sent = sent[1:]
+	}
+	if len(sent) >= room.config.ChatRate {
+		room.chatSent[id] = sent
+		return false
+	}
+	room.chatSent[id] = append(sent, now)
+	return true
+}
+
+func (room *Room) chatLogLocked() *chatLog {
+	if room.chat == nil {
+		room.chat = &chatLog{msgs: make([]ChatMessage, room.config.ChatHistory)}
+	}
+	return room.chat
+}
+
+func (room *Room) memberNameLocked(id string) string {
+	if p, ok := room.Players[id]; ok {
+		return p.Name
+	}
+	return room.Spectators[id].Name
+}
@@ -0,0 +1,75 @@
+package room
+
+import (
+	"testing"
+	"time"
+)
+
+func TestChatHistoryAndWhispers(t *testing.T) {
+	config := DefaultConfig()
+	config.ChatHistory = 3
+	config.ChatRate = 100
+	r := NewRegistry(config)
+	defer r.Close()
+	rm := r.Create()
+	rm.Join("a", "Alice")
+	rm.Join("b", "Bob")
+
+	sub := r.Subscribe()
+	for _, text := range []string{"one", "two", "three", "four"} {
+		if _, err := rm.Chat("a", "", text); err != nil {
+			t.Fatalf("chat failed: %v", err)
+		}
+	}
+	if e := nextEvent(t, sub, EventChat); e.Chat.Text != "one" || e.Chat.FromName != "Alice" {
+		t.Errorf("expected first message event from Alice, got %+v", e.Chat)
+	}
+
+	whisper, err := rm.Chat("b", "a", "psst")
+	if err != nil || !whisper.IsWhisper() {
+		t.Fatalf("whisper failed: %v", err)
+	}
+	if _, err := rm.Chat("b", "nobody", "hi"); err != ErrNotInRoom {
+		t.Errorf("expected ErrNotInRoom for unknown target, got %v", err)
+	}
+
+	history := rm.ChatHistory()
+	if len(history) != 3 || history[0].Text != "two" || history[2].Text != "four" {
+		t.Errorf("expected last 3 room messages, got %+v", history)
+	}
+}
+
+func TestChatModeration(t *testing.T) {
+	config := DefaultConfig()
+	config.ChatRate = 2
+	config.ChatRateWindow = time.Hour
+	config.ChatFilter = NewWordFilter("darn")
+	r := NewRegistry(config)
+	defer r.Close()
+	rm := r.Create()
+	rm.Join("host", "Host")
+	rm.Join("p", "Player")
+
+	msg, _ := rm.Chat("p", "", "Darn it, darned")
+	if msg.Text != "**** it, darned" {
+		t.Errorf("expected whole word masked, got %q", msg.Text)
+	}
+	rm.Chat("p", "", "again")
+	if _, err := rm.Chat("p", "", "spam"); err != ErrChatRateLimited {
+		t.Errorf("expected ErrChatRateLimited, got %v", err)
+	}
+	if _, err := rm.Chat("host", "", "   "); err != ErrEmptyMessage {
+		t.Errorf("expected ErrEmptyMessage, got %v", err)
+	}
+
+	if err := rm.Mute("p", "host", true); err != ErrNotHost {
+		t.Errorf("expected ErrNotHost, got %v", err)
+	}
+	rm.Mute("host", "p", true)
+	if _, err := rm.Chat("p", "", "hello"); err != ErrMuted {
+		t.Errorf("expected ErrMuted, got %v", err)
+	}
+	if _, err := rm.Chat("stranger", "", "hello"); err != ErrNotInRoom {
+		t.Errorf("expected ErrNotInRoom, got %v", err)
+	}
+}
@@ -3,8 +3,33 @@ package room
 import "errors"
 
 var (
-	ErrRoomNotFound = errors.New("room not found")
-	ErrRoomFull     = errors.New("room is full")
-	ErrNotHost      = errors.New("only host can perform this action")
-	ErrNotInRoom    = errors.New("player not in room")
-)
\ No newline at end of file
+	ErrRoomNotFound     = errors.New("room not found")
+	ErrRoomFull         = errors.New("room is full")
+	ErrNotHost          = errors.New("only host can perform this action")
+	ErrNotInRoom        = errors.New("player not in room")
+	ErrSpectatorsFull   = errors.New("room has no spectator slots left")
+	ErrNotSpectator     = errors.New("not a spectator in this room")
+	ErrWrongPhase       = errors.New("not allowed in the room's current phase")
+	ErrPlayersNotReady  = errors.New("not all players are ready")
+	ErrRoomClosed       = errors.New("room is closed")
+	ErrRoomLocked       = errors.New("room is locked")
+	ErrBanned           = errors.New("banned from this room")
+	ErrCannotKickHost   = errors.New("host cannot kick themselves")
+	ErrPasswordRequired = errors.New("room requires a password")
+	ErrWrongPassword    = errors.New("wrong room password")
+	ErrInviteRequired   = errors.New("room is invite-only")
+	ErrInvalidInvite    = errors.New("invalid invite")
+	ErrInviteExpired    = errors.New("invite has expired")
+	ErrSeatReserved     = errors.New("room is reserved for matched players")
+	ErrInvalidQuery     = errors.New("invalid room query")
+	ErrInvalidCursor    = errors.New("invalid cursor")
+	ErrNoTeams          = errors.New("room has no teams")
+	ErrInvalidTeam      = errors.New("no such team")
+	ErrTeamFull         = errors.New("team is full")
+	ErrInvalidSettings  = errors.New("invalid room settings")
+	ErrMuted            = errors.New("muted in this room")
+	ErrEmptyMessage     = errors.New("message is empty")
+	ErrMessageTooLong   = errors.New("message is too long")
+	ErrChatRateLimited  = errors.New("sending messages too fast")
+	ErrMessageFiltered  = errors.New("message blocked by filter")
+)
@@ -0,0 +1,138 @@
+package room
+
+import (
+	"sync"
+	"time"
+)
+
+// EventType identifies a registry event
+type EventType string
+
+const (
+	EventRoomCreated  EventType = "room_created"
+	EventPlayerJoined EventType = "player_joined" // Also spectators (see Event.Spectator)
+	EventPlayerLeft   EventType = "player_left"   // Includes kicks and bans
+	EventHostChanged  EventType = "host_changed"  // PlayerID is the new host
+	EventPhaseChanged EventType = "phase_changed"
+	EventRoomExpired  EventType = "room_expired" // Already removed from the registry
+	EventTeamChanged  EventType = "team_changed" // PlayerID moved to Team
+	EventChat         EventType = "chat"         // PlayerID sent Chat
+)
+
+// Event is something that happened to a room
+type Event struct {
+	Type      EventType
+	Room      *Room
+	PlayerID  string       // Joined, left, or new host
+	Spectator bool         // Joined or left as a spectator
+	From, To  Phase        // Phase changes
+	Team      int          // Team changes
+	Chat      *ChatMessage // Chat messages
+	Time      time.Time
+}
+
+// Subscription delivers registry events in order. Events queue up rather
+// than block the room that raised them, so a slow reader never stalls
+// the registry.
+type Subscription struct {
+	C <-chan Event // Closed by Close or when the registry closes
+
+	c     chan Event
+	queue []Event
+	wake  chan struct{}
+	done  chan struct{}
+	once  sync.Once
+	mu    sync.Mutex
+}
+
+// Subscribe starts a new event stream
+func (r *Registry) Subscribe() *Subscription {
+	c := make(chan Event)
+	sub := &Subscription{
+		C:    c,
+		c:    c,
+		wake: make(chan struct{}, 1),
+		done: make(chan struct{}),
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		sub.Close()
+	} else {
+		r.subs[sub] = struct{}{}
+	}
+	go sub.pump()
+	return sub
+}
+
+// Unsubscribe stops a stream and closes its channel
+func (r *Registry) Unsubscribe(sub *Subscription) {
+	r.mu.Lock()
+	delete(r.subs, sub)
+	r.mu.Unlock()
+	sub.Close()
+}
+
+// Close stops delivery and closes C. Queued events are dropped.
+func (s *Subscription) Close() {
+	s.once.Do(func() { close(s.done) })
+}
+
+// push queues an event without blocking
+func (s *Subscription) push(e Event) {
+	s.mu.Lock()
+	s.queue = append(s.queue, e)
+	s.mu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// pump feeds queued events to C until closed
+func (s *Subscription) pump() {
+	defer close(s.c)
+	for {
+		select {
+		case <-s.wake:
+		case <-s.done:
+			return
+		}
+
+		s.mu.Lock()
+		events := s.queue
+		s.queue = nil
+		s.mu.Unlock()
+
+		for _, e := range events {
+			select {
+			case s.c <- e:
+			case <-s.done:
+				return
+			}
+		}
+	}
+}
+
+// publish sends an event to every subscriber. Safe to call with room.mu
+// held: it never blocks.
+func (r *Registry) publish(e Event) {
+	e.Time = time.Now()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for sub := range r.subs {
+		sub.push(e)
+	}
+}
+
+// publish sends a room event through the room's registry, if any
+func (room *Room) publish(e Event) {
+	if room.registry == nil {
+		return
+	}
+	e.Room = room
+	room.registry.publish(e)
+}
@@ -0,0 +1,78 @@
+package room
+
+import (
+	"testing"
+	"time"
+)
+
+// nextEvent skips ahead to the next event of type want
+func nextEvent(t *testing.T, sub *Subscription, want EventType) Event {
+	t.Helper()
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case e, ok := <-sub.C:
+			if !ok {
+				t.Fatalf("subscription closed waiting for %s", want)
+			}
+			if e.Type == want {
+				return e
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for %s", want)
+		}
+	}
+}
+
+func TestRegistryEvents(t *testing.T) {
+	r := NewRegistry(DefaultConfig())
+	defer r.Close()
+	sub := r.Subscribe()
+
+	rm := r.Create()
+	rm.Join("host", "Host")
+	rm.Join("p2", "Player 2")
+	rm.Spectate("s1", "Watcher")
+	rm.Leave("host")
+
+	want := []Event{
+		{Type: EventRoomCreated},
+		{Type: EventPlayerJoined, PlayerID: "host"},
+		{Type: EventHostChanged, PlayerID: "host"},
+		{Type: EventPlayerJoined, PlayerID: "p2"},
+		{Type: EventPlayerJoined, PlayerID: "s1", Spectator: true},
+		{Type: EventPlayerLeft, PlayerID: "host"},
+		{Type: EventHostChanged, PlayerID: "p2"},
+	}
+	for _, w := range want {
+		e := <-sub.C
+		if e.Type != w.Type || e.PlayerID != w.PlayerID || e.Spectator != w.Spectator || e.Room != rm {
+			t.Errorf("expected %s %q, got %s %q", w.Type, w.PlayerID, e.Type, e.PlayerID)
+		}
+	}
+}
+
+func TestRegistryClose(t *testing.T) {
+	r := NewRegistry(DefaultConfig())
+	sub := r.Subscribe()
+
+	if err := r.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+	select {
+	case _, ok := <-sub.C:
+		if ok {
+			t.Error("expected no events after close")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("subscription not closed")
+	}
+
+	late := r.Subscribe()
+	if _, ok := <-late.C; ok {
+		t.Error("expected subscriptions to a closed registry to be closed")
+	}
+	if err := r.Close(); err != nil {
+		t.Errorf("expected second close to be a no-op, got %v", err)
+	}
+}
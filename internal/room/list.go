@@ -0,0 +1,225 @@
+package room
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Visibility controls whether a room shows up in the room browser
+type Visibility string
+
+const (
+	VisibilityPublic   Visibility = "public"   // Listed in the room browser
+	VisibilityUnlisted Visibility = "unlisted" // Joinable by link only
+)
+
+// ParseVisibility converts a string to a Visibility
+func ParseVisibility(s string) (Visibility, bool) {
+	switch v := Visibility(s); v {
+	case VisibilityPublic, VisibilityUnlisted:
+		return v, true
+	}
+	return "", false
+}
+
+// Sort orders for room listings
+const (
+	SortPlayers = "players" // Most players first
+	SortAge     = "age"     // Newest first
+)
+
+// DefaultListLimit and MaxListLimit bound a listing page
+const (
+	DefaultListLimit = 20
+	MaxListLimit     = 100
+)
+
+// ListQuery filters, sorts and pages the room browser
+type ListQuery struct {
+	Mode        string // Only rooms with this game mode ("" for any)
+	Tag         string // Only rooms with this tag ("" for any)
+	NotFull     bool   // Skip rooms with no free player slot
+	HasPassword *bool  // Only rooms with (true) or without (false) a password
+	Sort        string // SortPlayers (default) or SortAge
+	Reverse     bool   // Flip the sort order
+	Limit       int    // Page size (DefaultListLimit if 0)
+	Cursor      string // NextCursor from the previous page
+}
+
+// Listing is a point-in-time summary of a room for the browser
+type Listing struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Mode        string    `json:"mode"`
+	Tags        []string  `json:"tags"`
+	Players     int       `json:"players"`
+	MaxPlayers  int       `json:"max_players"`
+	Spectators  int       `json:"spectators"`
+	HasPassword bool      `json:"has_password"`
+	Phase       Phase     `json:"phase"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ListPage is one page of room listings
+type ListPage struct {
+	Rooms      []Listing `json:"rooms"`
+	NextCursor string    `json:"next_cursor,omitempty"` // Empty on the last page
+}
+
+// Listing returns a summary of the room
+func (room *Room) Listing() Listing {
+	room.mu.RLock()
+	defer room.mu.RUnlock()
+
+	return Listing{
+		ID:          room.ID,
+		Name:        room.Name,
+		Mode:        room.Mode,
+		Tags:        append([]string(nil), room.Tags...),
+		Players:     len(room.Players),
+		MaxPlayers:  room.MaxPlayer,
+		Spectators:  len(room.Spectators),
+		HasPassword: room.HasPassword,
+		Phase:       room.Phase,
+		CreatedAt:   room.CreatedAt,
+	}
+}
+
+// isListedLocked returns true if the room belongs in the room browser
+func (room *Room) isListedLocked() bool {
+	return room.Visibility == VisibilityPublic && !room.InviteOnly && room.Phase != PhaseClosed
+}
+
+// List returns a page of public rooms matching the query. Pages are
+// keyed on the sort value and room ID, so rooms created or filled between
+// requests shift the listing but never repeat within a page.
+func (r *Registry) List(q ListQuery) (ListPage, error) {
+	if q.Sort == "" {
+		q.Sort = SortPlayers
+	}
+	if q.Sort != SortPlayers && q.Sort != SortAge {
+		return ListPage{}, ErrInvalidQuery
+	}
+	if q.Limit <= 0 {
+		q.Limit = DefaultListLimit
+	}
+	if q.Limit > MaxListLimit {
+		q.Limit = MaxListLimit
+	}
+
+	var after *listKey
+	if q.Cursor != "" {
+		k, err := parseCursor(q.Cursor)
+		if err != nil {
+			return ListPage{}, err
+		}
+		after = &k
+	}
+
+	var listings []Listing
+	for _, room := range r.AllRooms() {
+		room.mu.RLock()
+		listed := room.isListedLocked()
+		room.mu.RUnlock()
+		if !listed {
+			continue
+		}
+		if l := room.Listing(); q.matches(l) {
+			listings = append(listings, l)
+		}
+	}
+
+	keys := make(map[string]listKey, len(listings))
+	for _, l := range listings {
+		keys[l.ID] = q.key(l)
+	}
+	sort.Slice(listings, func(i, j int) bool {
+		return keys[listings[i].ID].before(keys[listings[j].ID])
+	})
+
+	start := 0
+	if after != nil {
+		start = sort.Search(len(listings), func(i int) bool {
+			return after.before(keys[listings[i].ID])
+		})
+	}
+
+	page := ListPage{Rooms: listings[start:]}
+	if len(page.Rooms) > q.Limit {
+		page.Rooms = page.Rooms[:q.Limit]
+		page.NextCursor = keys[page.Rooms[q.Limit-1].ID].cursor()
+	}
+	if page.Rooms == nil {
+		page.Rooms = []Listing{}
+	}
+	return page, nil
+}
+
+// matches applies the query's filters to a listing
+func (q ListQuery) matches(l Listing) bool {
+	if q.Mode != "" && l.Mode != q.Mode {
+		return false
+	}
+	if q.NotFull && l.Players >= l.MaxPlayers {
+		return false
+	}
+	if q.HasPassword != nil && l.HasPassword != *q.HasPassword {
+		return false
+	}
+	if q.Tag != "" {
+		for _, tag := range l.Tags {
+			if tag == q.Tag {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// listKey orders listings: ascending value, then ascending ID
+type listKey struct {
+	value int64
+	id    string
+}
+
+// key builds the sort key for a listing (values negated for descending
+// orders so every page walks the keys upwards)
+func (q ListQuery) key(l Listing) listKey {
+	var v int64
+	switch q.Sort {
+	case SortAge:
+		v = -l.CreatedAt.UnixNano()
+	default:
+		v = -int64(l.Players)
+	}
+	if q.Reverse {
+		v = -v
+	}
+	return listKey{value: v, id: l.ID}
+}
+
+func (k listKey) before(other listKey) bool {
+	if k.value != other.value {
+		return k.value < other.value
+	}
+	return k.id < other.id
+}
+
+func (k listKey) cursor() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%s", k.value, k.id)))
+}
+
+func parseCursor(cursor string) (listKey, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return listKey{}, ErrInvalidCursor
+	}
+	var k listKey
+	if _, err := fmt.Sscanf(string(raw), "%d:%s", &k.value, &k.id); err != nil {
+		return listKey{}, ErrInvalidCursor
+	}
+	return k, nil
+}
@@ -0,0 +1,91 @@
+package room
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRegistryListFilters(t *testing.T) {
+	r := NewRegistry(DefaultConfig())
+
+	ffa := r.Create()
+	ffa.Mode = "ffa"
+	ffa.Join("a", "A")
+	ctf, _ := r.CreateWithOptions(Options{Mode: "ctf", Password: "pw", Tags: []string{"casual"}})
+	r.CreateWithOptions(Options{Mode: "ffa", Visibility: VisibilityUnlisted})
+	r.CreateWithOptions(Options{Mode: "ffa", InviteOnly: true})
+
+	page, err := r.List(ListQuery{})
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if len(page.Rooms) != 2 {
+		t.Fatalf("expected only public rooms listed, got %d", len(page.Rooms))
+	}
+	if page.Rooms[0].ID != ffa.ID {
+		t.Errorf("expected fullest room first")
+	}
+
+	page, _ = r.List(ListQuery{Mode: "ctf"})
+	if len(page.Rooms) != 1 || page.Rooms[0].ID != ctf.ID {
+		t.Errorf("expected mode filter to return ctf room, got %+v", page.Rooms)
+	}
+	noPassword := false
+	page, _ = r.List(ListQuery{HasPassword: &noPassword})
+	if len(page.Rooms) != 1 || page.Rooms[0].ID != ffa.ID {
+		t.Errorf("expected password filter to return ffa room, got %+v", page.Rooms)
+	}
+	page, _ = r.List(ListQuery{Tag: "casual"})
+	if len(page.Rooms) != 1 || page.Rooms[0].ID != ctf.ID {
+		t.Errorf("expected tag filter to return ctf room, got %+v", page.Rooms)
+	}
+
+	ffa.MaxPlayer = 1
+	page, _ = r.List(ListQuery{NotFull: true})
+	if len(page.Rooms) != 1 || page.Rooms[0].ID != ctf.ID {
+		t.Errorf("expected full room filtered out, got %+v", page.Rooms)
+	}
+
+	if _, err := r.List(ListQuery{Sort: "bogus"}); err != ErrInvalidQuery {
+		t.Errorf("expected ErrInvalidQuery, got %v", err)
+	}
+	if _, err := r.List(ListQuery{Cursor: "!!"}); err != ErrInvalidCursor {
+		t.Errorf("expected ErrInvalidCursor, got %v", err)
+	}
+}
+
+func TestRegistryListPagination(t *testing.T) {
+	r := NewRegistry(DefaultConfig())
+	for i := 0; i < 7; i++ {
+		rm := r.Create()
+		for p := 0; p < i%3; p++ {
+			rm.Join(fmt.Sprintf("p%d", p), "P")
+		}
+	}
+
+	for _, sort := range []string{SortPlayers, SortAge} {
+		seen := make(map[string]bool)
+		var cursor string
+		pages := 0
+		for {
+			page, err := r.List(ListQuery{Sort: sort, Limit: 3, Cursor: cursor})
+			if err != nil {
+				t.Fatalf("%s: list failed: %v", sort, err)
+			}
+			for _, l := range page.Rooms {
+				if seen[l.ID] {
+					t.Errorf("%s: room %s listed twice", sort, l.ID)
+				}
+				seen[l.ID] = true
+			}
+			pages++
+			if page.NextCursor == "" {
+				break
+			}
+			cursor = page.NextCursor
+		}
+		if len(seen) != 7 || pages != 3 {
+			t.Errorf("%s: expected 7 rooms over 3 pages, got %d over %d", sort, len(seen), pages)
+		}
+	}
+}
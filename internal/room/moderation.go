@@ -0,0 +1,150 @@
+package room
+
+import "time"
+
+// Host returns the current host's player ID
+func (room *Room) Host() string {
+	room.mu.RLock()
+	defer room.mu.RUnlock()
+	return room.HostID
+}
+
+// IsLocked returns true if the room rejects new joins
+func (room *Room) IsLocked() bool {
+	room.mu.RLock()
+	defer room.mu.RUnlock()
+	return room.Locked
+}
+
+// IsBanned returns true if the player is banned from the room
+func (room *Room) IsBanned(playerID string) bool {
+	room.mu.RLock()
+	defer room.mu.RUnlock()
+	_, banned := room.banned[playerID]
+	return banned
+}
+
+// Kick removes a player or spectator from the room (host only)
+func (room *Room) Kick(hostID, targetID string) error {
+	room.mu.Lock()
+	defer room.mu.Unlock()
+
+	if err := room.checkKickLocked(hostID, targetID); err != nil {
+		return err
+	}
+	if !room.hasMemberLocked(targetID) {
+		return ErrNotInRoom
+	}
+	room.leaveLocked(targetID)
+	return nil
+}
+
+// Ban kicks a player (if present) and keeps them out for the room's
+// lifetime (host only)
+func (room *Room) Ban(hostID, targetID string) error {
+	room.mu.Lock()
+	if err := room.checkKickLocked(hostID, targetID); err != nil {
+		room.mu.Unlock()
+		return err
+	}
+	room.banned[targetID] = struct{}{}
+	if room.hasMemberLocked(targetID) {
+		room.leaveLocked(targetID)
+	}
+	room.mu.Unlock()
+
+	room.save()
+	return nil
+}
+
+// Unban lets a banned player join again (host only)
+func (room *Room) Unban(hostID, targetID string) error {
+	room.mu.Lock()
+	if !room.isHostLocked(hostID) {
+		room.mu.Unlock()
+		return ErrNotHost
+	}
+	delete(room.banned, targetID)
+	room.mu.Unlock()
+
+	room.save()
+	return nil
+}
+
+// TransferHost hands host powers to another player (host only)
+func (room *Room) TransferHost(hostID, newHostID string) error {
+	room.mu.Lock()
+	defer room.mu.Unlock()
+
+	if !room.isHostLocked(hostID) {
+		return ErrNotHost
+	}
+	newHost, ok := room.Players[newHostID]
+	if !ok {
+		return ErrNotInRoom
+	}
+
+	if old, ok := room.Players[hostID]; ok {
+		old.IsHost = false
+		room.Players[hostID] = old
+	}
+	newHost.IsHost = true
+	room.Players[newHostID] = newHost
+	room.HostID = newHostID
+	room.lastActivity = time.Now()
+	room.publish(Event{Type: EventHostChanged, PlayerID: newHostID})
+	return nil
+}
+
+// SetLocked locks or unlocks the room against new joins (host only).
+// Players and spectators already in the room are unaffected.
+func (room *Room) SetLocked(hostID string, locked bool) error {
+	room.mu.Lock()
+	if !room.isHostLocked(hostID) {
+		room.mu.Unlock()
+		return ErrNotHost
+	}
+	room.Locked = locked
+	room.lastActivity = time.Now()
+	room.mu.Unlock()
+
+	room.save()
+	return nil
+}
+
+// isHostLocked returns true if id is the room's host
+func (room *Room) isHostLocked(id string) bool {
+	return id != "" && id == room.HostID
+}
+
+// checkKickLocked verifies the host may remove targetID
+func (room *Room) checkKickLocked(hostID, targetID string) error {
+	if !room.isHostLocked(hostID) {
+		return ErrNotHost
+	}
+	if targetID == hostID {
+		return ErrCannotKickHost
+	}
+	return nil
+}
+
+// hasMemberLocked returns true if id is a player or spectator
+func (room *Room) hasMemberLocked(id string) bool {
+	_, isPlayer := room.Players[id]
+	_, isSpectator := room.Spectators[id]
+	return isPlayer || isSpectator
+}
+
+// checkJoinLocked rejects banned players and new joins to locked rooms
+func (room *Room) checkJoinLocked(id string) error {
+	if room.Phase == PhaseClosed {
+		return ErrRoomClosed
+	}
+	if _, banned := room.banned[id]; banned {
+		return ErrBanned
+	}
+	if room.Locked && !room.hasMemberLocked(id) {
+		return ErrRoomLocked
+	}
+	return nil
+}
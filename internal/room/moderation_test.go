@@ -0,0 +1,65 @@
+package room
+
+import "testing"
+
+func TestRoomKickAndBan(t *testing.T) {
+	_, rm := newTestRoom(t, 0)
+	rm.Spectate("s1", "Watcher")
+
+	if err := rm.Kick("p2", "host"); err != ErrNotHost {
+		t.Errorf("expected ErrNotHost, got %v", err)
+	}
+	if err := rm.Kick("host", "host"); err != ErrCannotKickHost {
+		t.Errorf("expected ErrCannotKickHost, got %v", err)
+	}
+	if err := rm.Kick("host", "nobody"); err != ErrNotInRoom {
+		t.Errorf("expected ErrNotInRoom, got %v", err)
+	}
+
+	if err := rm.Kick("host", "s1"); err != nil {
+		t.Fatalf("kick spectator failed: %v", err)
+	}
+	if rm.IsSpectator("s1") {
+		t.Error("expected spectator removed")
+	}
+
+	if err := rm.Ban("host", "p2"); err != nil {
+		t.Fatalf("ban failed: %v", err)
+	}
+	if rm.PlayerCount() != 1 {
+		t.Errorf("expected banned player removed, got %d players", rm.PlayerCount())
+	}
+	if _, err := rm.Join("p2", "Again"); err != ErrBanned {
+		t.Errorf("expected ErrBanned, got %v", err)
+	}
+
+	rm.Unban("host", "p2")
+	if _, err := rm.Join("p2", "Again"); err != nil {
+		t.Errorf("expected rejoin after unban, got %v", err)
+	}
+}
+
+func TestRoomTransferHostAndLock(t *testing.T) {
+	_, rm := newTestRoom(t, 0)
+
+	if err := rm.TransferHost("host", "nobody"); err != ErrNotInRoom {
+		t.Errorf("expected ErrNotInRoom, got %v", err)
+	}
+	if err := rm.TransferHost("host", "p2"); err != nil {
+		t.Fatalf("transfer failed: %v", err)
+	}
+	if rm.Host() != "p2" || !rm.Players["p2"].IsHost || rm.Players["host"].IsHost {
+		t.Error("expected p2 to be the only host")
+	}
+
+	if err := rm.SetLocked("host", true); err != ErrNotHost {
+		t.Errorf("expected ErrNotHost for former host, got %v", err)
+	}
+	rm.SetLocked("p2", true)
+	if _, err := rm.Join("p3", "Late"); err != ErrRoomLocked {
+		t.Errorf("expected ErrRoomLocked, got %v", err)
+	}
+	if _, err := rm.Join("host", "Host"); err != nil {
+		t.Errorf("expected existing member unaffected by lock, got %v", err)
+	}
+}
@@ -0,0 +1,221 @@
+package room
+
+import "time"
+
+// Phase is where a room is in its lifecycle
+type Phase string
+
+const (
+	PhaseLobby     Phase = "lobby"     // Players gather and ready up
+	PhaseCountdown Phase = "countdown" // Host started; game begins when the countdown ends
+	PhaseInGame    Phase = "in_game"   // Game running, inputs accepted
+	PhaseResults   Phase = "results"   // Game over, showing results
+	PhaseClosed    Phase = "closed"    // Room shut down by the host
+)
+
+// ParsePhase converts a string to a Phase
+func ParsePhase(s string) (Phase, bool) {
+	switch p := Phase(s); p {
+	case PhaseLobby, PhaseCountdown, PhaseInGame, PhaseResults, PhaseClosed:
+		return p, true
+	}
+	return "", false
+}
+
+// phaseChanged publishes a phase change event
+func (room *Room) phaseChanged(from, to Phase) {
+	if from == to {
+		return
+	}
+	room.publish(Event{Type: EventPhaseChanged, From: from, To: to})
+}
+
+// GetPhase returns the room's current phase
+func (room *Room) GetPhase() Phase {
+	room.mu.RLock()
+	defer room.mu.RUnlock()
+	return room.Phase
+}
+
+// CountdownRemaining returns how long until the game starts (0 if not counting down)
+func (room *Room) CountdownRemaining() time.Duration {
+	room.mu.RLock()
+	defer room.mu.RUnlock()
+
+	if room.Phase != PhaseCountdown {
+		return 0
+	}
+	return time.Until(room.CountdownEnds)
+}
+
+// SetReady marks a player ready or not. Un-readying during the
+// countdown cancels it.
+func (room *Room) SetReady(playerID string, ready bool) error {
+	room.mu.Lock()
+	p, ok := room.Players[playerID]
+	if !ok {
+		room.mu.Unlock()
+		return ErrNotInRoom
+	}
+	if room.Phase != PhaseLobby && room.Phase != PhaseCountdown {
+		room.mu.Unlock()
+		return ErrWrongPhase
+	}
+
+	p.Ready = ready
+	room.Players[playerID] = p
+	room.lastActivity = time.Now()
+
+	from := room.Phase
+	if !ready && room.Phase == PhaseCountdown {
+		room.setPhaseLocked(PhaseLobby)
+	}
+	to := room.Phase
+	room.mu.Unlock()
+
+	room.phaseChanged(from, to)
+	return nil
+}
+
+// AllReady returns true if every player except the host is ready
+func (room *Room) AllReady() bool {
+	room.mu.RLock()
+	defer room.mu.RUnlock()
+	return room.allReadyLocked()
+}
+
+func (room *Room) allReadyLocked() bool {
+	for id, p := range room.Players {
+		if id != room.HostID && !p.Ready {
+			return false
+		}
+	}
+	return true
+}
+
+// StartCountdown begins the countdown to the game (host only, everyone ready)
+func (room *Room) StartCountdown(playerID string) error {
+	room.mu.Lock()
+	if err := room.checkHostLocked(playerID, PhaseLobby); err != nil {
+		room.mu.Unlock()
+		return err
+	}
+	if !room.allReadyLocked() {
+		room.mu.Unlock()
+		return ErrPlayersNotReady
+	}
+
+	countdown := room.config.Countdown
+	if countdown <= 0 {
+		room.setPhaseLocked(PhaseInGame)
+		room.mu.Unlock()
+		room.phaseChanged(PhaseLobby, PhaseInGame)
+		return nil
+	}
+
+	room.setPhaseLocked(PhaseCountdown)
+	room.CountdownEnds = time.Now().Add(countdown)
+	room.countdown = time.AfterFunc(countdown, room.finishCountdown)
+	room.mu.Unlock()
+
+	room.phaseChanged(PhaseLobby, PhaseCountdown)
+	return nil
+}
+
+// finishCountdown starts the game if the countdown wasn't cancelled
+func (room *Room) finishCountdown() {
+	room.mu.Lock()
+	if room.Phase != PhaseCountdown {
+		room.mu.Unlock()
+		return
+	}
+	room.setPhaseLocked(PhaseInGame)
+	room.mu.Unlock()
+
+	room.phaseChanged(PhaseCountdown, PhaseInGame)
+}
+
+// CancelCountdown returns to the lobby (host only)
+func (room *Room) CancelCountdown(playerID string) error {
+	return room.transition(playerID, PhaseCountdown, PhaseLobby)
+}
+
+// EndGame moves a running game to results (host only)
+func (room *Room) EndGame(playerID string) error {
+	return room.transition(playerID, PhaseInGame, PhaseResults)
+}
+
+// ReturnToLobby starts a new round from results (host only).
+// Everyone has to ready up again.
+func (room *Room) ReturnToLobby(playerID string) error {
+	room.mu.Lock()
+	if err := room.checkHostLocked(playerID, PhaseResults); err != nil {
+		room.mu.Unlock()
+		return err
+	}
+	for id, p := range room.Players {
+		p.Ready = false
+		room.Players[id] = p
+	}
+	room.setPhaseLocked(PhaseLobby)
+	room.mu.Unlock()
+
+	room.phaseChanged(PhaseResults, PhaseLobby)
+	return nil
+}
+
+// Close shuts the room down (host only). Closed rooms expire immediately.
+func (room *Room) Close(playerID string) error {
+	room.mu.Lock()
+	from := room.Phase
+	if err := room.checkHostLocked(playerID, ""); err != nil {
+		room.mu.Unlock()
+		return err
+	}
+	if from == PhaseClosed {
+		room.mu.Unlock()
+		return ErrRoomClosed
+	}
+	room.setPhaseLocked(PhaseClosed)
+	room.mu.Unlock()
+
+	room.phaseChanged(from, PhaseClosed)
+	return nil
+}
+
+// transition moves from one phase to another on behalf of the host
+func (room *Room) transition(playerID string, from, to Phase) error {
+	room.mu.Lock()
+	if err := room.checkHostLocked(playerID, from); err != nil {
+		room.mu.Unlock()
+		return err
+	}
+	room.setPhaseLocked(to)
+	room.mu.Unlock()
+
+	room.phaseChanged(from, to)
+	return nil
+}
+
+// checkHostLocked verifies playerID is the host and the room is in phase
+// (any phase if empty)
+func (room *Room) checkHostLocked(playerID string, phase Phase) error {
+	if !room.isHostLocked(playerID) {
+		return ErrNotHost
+	}
+	if phase != "" && room.Phase != phase {
+		return ErrWrongPhase
+	}
+	return nil
+}
+
+// setPhaseLocked changes phase and stops any pending countdown
+func (room *Room) setPhaseLocked(to Phase) {
+	if room.countdown != nil && to != PhaseCountdown {
+		room.countdown.Stop()
+		room.countdown = nil
+		room.CountdownEnds = time.Time{}
+	}
+	room.Phase = to
+	room.lastActivity = time.Now()
+}
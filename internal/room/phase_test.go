@@ -0,0 +1,95 @@
+package room
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestRoom(t *testing.T, countdown time.Duration) (*Registry, *Room) {
+	t.Helper()
+	config := DefaultConfig()
+	config.Countdown = countdown
+	r := NewRegistry(config)
+	rm := r.Create()
+	rm.Join("host", "Host")
+	rm.Join("p2", "Player 2")
+	return r, rm
+}
+
+func TestRoomLifecycle(t *testing.T) {
+	r, rm := newTestRoom(t, 0)
+	sub := r.Subscribe()
+	defer r.Unsubscribe(sub)
+
+	if rm.GetPhase() != PhaseLobby {
+		t.Fatalf("expected lobby, got %s", rm.GetPhase())
+	}
+	if err := rm.StartCountdown("p2"); err != ErrNotHost {
+		t.Errorf("expected ErrNotHost, got %v", err)
+	}
+	if err := rm.StartCountdown("host"); err != ErrPlayersNotReady {
+		t.Errorf("expected ErrPlayersNotReady, got %v", err)
+	}
+
+	rm.SetReady("p2", true)
+	if err := rm.StartCountdown("host"); err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+	if rm.GetPhase() != PhaseInGame {
+		t.Fatalf("expected in_game with no countdown, got %s", rm.GetPhase())
+	}
+	if err := rm.SetReady("p2", false); err != ErrWrongPhase {
+		t.Errorf("expected ErrWrongPhase, got %v", err)
+	}
+
+	if err := rm.EndGame("host"); err != nil {
+		t.Fatalf("end game failed: %v", err)
+	}
+	if err := rm.ReturnToLobby("host"); err != nil {
+		t.Fatalf("return to lobby failed: %v", err)
+	}
+	if rm.AllReady() {
+		t.Error("expected ready flags cleared in new lobby")
+	}
+
+	if err := rm.Close("host"); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+	if _, err := rm.Join("p3", "Late"); err != ErrRoomClosed {
+		t.Errorf("expected ErrRoomClosed, got %v", err)
+	}
+
+	for _, want := range []Phase{PhaseInGame, PhaseResults, PhaseLobby, PhaseClosed} {
+		if e := nextEvent(t, sub, EventPhaseChanged); e.To != want {
+			t.Errorf("expected change to %s, got %s", want, e.To)
+		}
+	}
+}
+
+func TestRoomCountdown(t *testing.T) {
+	r, rm := newTestRoom(t, 20*time.Millisecond)
+	sub := r.Subscribe()
+	defer r.Unsubscribe(sub)
+
+	rm.SetReady("p2", true)
+	if err := rm.StartCountdown("host"); err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+	if rm.GetPhase() != PhaseCountdown {
+		t.Fatalf("expected countdown, got %s", rm.GetPhase())
+	}
+
+	// Un-readying cancels the countdown
+	rm.SetReady("p2", false)
+	if rm.GetPhase() != PhaseLobby {
+		t.Fatalf("expected lobby after unready, got %s", rm.GetPhase())
+	}
+
+	rm.SetReady("p2", true)
+	rm.StartCountdown("host")
+	for {
+		if e := nextEvent(t, sub, EventPhaseChanged); e.To == PhaseInGame {
+			return
+		}
+	}
+}
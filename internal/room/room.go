@@ -3,24 +3,44 @@ package room
 import (
 	"crypto/rand"
 	"encoding/base64"
+	"log"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/LemmyAI/gameserver/internal/auth"
 )
 
 // Config for room settings
 type Config struct {
 	MaxPlayers    int           `json:"max_players"`
-	RoomTTL       time.Duration `json:"room_ttl"`        // Time before empty room expires
+	MaxSpectators int           `json:"max_spectators"`
+	RoomTTL       time.Duration `json:"room_ttl"`       // Time before empty room expires
 	CleanupPeriod time.Duration `json:"cleanup_period"` // How often to check for expired rooms
+	Countdown     time.Duration `json:"countdown"`      // Delay between host start and game start
+	InviteSecret  []byte        `json:"-"`              // Signs invites; random if empty (invites die on restart)
+
+	// Chat
+	ChatHistory    int           `json:"chat_history"` // Messages kept for players who join later
+	ChatRate       int           `json:"chat_rate"`    // Messages per member per ChatRateWindow
+	ChatRateWindow time.Duration `json:"chat_rate_window"`
+	MaxChatLength  int           `json:"max_chat_length"` // In characters
+	ChatFilter     ChatFilter    `json:"-"`               // Optional profanity filter
 }
 
 // DefaultConfig returns sensible defaults
 func DefaultConfig() Config {
 	return Config{
 		MaxPlayers:    8,
+		MaxSpectators: 16,
 		RoomTTL:       5 * time.Minute,
 		CleanupPeriod: 30 * time.Second,
+		Countdown:     5 * time.Second,
+
+		ChatHistory:    DefaultChatHistory,
+		ChatRate:       DefaultChatRate,
+		ChatRateWindow: DefaultChatRateWindow,
+		MaxChatLength:  DefaultMaxChatLength,
 	}
 }
 
@@ -39,88 +59,236 @@ type Player struct {
 	Name     string    `json:"name"`
 	JoinedAt time.Time `json:"joined_at"`
 	IsHost   bool      `json:"is_host"`
+	Ready    bool      `json:"ready"`
+	Team     int       `json:"team,omitempty"` // 1..Room.Teams, 0 without teams
+}
+
+// Spectator watches a room without occupying a player slot
+type Spectator struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	JoinedAt  time.Time `json:"joined_at"`
+	Following string    `json:"following,omitempty"` // Player ID the camera follows
 }
 
 // Room represents a game room
 type Room struct {
-	ID        string            `json:"id"`
-	CreatedAt time.Time         `json:"created_at"`
-	Players   map[string]Player `json:"players"`
-	HostID    string            `json:"host_id"`
-	MaxPlayer int               `json:"max_players"`
+	ID         string               `json:"id"`
+	CreatedAt  time.Time            `json:"created_at"`
+	Players    map[string]Player    `json:"players"`
+	Spectators map[string]Spectator `json:"spectators"`
+	HostID     string               `json:"host_id"`
+	MaxPlayer  int                  `json:"max_players"`
+
+	// Browser
+	Name       string     `json:"name"`
+	Mode       string     `json:"mode"`
+	Tags       []string   `json:"tags"`
+	Visibility Visibility `json:"visibility"`
+
+	// Teams (0 = free for all)
+	Teams int `json:"teams"`
+
+	// Game server settings
+	Settings Settings `json:"settings"`
+
+	// Lifecycle
+	Phase         Phase     `json:"phase"`
+	CountdownEnds time.Time `json:"countdown_ends"`
+
+	// Moderation
+	Locked bool                `json:"locked"`
+	banned map[string]struct{} // Player IDs kept out for the room's lifetime
+
+	// Access
+	HasPassword  bool `json:"has_password"`
+	InviteOnly   bool `json:"invite_only"`
+	passwordSalt []byte
+	passwordHash []byte
+	seats        map[string]struct{} // Player IDs a matchmade room is reserved for
+
+	// Chat
+	chat     *chatLog
+	chatSeq  uint64
+	chatSent map[string][]time.Time // Recent send times per member, for rate limits
+	muted    map[string]struct{}
 
 	// Internal
 	lastActivity time.Time
 	config       Config
+	countdown    *time.Timer
+	registry     *Registry
 	mu           sync.RWMutex
 }
 
 // Registry manages all rooms
 type Registry struct {
-	rooms  map[string]*Room
-	config Config
-	mu     sync.RWMutex
-
-	// Callbacks
-	onRoomExpired func(*Room)
+	store   Store
+	config  Config
+	invites *auth.Signer // Signs room invite tokens
+	mu      sync.RWMutex
+
+	// Events and shutdown
+	subs   map[*Subscription]struct{}
+	closed bool
+	stop   chan struct{} // Closed by Close to stop the cleanup loop
+	done   chan struct{} // Closed when the cleanup loop exits
 }
 
-// NewRegistry creates a new room registry
+// NewRegistry creates a new in-memory room registry
 func NewRegistry(config Config) *Registry {
+	return NewRegistryWithStore(config, NewMemoryStore())
+}
+
+// NewRegistryWithStore creates a room registry backed by store, adopting
+// any rooms it already holds
+func NewRegistryWithStore(config Config, store Store) *Registry {
+	secret := config.InviteSecret
+	if len(secret) == 0 {
+		secret = []byte(auth.GenerateSecret())
+	}
+
 	r := &Registry{
-		rooms:  make(map[string]*Room),
-		config: config,
+		store:   store,
+		config:  config,
+		invites: auth.NewSigner(secret),
+		subs:    make(map[*Subscription]struct{}),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	for _, room := range store.All() {
+		room.mu.Lock()
+		room.registry = r
+		room.config = config
+		if room.MaxPlayer == 0 {
+			room.MaxPlayer = config.MaxPlayers
+		}
+		room.mu.Unlock()
 	}
 	go r.cleanupLoop()
 	return r
 }
 
-// Create creates a new room and returns it
+// save writes a room's persistent state to the store
+func (r *Registry) save(room *Room) {
+	if err := r.store.Put(room); err != nil {
+		log.Printf("⚠️  Failed to save room %s: %v", room.ID, err)
+	}
+}
+
+// save persists the room (never called with room.mu held)
+func (room *Room) save() {
+	if room.registry != nil {
+		room.registry.save(room)
+	}
+}
+
+// Create creates a new public room and returns it
 func (r *Registry) Create() *Room {
-	r.mu.Lock()
-	defer r.mu.Unlock()
+	room, _ := r.CreateWithOptions(Options{})
+	return room
+}
 
+// CreateWithOptions creates a room and returns it with an invite token
+// for its creator
+func (r *Registry) CreateWithOptions(opts Options) (*Room, string) {
 	room := &Room{
 		ID:           generateID(),
 		CreatedAt:    time.Now(),
 		Players:      make(map[string]Player),
+		Spectators:   make(map[string]Spectator),
+		banned:       make(map[string]struct{}),
 		MaxPlayer:    r.config.MaxPlayers,
+		Phase:        PhaseLobby,
 		lastActivity: time.Now(),
 		config:       r.config,
+		registry:     r,
+		Name:         opts.Name,
+		Mode:         opts.Mode,
+		Tags:         opts.Tags,
+		Visibility:   opts.Visibility,
+		InviteOnly:   opts.InviteOnly,
+		Teams:        opts.Teams,
+		Settings:     opts.Settings,
 	}
-	r.rooms[room.ID] = room
-	return room
+	if opts.Settings.MaxPlayers > 0 {
+		room.MaxPlayer = opts.Settings.MaxPlayers
+	}
+	if room.Visibility == "" {
+		room.Visibility = VisibilityPublic
+	}
+	if room.Teams > room.MaxPlayer {
+		room.Teams = room.MaxPlayer
+	}
+	if opts.Seats != nil {
+		room.seats = make(map[string]struct{}, len(opts.Seats))
+		for _, id := range opts.Seats {
+			room.seats[id] = struct{}{}
+		}
+	}
+	room.setPasswordLocked(opts.Password)
+	r.save(room)
+	room.publish(Event{Type: EventRoomCreated})
+	return room, room.mintInviteLocked(DefaultInviteTTL)
+}
+
+// Config returns the registry's room configuration
+func (r *Registry) Config() Config {
+	return r.config
 }
 
 // Get retrieves a room by ID
 func (r *Registry) Get(id string) *Room {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-	return r.rooms[id]
+	return r.store.Get(id)
 }
 
 // Join adds a player to a room. Returns the room, player, or error.
-func (r *Registry) Join(roomID, playerID, playerName string) (*Room, *Player, error) {
-	r.mu.Lock()
-	room, exists := r.rooms[roomID]
-	if !exists {
-		r.mu.Unlock()
+func (r *Registry) Join(roomID, playerID, playerName string, creds Credentials) (*Room, *Player, error) {
+	room := r.Get(roomID)
+	if room == nil {
 		return nil, nil, ErrRoomNotFound
 	}
-	r.mu.Unlock()
 
-	player, err := room.Join(playerID, playerName)
+	player, err := room.JoinWith(playerID, playerName, creds)
 	if err != nil {
 		return nil, nil, err
 	}
 	return room, player, nil
 }
 
-// Join adds a player to the room
+// Spectate adds a spectator to a room. Returns the room, spectator, or error.
+func (r *Registry) Spectate(roomID, spectatorID, name string, creds Credentials) (*Room, *Spectator, error) {
+	room := r.Get(roomID)
+	if room == nil {
+		return nil, nil, ErrRoomNotFound
+	}
+
+	spectator, err := room.SpectateWith(spectatorID, name, creds)
+	if err != nil {
+		return nil, nil, err
+	}
+	return room, spectator, nil
+}
+
+// Join adds a player to the room without credentials (public rooms)
 func (room *Room) Join(playerID, playerName string) (*Player, error) {
+	return room.JoinWith(playerID, playerName, Credentials{})
+}
+
+// JoinWith adds a player to the room, checking password or invite
+func (room *Room) JoinWith(playerID, playerName string, creds Credentials) (*Player, error) {
 	room.mu.Lock()
 	defer room.mu.Unlock()
 
+	if err := room.checkJoinLocked(playerID); err != nil {
+		return nil, err
+	}
+	if err := room.checkSeatLocked(playerID); err != nil {
+		return nil, err
+	}
+	if err := room.checkCredentialsLocked(playerID, creds); err != nil {
+		return nil, err
+	}
 	if len(room.Players) >= room.MaxPlayer {
 		return nil, ErrRoomFull
 	}
@@ -142,19 +310,137 @@ func (room *Room) Join(playerID, playerName string) (*Player, error) {
 		JoinedAt: time.Now(),
 		IsHost:   isHost,
 	}
+	if room.Teams > 0 {
+		player.Team = room.smallestTeamLocked()
+	}
 	room.Players[playerID] = player
 	room.lastActivity = time.Now()
+	room.publish(Event{Type: EventPlayerJoined, PlayerID: playerID})
+	if isHost {
+		room.publish(Event{Type: EventHostChanged, PlayerID: playerID})
+	}
+	if player.Team != 0 {
+		room.publish(Event{Type: EventTeamChanged, PlayerID: playerID, Team: player.Team})
+	}
 
 	return &player, nil
 }
 
-// Leave removes a player from the room
+// Spectate adds a spectator to the room without credentials (public rooms)
+func (room *Room) Spectate(spectatorID, name string) (*Spectator, error) {
+	return room.SpectateWith(spectatorID, name, Credentials{})
+}
+
+// SpectateWith adds a spectator to the room, checking password or invite
+func (room *Room) SpectateWith(spectatorID, name string, creds Credentials) (*Spectator, error) {
+	room.mu.Lock()
+	defer room.mu.Unlock()
+
+	if err := room.checkJoinLocked(spectatorID); err != nil {
+		return nil, err
+	}
+	if err := room.checkCredentialsLocked(spectatorID, creds); err != nil {
+		return nil, err
+	}
+	if s, exists := room.Spectators[spectatorID]; exists {
+		return &s, nil
+	}
+	if len(room.Spectators) >= room.config.MaxSpectators {
+		return nil, ErrSpectatorsFull
+	}
+
+	spectator := Spectator{
+		ID:       spectatorID,
+		Name:     name,
+		JoinedAt: time.Now(),
+	}
+	room.Spectators[spectatorID] = spectator
+	room.lastActivity = time.Now()
+	room.publish(Event{Type: EventPlayerJoined, PlayerID: spectatorID, Spectator: true})
+
+	return &spectator, nil
+}
+
+// Follow points a spectator's camera at a player ("" to stop following)
+func (room *Room) Follow(spectatorID, playerID string) error {
+	room.mu.Lock()
+	defer room.mu.Unlock()
+
+	s, ok := room.Spectators[spectatorID]
+	if !ok {
+		return ErrNotSpectator
+	}
+	if playerID != "" {
+		if _, ok := room.Players[playerID]; !ok {
+			return ErrNotInRoom
+		}
+	}
+	s.Following = playerID
+	room.Spectators[spectatorID] = s
+	return nil
+}
+
+// IsPlayer returns true if the ID belongs to a player
+func (room *Room) IsPlayer(id string) bool {
+	room.mu.RLock()
+	defer room.mu.RUnlock()
+	_, ok := room.Players[id]
+	return ok
+}
+
+// IsSpectator returns true if the ID belongs to a spectator
+func (room *Room) IsSpectator(id string) bool {
+	room.mu.RLock()
+	defer room.mu.RUnlock()
+	_, ok := room.Spectators[id]
+	return ok
+}
+
+// SpectatorCount returns the number of spectators in the room
+func (room *Room) SpectatorCount() int {
+	room.mu.RLock()
+	defer room.mu.RUnlock()
+	return len(room.Spectators)
+}
+
+// SpectatorIDs returns a list of spectator IDs in the room
+func (room *Room) SpectatorIDs() []string {
+	room.mu.RLock()
+	defer room.mu.RUnlock()
+	ids := make([]string, 0, len(room.Spectators))
+	for id := range room.Spectators {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// Leave removes a player or spectator from the room
 func (room *Room) Leave(playerID string) {
 	room.mu.Lock()
 	defer room.mu.Unlock()
+	room.leaveLocked(playerID)
+}
 
+// leaveLocked removes a member and hands off host if needed
+func (room *Room) leaveLocked(playerID string) {
+	_, isPlayer := room.Players[playerID]
+	_, isSpectator := room.Spectators[playerID]
+	if !isPlayer && !isSpectator {
+		return
+	}
 	delete(room.Players, playerID)
+	delete(room.Spectators, playerID)
+	delete(room.chatSent, playerID)
 	room.lastActivity = time.Now()
+	room.publish(Event{Type: EventPlayerLeft, PlayerID: playerID, Spectator: isSpectator})
+
+	// Spectators following the leaving player lose their target
+	for id, s := range room.Spectators {
+		if s.Following == playerID {
+			s.Following = ""
+			room.Spectators[id] = s
+		}
+	}
 
 	// If host left, assign new host
 	if playerID == room.HostID && len(room.Players) > 0 {
@@ -163,6 +449,7 @@ func (room *Room) Leave(playerID string) {
 			p.IsHost = true
 			room.Players[id] = p
 			room.HostID = id
+			room.publish(Event{Type: EventHostChanged, PlayerID: id})
 			break
 		}
 	}
@@ -186,17 +473,30 @@ func (room *Room) PlayerIDs() []string {
 	return ids
 }
 
+// GetPlayer returns a player in the room
+func (room *Room) GetPlayer(playerID string) (Player, bool) {
+	room.mu.RLock()
+	defer room.mu.RUnlock()
+	p, ok := room.Players[playerID]
+	return p, ok
+}
+
 // IsEmpty returns true if the room has no players
 func (room *Room) IsEmpty() bool {
 	return room.PlayerCount() == 0
 }
 
 // IsExpired returns true if the room has been empty longer than TTL
+// (spectators keep a room alive too) or was closed
 func (room *Room) IsExpired() bool {
 	room.mu.RLock()
 	defer room.mu.RUnlock()
 
-	if len(room.Players) > 0 {
+	if room.Phase == PhaseClosed {
+		return true
+	}
+
+	if len(room.Players) > 0 || len(room.Spectators) > 0 {
 		return false
 	}
 	return time.Since(room.lastActivity) > room.config.RoomTTL
@@ -204,50 +504,60 @@ func (room *Room) IsExpired() bool {
 
 // Delete removes a room from the registry
 func (r *Registry) Delete(roomID string) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	delete(r.rooms, roomID)
+	if err := r.store.Delete(roomID); err != nil {
+		log.Printf("⚠️  Failed to delete room %s: %v", roomID, err)
+	}
 }
 
-// OnRoomExpired sets a callback for when a room expires
-func (r *Registry) OnRoomExpired(callback func(*Room)) {
-	r.onRoomExpired = callback
+// Close stops the cleanup loop, ends every event subscription and closes
+// the store. The registry must not be used afterwards.
+func (r *Registry) Close() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	subs := r.subs
+	r.subs = make(map[*Subscription]struct{})
+	r.mu.Unlock()
+
+	close(r.stop)
+	<-r.done
+	for sub := range subs {
+		sub.Close()
+	}
+	return r.store.Close()
 }
 
-// cleanupLoop periodically removes expired rooms
+// cleanupLoop periodically removes expired rooms until Close
 func (r *Registry) cleanupLoop() {
+	defer close(r.done)
 	ticker := time.NewTicker(r.config.CleanupPeriod)
 	defer ticker.Stop()
 
-	for range ticker.C {
-		r.mu.Lock()
-		for id, room := range r.rooms {
+	for {
+		select {
+		case <-ticker.C:
+		case <-r.stop:
+			return
+		}
+
+		for _, room := range r.store.All() {
 			if room.IsExpired() {
-				if r.onRoomExpired != nil {
-					go r.onRoomExpired(room)
-				}
-				delete(r.rooms, id)
+				r.Delete(room.ID)
+				room.publish(Event{Type: EventRoomExpired})
 			}
 		}
-		r.mu.Unlock()
 	}
 }
 
-// AllRooms returns all active rooms (for debugging/admin)
+// AllRooms returns all active rooms, listed or not (see List for the browser)
 func (r *Registry) AllRooms() []*Room {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-
-	rooms := make([]*Room, 0, len(r.rooms))
-	for _, room := range r.rooms {
-		rooms = append(rooms, room)
-	}
-	return rooms
+	return r.store.All()
 }
 
 // Count returns the total number of rooms
 func (r *Registry) Count() int {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-	return len(r.rooms)
-}
\ No newline at end of file
+	return r.store.Count()
+}
@@ -0,0 +1,55 @@
+package room
+
+import "fmt"
+
+// Settings limits
+const (
+	MinTickRate  = 10
+	MaxTickRate  = 120
+	MinWorldSize = 100
+	MaxWorldSize = 10000
+	MaxSpeed     = 1000
+)
+
+// Settings tune the game server a room runs on. Zero fields use the
+// game server's defaults.
+type Settings struct {
+	TickRate    int     `json:"tick_rate,omitempty"`    // Ticks per second
+	MaxPlayers  int     `json:"max_players,omitempty"`  // Player slots (up to Config.MaxPlayers)
+	WorldWidth  float32 `json:"world_width,omitempty"`  // World bounds
+	WorldHeight float32 `json:"world_height,omitempty"` // World bounds
+	PlayerSpeed float32 `json:"player_speed,omitempty"` // Units per second
+
+	// VisionRadius hides players beyond this distance unless they're
+	// teammates (0 = everyone sees everyone)
+	VisionRadius float32 `json:"vision_radius,omitempty"`
+}
+
+// Validate checks settings against the limits and the registry's player
+// cap
+func (s Settings) Validate(maxPlayers int) error {
+	switch {
+	case s.TickRate != 0 && (s.TickRate < MinTickRate || s.TickRate > MaxTickRate):
+		return fmt.Errorf("%w: tick rate must be %d-%d", ErrInvalidSettings, MinTickRate, MaxTickRate)
+	case s.MaxPlayers < 0 || s.MaxPlayers > maxPlayers:
+		return fmt.Errorf("%w: max players must be 1-%d", ErrInvalidSettings, maxPlayers)
+	case !validWorldSize(s.WorldWidth) || !validWorldSize(s.WorldHeight):
+		return fmt.Errorf("%w: world size must be %d-%d", ErrInvalidSettings, MinWorldSize, MaxWorldSize)
+	case s.PlayerSpeed < 0 || s.PlayerSpeed > MaxSpeed:
+		return fmt.Errorf("%w: player speed must be up to %d", ErrInvalidSettings, MaxSpeed)
+	case s.VisionRadius < 0 || s.VisionRadius > MaxWorldSize:
+		return fmt.Errorf("%w: vision radius must be up to %d", ErrInvalidSettings, MaxWorldSize)
+	}
+	return nil
+}
+
+func validWorldSize(size float32) bool {
+	return size == 0 || (size >= MinWorldSize && size <= MaxWorldSize)
+}
+
+// GetSettings returns the room's game settings
+func (room *Room) GetSettings() Settings {
+	room.mu.RLock()
+	defer room.mu.RUnlock()
+	return room.Settings
+}
@@ -0,0 +1,46 @@
+package room
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSettingsValidate(t *testing.T) {
+	valid := Settings{TickRate: 30, MaxPlayers: 4, WorldWidth: 2000, PlayerSpeed: 150, VisionRadius: 300}
+	if err := valid.Validate(8); err != nil {
+		t.Errorf("expected valid settings, got %v", err)
+	}
+	if err := (Settings{}).Validate(8); err != nil {
+		t.Errorf("expected empty settings valid, got %v", err)
+	}
+
+	for name, s := range map[string]Settings{
+		"tick rate":   {TickRate: 500},
+		"max players": {MaxPlayers: 9},
+		"world size":  {WorldHeight: 10},
+		"speed":       {PlayerSpeed: -1},
+		"vision":      {VisionRadius: -1},
+	} {
+		if err := s.Validate(8); !errors.Is(err, ErrInvalidSettings) {
+			t.Errorf("%s: expected ErrInvalidSettings, got %v", name, err)
+		}
+	}
+}
+
+func TestSettingsApplyToRoom(t *testing.T) {
+	r := NewRegistry(DefaultConfig())
+	defer r.Close()
+	rm, _ := r.CreateWithOptions(Options{Settings: Settings{TickRate: 30, MaxPlayers: 2}})
+
+	if rm.MaxPlayer != 2 {
+		t.Errorf("expected max players from settings, got %d", rm.MaxPlayer)
+	}
+	rm.Join("a", "A")
+	rm.Join("b", "B")
+	if _, err := rm.Join("c", "C"); err != ErrRoomFull {
+		t.Errorf("expected ErrRoomFull, got %v", err)
+	}
+	if got := FromRecord(rm.Record()).GetSettings(); got != rm.GetSettings() {
+		t.Errorf("expected settings persisted, got %+v", got)
+	}
+}
@@ -0,0 +1,324 @@
+package room
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Store holds the registry's rooms. Put is called when a room is created
+// and whenever its persistent settings change.
+type Store interface {
+	Get(id string) *Room
+	Put(room *Room) error
+	Delete(id string) error
+	All() []*Room
+	Count() int
+	Close() error
+}
+
+// Record is the persisted form of a room. Membership, host, phase and
+// countdown are runtime state and aren't stored, so a room comes back
+// from a restart as an empty lobby that keeps its ID, settings,
+// password and bans.
+type Record struct {
+	ID           string     `json:"id"`
+	CreatedAt    time.Time  `json:"created_at"`
+	MaxPlayers   int        `json:"max_players"`
+	Name         string     `json:"name,omitempty"`
+	Mode         string     `json:"mode,omitempty"`
+	Tags         []string   `json:"tags,omitempty"`
+	Visibility   Visibility `json:"visibility"`
+	Teams        int        `json:"teams,omitempty"`
+	Settings     Settings   `json:"settings"`
+	Locked       bool       `json:"locked,omitempty"`
+	Banned       []string   `json:"banned,omitempty"`
+	InviteOnly   bool       `json:"invite_only,omitempty"`
+	PasswordSalt []byte     `json:"password_salt,omitempty"`
+	PasswordHash []byte     `json:"password_hash,omitempty"`
+	Seats        []string   `json:"seats,omitempty"`
+}
+
+// Record returns the room's persistent state
+func (room *Room) Record() Record {
+	room.mu.RLock()
+	defer room.mu.RUnlock()
+
+	rec := Record{
+		ID:           room.ID,
+		CreatedAt:    room.CreatedAt,
+		MaxPlayers:   room.MaxPlayer,
+		Name:         room.Name,
+		Mode:         room.Mode,
+		Tags:         append([]string(nil), room.Tags...),
+		Visibility:   room.Visibility,
+		Teams:        room.Teams,
+		Settings:     room.Settings,
+		Locked:       room.Locked,
+		InviteOnly:   room.InviteOnly,
+		PasswordSalt: room.passwordSalt,
+		PasswordHash: room.passwordHash,
+	}
+	for id := range room.banned {
+		rec.Banned = append(rec.Banned, id)
+	}
+	for id := range room.seats {
+		rec.Seats = append(rec.Seats, id)
+	}
+	return rec
+}
+
+// FromRecord rebuilds an empty room from its persisted state. The room
+// isn't usable until a Registry adopts it.
+func FromRecord(rec Record) *Room {
+	room := &Room{
+		ID:           rec.ID,
+		CreatedAt:    rec.CreatedAt,
+		Players:      make(map[string]Player),
+		Spectators:   make(map[string]Spectator),
+		MaxPlayer:    rec.MaxPlayers,
+		Name:         rec.Name,
+		Mode:         rec.Mode,
+		Tags:         rec.Tags,
+		Visibility:   rec.Visibility,
+		Teams:        rec.Teams,
+		Settings:     rec.Settings,
+		Phase:        PhaseLobby,
+		Locked:       rec.Locked,
+		banned:       make(map[string]struct{}, len(rec.Banned)),
+		HasPassword:  len(rec.PasswordHash) > 0,
+		InviteOnly:   rec.InviteOnly,
+		passwordSalt: rec.PasswordSalt,
+		passwordHash: rec.PasswordHash,
+		lastActivity: time.Now(),
+	}
+	for _, id := range rec.Banned {
+		room.banned[id] = struct{}{}
+	}
+	if rec.Seats != nil {
+		room.seats = make(map[string]struct{}, len(rec.Seats))
+		for _, id := range rec.Seats {
+			room.seats[id] = struct{}{}
+		}
+	}
+	return room
+}
+
+// MemoryStore keeps rooms in a map; everything is lost on restart
+type MemoryStore struct {
+	rooms map[string]*Room
+	mu    sync.RWMutex
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{rooms: make(map[string]*Room)}
+}
+
+// Get retrieves a room by ID (nil if missing)
+func (s *MemoryStore) Get(id string) *Room {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.rooms[id]
+}
+
+// Put adds or replaces a room
+func (s *MemoryStore) Put(room *Room) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rooms[room.ID] = room
+	return nil
+}
+
+// Delete removes a room
+func (s *MemoryStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.rooms, id)
+	return nil
+}
+
+// All returns every room
+func (s *MemoryStore) All() []*Room {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	rooms := make([]*Room, 0, len(s.rooms))
+	for _, room := range s.rooms {
+		rooms = append(rooms, room)
+	}
+	return rooms
+}
+
+// Count returns the number of rooms
+func (s *MemoryStore) Count() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.rooms)
+}
+
+// Close does nothing
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+// compactEvery is how many log entries a FileStore appends before
+// rewriting the log down to one entry per live room
+const compactEvery = 1000
+
+// logEntry is one line of a FileStore's append-only log
+type logEntry struct {
+	Op   string  `json:"op"` // "put" or "delete"
+	ID   string  `json:"id,omitempty"`
+	Room *Record `json:"room,omitempty"`
+}
+
+// FileStore keeps rooms in memory backed by an append-only JSON log, so
+// rooms and their links survive a restart
+type FileStore struct {
+	*MemoryStore
+
+	path    string
+	file    *os.File
+	entries int // Entries appended since the last compaction
+	mu      sync.Mutex
+}
+
+// OpenFileStore loads the log at path (creating it if needed) and
+// compacts it
+func OpenFileStore(path string) (*FileStore, error) {
+	s := &FileStore{
+		MemoryStore: NewMemoryStore(),
+		path:        path,
+	}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	if err := s.compact(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// load replays the log into memory. Lines that don't parse (such as one
+// torn by a crash mid-write) are skipped.
+func (s *FileStore) load() error {
+	f, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		var entry logEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		switch {
+		case entry.Op == "put" && entry.Room != nil:
+			s.MemoryStore.Put(FromRecord(*entry.Room))
+		case entry.Op == "delete":
+			s.MemoryStore.Delete(entry.ID)
+		default:
+			return fmt.Errorf("room store %s:%d: bad entry %q", s.path, line, entry.Op)
+		}
+	}
+	return scanner.Err()
+}
+
+// compact rewrites the log with one put per live room and reopens it
+// for appending
+func (s *FileStore) compact() error {
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(tmp)
+	for _, room := range s.MemoryStore.All() {
+		rec := room.Record()
+		if err := enc.Encode(logEntry{Op: "put", Room: &rec}); err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+			return err
+		}
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	tmp.Close()
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if s.file != nil {
+		s.file.Close()
+	}
+	s.file, err = os.OpenFile(s.path, os.O_APPEND|os.O_WRONLY, 0o600)
+	s.entries = 0
+	return err
+}
+
+// appendLocked writes one entry to the log
+func (s *FileStore) appendLocked(entry logEntry) error {
+	if s.file == nil {
+		return os.ErrClosed
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if _, err := s.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	if err := s.file.Sync(); err != nil {
+		return err
+	}
+
+	s.entries++
+	if s.entries >= compactEvery {
+		return s.compact()
+	}
+	return nil
+}
+
+// Put adds or replaces a room and logs its record
+func (s *FileStore) Put(room *Room) error {
+	rec := room.Record()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.MemoryStore.Put(room)
+	return s.appendLocked(logEntry{Op: "put", Room: &rec})
+}
+
+// Delete removes a room and logs the deletion
+func (s *FileStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.MemoryStore.Delete(id)
+	return s.appendLocked(logEntry{Op: "delete", ID: id})
+}
+
+// Close flushes and closes the log
+func (s *FileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
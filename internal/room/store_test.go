@@ -0,0 +1,97 @@
+package room
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileStoreSurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rooms.log")
+	config := DefaultConfig()
+	config.InviteSecret = []byte("secret")
+
+	store, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	r := NewRegistryWithStore(config, store)
+	rm, invite := r.CreateWithOptions(Options{Name: "Friday", Password: "pw"})
+	gone := r.Create()
+	r.Join(rm.ID, "host", "Host", Credentials{Password: "pw"})
+	rm.Ban("host", "troll")
+	r.Delete(gone.ID)
+	store.Close()
+
+	store, err = OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer store.Close()
+	r = NewRegistryWithStore(config, store)
+
+	if r.Count() != 1 || r.Get(gone.ID) != nil {
+		t.Fatalf("expected only the live room restored, got %d rooms", r.Count())
+	}
+	restored := r.Get(rm.ID)
+	if restored == nil || restored.Name != "Friday" {
+		t.Fatalf("expected room %s restored with its name", rm.ID)
+	}
+	if restored.PlayerCount() != 0 || restored.GetPhase() != PhaseLobby {
+		t.Error("expected restored room to be an empty lobby")
+	}
+	if _, err := restored.Join("p1", "One"); err != ErrPasswordRequired {
+		t.Errorf("expected password kept, got %v", err)
+	}
+	if !restored.IsBanned("troll") {
+		t.Error("expected ban kept")
+	}
+	if _, err := restored.JoinWith("p1", "One", Credentials{Invite: invite}); err != nil {
+		t.Errorf("expected invite valid across restart, got %v", err)
+	}
+}
+
+func TestFileStoreSkipsTornLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rooms.log")
+	store, _ := OpenFileStore(path)
+	r := NewRegistryWithStore(DefaultConfig(), store)
+	rm := r.Create()
+	store.Close()
+
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	f.WriteString(`{"op":"put","room":{"id":"half`)
+	f.Close()
+
+	store, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer store.Close()
+	if store.Get(rm.ID) == nil || store.Count() != 1 {
+		t.Errorf("expected room restored past torn line, got %d rooms", store.Count())
+	}
+}
+
+func TestCleanupUsesStore(t *testing.T) {
+	config := DefaultConfig()
+	config.RoomTTL = 0
+	config.CleanupPeriod = 10 * time.Millisecond
+
+	store, err := OpenFileStore(filepath.Join(t.TempDir(), "rooms.log"))
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	r := NewRegistryWithStore(config, store)
+	defer r.Close()
+
+	sub := r.Subscribe()
+	rm := r.Create()
+
+	if e := nextEvent(t, sub, EventRoomExpired); e.Room.ID != rm.ID {
+		t.Errorf("expected %s expired, got %s", rm.ID, e.Room.ID)
+	}
+	if store.Get(rm.ID) != nil {
+		t.Error("expected expired room removed from store")
+	}
+}
@@ -0,0 +1,147 @@
+package room
+
+import (
+	"sort"
+	"time"
+)
+
+// TeamCapacity returns how many players fit on one team (0 without teams)
+func (room *Room) TeamCapacity() int {
+	room.mu.RLock()
+	defer room.mu.RUnlock()
+	return room.teamCapacityLocked()
+}
+
+func (room *Room) teamCapacityLocked() int {
+	if room.Teams == 0 {
+		return 0
+	}
+	return (room.MaxPlayer + room.Teams - 1) / room.Teams
+}
+
+// TeamAssignments returns each player's team
+func (room *Room) TeamAssignments() map[string]int {
+	room.mu.RLock()
+	defer room.mu.RUnlock()
+	teams := make(map[string]int, len(room.Players))
+	for id, p := range room.Players {
+		teams[id] = p.Team
+	}
+	return teams
+}
+
+// ChooseTeam moves a player to a team of their choice (lobby only, and
+// only onto a team with room left)
+func (room *Room) ChooseTeam(playerID string, team int) error {
+	room.mu.Lock()
+	defer room.mu.Unlock()
+
+	if err := room.checkTeamLocked(playerID, team); err != nil {
+		return err
+	}
+	if room.Phase != PhaseLobby {
+		return ErrWrongPhase
+	}
+	if room.Players[playerID].Team == team {
+		return nil
+	}
+	if room.teamSizesLocked()[team] >= room.teamCapacityLocked() {
+		return ErrTeamFull
+	}
+	room.setTeamLocked(playerID, team)
+	return nil
+}
+
+// AssignTeam puts a player on a team (host only, any phase)
+func (room *Room) AssignTeam(hostID, playerID string, team int) error {
+	room.mu.Lock()
+	defer room.mu.Unlock()
+
+	if !room.isHostLocked(hostID) {
+		return ErrNotHost
+	}
+	if err := room.checkTeamLocked(playerID, team); err != nil {
+		return err
+	}
+	if room.Players[playerID].Team != team {
+		room.setTeamLocked(playerID, team)
+	}
+	return nil
+}
+
+// BalanceTeams deals players out across teams in join order (host only,
+// lobby only)
+func (room *Room) BalanceTeams(hostID string) error {
+	room.mu.Lock()
+	defer room.mu.Unlock()
+
+	if err := room.checkHostLocked(hostID, PhaseLobby); err != nil {
+		return err
+	}
+	if room.Teams == 0 {
+		return ErrNoTeams
+	}
+
+	players := make([]Player, 0, len(room.Players))
+	for _, p := range room.Players {
+		players = append(players, p)
+	}
+	sort.Slice(players, func(i, j int) bool {
+		if players[i].JoinedAt.Equal(players[j].JoinedAt) {
+			return players[i].ID < players[j].ID
+		}
+		return players[i].JoinedAt.Before(players[j].JoinedAt)
+	})
+	for i, p := range players {
+		if team := i%room.Teams + 1; p.Team != team {
+			room.setTeamLocked(p.ID, team)
+		}
+	}
+	return nil
+}
+
+// checkTeamLocked verifies the room has teams, the team exists and the
+// player is in the room
+func (room *Room) checkTeamLocked(playerID string, team int) error {
+	if room.Teams == 0 {
+		return ErrNoTeams
+	}
+	if team < 1 || team > room.Teams {
+		return ErrInvalidTeam
+	}
+	if _, ok := room.Players[playerID]; !ok {
+		return ErrNotInRoom
+	}
+	return nil
+}
+
+// teamSizesLocked counts players per team
+func (room *Room) teamSizesLocked() map[int]int {
+	sizes := make(map[int]int, room.Teams)
+	for _, p := range room.Players {
+		sizes[p.Team]++
+	}
+	return sizes
+}
+
+// smallestTeamLocked picks the team a new player is auto-balanced onto
+// (lowest number wins ties)
+func (room *Room) smallestTeamLocked() int {
+	sizes := room.teamSizesLocked()
+	best := 1
+	for team := 2; team <= room.Teams; team++ {
+		if sizes[team] < sizes[best] {
+			best = team
+		}
+	}
+	return best
+}
+
+// setTeamLocked moves a player and publishes the change
+func (room *Room) setTeamLocked(playerID string, team int) {
+	p := room.Players[playerID]
+	p.Team = team
+	room.Players[playerID] = p
+	room.lastActivity = time.Now()
+	room.publish(Event{Type: EventTeamChanged, PlayerID: playerID, Team: team})
+}
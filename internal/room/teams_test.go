@@ -0,0 +1,79 @@
+package room
+
+import "testing"
+
+func TestTeamAutoBalance(t *testing.T) {
+	config := DefaultConfig()
+	config.MaxPlayers = 4
+	r := NewRegistry(config)
+	defer r.Close()
+	rm, _ := r.CreateWithOptions(Options{Teams: 2})
+
+	sub := r.Subscribe()
+	for _, id := range []string{"a", "b", "c"} {
+		rm.Join(id, id)
+	}
+	if e := nextEvent(t, sub, EventTeamChanged); e.PlayerID != "a" || e.Team != 1 {
+		t.Errorf("expected a on team 1, got %s on %d", e.PlayerID, e.Team)
+	}
+
+	teams := rm.TeamAssignments()
+	if teams["a"] != 1 || teams["b"] != 2 || teams["c"] != 1 {
+		t.Errorf("expected alternating teams, got %v", teams)
+	}
+	if rm.TeamCapacity() != 2 {
+		t.Errorf("expected 2 per team, got %d", rm.TeamCapacity())
+	}
+}
+
+func TestChooseAndAssignTeam(t *testing.T) {
+	config := DefaultConfig()
+	config.MaxPlayers = 4
+	r := NewRegistry(config)
+	defer r.Close()
+	rm, _ := r.CreateWithOptions(Options{Teams: 2})
+	for _, id := range []string{"host", "b", "c"} {
+		rm.Join(id, id)
+	}
+
+	if err := rm.ChooseTeam("b", 1); err != ErrTeamFull {
+		t.Errorf("expected ErrTeamFull, got %v", err)
+	}
+	if err := rm.ChooseTeam("c", 3); err != ErrInvalidTeam {
+		t.Errorf("expected ErrInvalidTeam, got %v", err)
+	}
+	if err := rm.ChooseTeam("c", 2); err != nil {
+		t.Errorf("choose failed: %v", err)
+	}
+
+	if err := rm.AssignTeam("b", "c", 1); err != ErrNotHost {
+		t.Errorf("expected ErrNotHost, got %v", err)
+	}
+	if err := rm.AssignTeam("host", "b", 1); err != nil {
+		t.Errorf("assign failed: %v", err)
+	}
+	if err := rm.BalanceTeams("host"); err != nil {
+		t.Fatalf("balance failed: %v", err)
+	}
+	sizes := map[int]int{}
+	for _, team := range rm.TeamAssignments() {
+		sizes[team]++
+	}
+	if sizes[1] != 2 || sizes[2] != 1 {
+		t.Errorf("expected balanced teams, got %v", sizes)
+	}
+
+	rm.SetReady("host", true)
+	rm.SetReady("b", true)
+	rm.SetReady("c", true)
+	rm.StartCountdown("host")
+	if err := rm.ChooseTeam("c", 1); err != ErrWrongPhase {
+		t.Errorf("expected ErrWrongPhase once started, got %v", err)
+	}
+
+	ffa := r.Create()
+	ffa.Join("p", "p")
+	if err := ffa.ChooseTeam("p", 1); err != ErrNoTeams {
+		t.Errorf("expected ErrNoTeams, got %v", err)
+	}
+}
@@ -49,7 +49,8 @@ type Config struct {
 	RecvBufferSize int
 	ReadTimeout    time.Duration
 	WriteTimeout   time.Duration
-	ListenAddr     string // Address to listen on (e.g., ":9000")
+	IdleTimeout    time.Duration // Silence before a client counts as disconnected (0 = never)
+	ListenAddr     string        // Address to listen on (e.g., ":9000")
 }
 
 // DefaultConfig returns sensible defaults.
@@ -60,6 +61,7 @@ func DefaultConfig() Config {
 		RecvBufferSize: 1024,
 		ReadTimeout:    5 * time.Second,
 		WriteTimeout:   5 * time.Second,
+		IdleTimeout:    10 * time.Second,
 		ListenAddr:     ":9000",
 	}
 }
@@ -55,6 +55,12 @@ func (t *UDPTransport) Listen(addr string) error {
 	t.wg.Add(1)
 	go t.receiveLoop()
 
+	// Start idle client reaper
+	if t.config.IdleTimeout > 0 {
+		t.wg.Add(1)
+		go t.reapLoop()
+	}
+
 	return nil
 }
 
@@ -163,3 +169,34 @@ func (t *UDPTransport) trackClient(addr string) {
 		go t.handlers.connect(addr)
 	}
 }
+
+// reapLoop fires disconnect events for clients that have gone silent.
+func (t *UDPTransport) reapLoop() {
+	defer t.wg.Done()
+
+	ticker := time.NewTicker(t.config.IdleTimeout / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.stopCh:
+			return
+		case now := <-ticker.C:
+			t.clientsMu.Lock()
+			var idle []string
+			for addr, lastSeen := range t.clients {
+				if now.Sub(lastSeen) > t.config.IdleTimeout {
+					idle = append(idle, addr)
+					delete(t.clients, addr)
+				}
+			}
+			t.clientsMu.Unlock()
+
+			if t.handlers.disconnect != nil {
+				for _, addr := range idle {
+					t.handlers.disconnect(addr)
+				}
+			}
+		}
+	}
+}
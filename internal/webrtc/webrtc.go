@@ -131,7 +131,7 @@ func (m *Manager) CreatePeerConnection(playerID string) (*webrtc.PeerConnection,
 		if m.incomingTracks[playerID] == nil {
 			m.incomingTracks[playerID] = make(map[string]*webrtc.TrackRemote)
 		}
-		m.incomingTracks[playerID][string(track.Kind())] = track
+		m.incomingTracks[playerID][track.Kind().String()] = track
 		m.mu.Unlock()
 		
 		// Notify about new track
@@ -163,7 +163,7 @@ func (m *Manager) forwardTrackToOthers(fromPlayerID string, track *webrtc.TrackR
 
 	localTrack, err := webrtc.NewTrackLocalStaticRTP(
 		capability,
-		"track-"+fromPlayerID+"-"+string(track.Kind()),
+		"track-"+fromPlayerID+"-"+track.Kind().String(),
 		"stream-"+fromPlayerID,
 	)
 	if err != nil {
@@ -381,7 +381,7 @@ func (m *Manager) HandleOffer(playerID string, sdp string) (*webrtc.SessionDescr
 		hasSenderTrack := sender != nil && sender.Track() != nil
 		var recvKind string
 		if receiver != nil && receiver.Track() != nil {
-			recvKind = string(receiver.Track().Kind())
+			recvKind = receiver.Track().Kind().String()
 		}
 		log.Printf("🎥 [%s] Transceiver %d: direction=%v, hasSenderTrack=%v, recvKind=%s", 
 			playerID, i, t.Direction(), hasSenderTrack, recvKind)